@@ -4,19 +4,27 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"spfnet/internal/route"
 	pb "spfnet/proto"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
 	serverAddr = flag.String("server", "localhost:5001", "Server address (ip:port)")
-	command    = flag.String("cmd", "", "Command to execute: addlink, ping, sendpacket")
+	command    = flag.String("cmd", "", "Command to execute: addlink, removelink, setcost, ping, sendpacket, enablesync, traceroute, routes, topology, stats, watch, multicast, broadcast, publish, tunnel-add, tunnel-rm, tunnels, sendfile")
+	output     = flag.String("output", "table", "Output format for routes/topology/stats/watch: table, json")
 
 	// addlink 参数
 	neighbor        = flag.String("neighbor", "", "Neighbor node ID")
@@ -33,6 +41,34 @@ var (
 
 	// enablesync 参数
 	syncEnabled = flag.Bool("enabled", true, "Enable or disable sync")
+
+	// traceroute 参数（目标节点复用 -dest）
+	traceCount = flag.Int("count", 1, "Number of trace probes (path-ping statistics when > 1)")
+
+	// stats 参数
+	watchInterval = flag.Duration("watch", 0, "Refresh stats periodically at this interval (0 to print once)")
+
+	// watch 参数
+	eventTypes = flag.String("types", "", "Comma-separated event types to watch, e.g. member,link_update,route (empty for all)")
+
+	// multicast / publish 参数（数据复用 -payload）
+	group = flag.String("group", "", "Multicast group name")
+	topic = flag.String("topic", "", "Topic to publish to")
+
+	// tunnel-add / tunnel-rm 参数（出口节点复用 -dest）
+	tunnelName = flag.String("name", "", "Tunnel name (defaults to the listen address); for sendfile, file name in the destination inbox (defaults to the source file name)")
+	listenAddr = flag.String("listen", "", "Local TCP address the tunnel listens on (e.g., 127.0.0.1:8080)")
+	targetAddr = flag.String("target", "", "TCP address the exit node connects to (e.g., 127.0.0.1:9000)")
+	maxConns   = flag.Int("max-conns", 0, "Maximum concurrent tunnel connections (0 for unlimited)")
+
+	// sendfile 参数（目标节点复用 -dest，收件文件名复用 -name）
+	filePath = flag.String("file", "", "File to send, relative to file_transfer.send_dir on the host of -server")
+
+	// TLS 参数（节点启用 [tls] 时使用）
+	tlsCA     = flag.String("tls-ca", "", "CA certificate used to verify the node (enables TLS)")
+	tlsCert   = flag.String("tls-cert", "", "Client certificate presented to the node (required when the node requires client auth)")
+	tlsKey    = flag.String("tls-key", "", "Private key of the client certificate")
+	tlsNodeID = flag.String("tls-node", "", "Expected node ID in the server certificate (empty to only verify the CA chain)")
 )
 
 func main() {
@@ -45,8 +81,21 @@ func main() {
 	}
 
 	// 建立 gRPC 连接
+	creds := insecure.NewCredentials()
+	if *tlsCA != "" {
+		nodeTLS, err := route.LoadNodeTLS(route.TLSConfig{
+			Enabled:  true,
+			CAFile:   *tlsCA,
+			CertFile: *tlsCert,
+			KeyFile:  *tlsKey,
+		})
+		if err != nil {
+			log.Fatalf("Failed to load TLS config: %v", err)
+		}
+		creds = nodeTLS.ClientCredentials(*tlsNodeID)
+	}
 	conn, err := grpc.NewClient(*serverAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
@@ -62,13 +111,41 @@ func main() {
 		doPing(ctx, client)
 	case "addlink":
 		doAddLink(ctx, client)
+	case "removelink":
+		doRemoveLink(ctx, client)
+	case "setcost":
+		doSetCost(ctx, client)
 	case "sendpacket":
 		doSendPacket(ctx, client)
 	case "enablesync":
 		doEnableSync(ctx, client)
+	case "traceroute":
+		doTraceroute(client)
+	case "routes":
+		doRoutes(ctx, client)
+	case "topology":
+		doTopology(ctx, client)
+	case "stats":
+		doStats(client)
+	case "watch":
+		doWatch(client)
+	case "multicast":
+		doMulticast(ctx, client, false)
+	case "broadcast":
+		doMulticast(ctx, client, true)
+	case "publish":
+		doPublish(ctx, client)
+	case "tunnel-add":
+		doAddTunnel(ctx, client)
+	case "tunnel-rm":
+		doRemoveTunnel(ctx, client)
+	case "tunnels":
+		doTunnels(ctx, client)
+	case "sendfile":
+		doSendFile(client)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", *command)
-		fmt.Fprintf(os.Stderr, "Available commands: ping, addlink, sendpacket, enablesync\n")
+		fmt.Fprintf(os.Stderr, "Available commands: ping, addlink, removelink, setcost, sendpacket, enablesync, traceroute, routes, topology, stats, watch, multicast, broadcast, publish, tunnel-add, tunnel-rm, tunnels, sendfile\n")
 		os.Exit(1)
 	}
 }
@@ -119,6 +196,61 @@ func doAddLink(ctx context.Context, client pb.ControlServiceClient) {
 	}
 }
 
+func doRemoveLink(ctx context.Context, client pb.ControlServiceClient) {
+	if *neighbor == "" {
+		fmt.Fprintf(os.Stderr, "Error: -neighbor is required for removelink command\n")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Removing link to neighbor %s on %s...\n", *neighbor, *serverAddr)
+
+	resp, err := client.RemoveLink(ctx, &pb.RemoveLinkRequest{Neighbor: *neighbor})
+	if err != nil {
+		log.Fatalf("RemoveLink failed: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✓ %s\n", resp.Message)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+}
+
+func doSetCost(ctx context.Context, client pb.ControlServiceClient) {
+	if *neighbor == "" {
+		fmt.Fprintf(os.Stderr, "Error: -neighbor is required for setcost command\n")
+		os.Exit(1)
+	}
+
+	// -cost 为 0 时重新自动探测，否则固定为指定值
+	probe := *cost <= 0
+	if probe {
+		fmt.Printf("Re-probing link cost to neighbor %s on %s...\n", *neighbor, *serverAddr)
+	} else {
+		fmt.Printf("Setting link cost to neighbor %s on %s to %.2f...\n", *neighbor, *serverAddr, *cost)
+	}
+
+	req := &pb.SetLinkCostRequest{
+		Neighbor:  *neighbor,
+		Cost:      *cost,
+		AutoProbe: probe,
+	}
+
+	resp, err := client.SetLinkCost(ctx, req)
+	if err != nil {
+		log.Fatalf("SetLinkCost failed: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✓ %s\n", resp.Message)
+		fmt.Printf("  Final cost: %.2f, Pinned: %v\n", resp.Cost, resp.Pinned)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+}
+
 func doSendPacket(ctx context.Context, client pb.ControlServiceClient) {
 	if *sourceAddr == "" {
 		fmt.Fprintf(os.Stderr, "Error: -source-addr is required for sendpacket command\n")
@@ -165,6 +297,184 @@ func doSendPacket(ctx context.Context, client pb.ControlServiceClient) {
 	}
 }
 
+func doMulticast(ctx context.Context, client pb.ControlServiceClient, broadcast bool) {
+	if !broadcast && *group == "" {
+		fmt.Fprintf(os.Stderr, "Error: -group is required for multicast command\n")
+		os.Exit(1)
+	}
+
+	req := &pb.MulticastRequest{
+		Group:     *group,
+		Broadcast: broadcast,
+		Payload:   []byte(*payload),
+	}
+
+	printMulticastResult(ctx, client, req)
+}
+
+func doPublish(ctx context.Context, client pb.ControlServiceClient) {
+	if *topic == "" {
+		fmt.Fprintf(os.Stderr, "Error: -topic is required for publish command\n")
+		os.Exit(1)
+	}
+
+	req := &pb.MulticastRequest{
+		Topic:   *topic,
+		Payload: []byte(*payload),
+	}
+
+	printMulticastResult(ctx, client, req)
+}
+
+// printMulticastResult 发送多播、广播或发布请求并输出结果
+func printMulticastResult(ctx context.Context, client pb.ControlServiceClient, req *pb.MulticastRequest) {
+	resp, err := client.Multicast(ctx, req)
+	if err != nil {
+		log.Fatalf("Multicast failed: %v", err)
+	}
+
+	if *output == "json" {
+		printJSON(resp)
+		if !resp.Success {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if resp.Success {
+		fmt.Printf("✓ %s\n", resp.Message)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+	}
+	fmt.Printf("  Packet ID: %s\n", resp.PacketId)
+	fmt.Printf("  Targets: %s\n", strings.Join(resp.Targets, ", "))
+	if len(resp.FailedDestinations) > 0 {
+		fmt.Printf("  Failed: %s\n", strings.Join(resp.FailedDestinations, ", "))
+		os.Exit(1)
+	}
+	if !resp.Success {
+		os.Exit(1)
+	}
+}
+
+func doAddTunnel(ctx context.Context, client pb.ControlServiceClient) {
+	if *listenAddr == "" || *destNode == "" || *targetAddr == "" {
+		fmt.Fprintf(os.Stderr, "Error: -listen, -dest and -target are required for tunnel-add command\n")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Adding tunnel on %s: %s -> %s -> %s...\n", *serverAddr, *listenAddr, *destNode, *targetAddr)
+
+	resp, err := client.AddTunnel(ctx, &pb.AddTunnelRequest{
+		Name:     *tunnelName,
+		Listen:   *listenAddr,
+		Node:     *destNode,
+		Target:   *targetAddr,
+		MaxConns: int32(*maxConns),
+	})
+	if err != nil {
+		log.Fatalf("AddTunnel failed: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✓ %s\n", resp.Message)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+}
+
+func doRemoveTunnel(ctx context.Context, client pb.ControlServiceClient) {
+	if *tunnelName == "" {
+		fmt.Fprintf(os.Stderr, "Error: -name is required for tunnel-rm command\n")
+		os.Exit(1)
+	}
+
+	resp, err := client.RemoveTunnel(ctx, &pb.RemoveTunnelRequest{Name: *tunnelName})
+	if err != nil {
+		log.Fatalf("RemoveTunnel failed: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✓ %s\n", resp.Message)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+}
+
+func doTunnels(ctx context.Context, client pb.ControlServiceClient) {
+	resp, err := client.ListTunnels(ctx, &pb.ListTunnelsRequest{})
+	if err != nil {
+		log.Fatalf("ListTunnels failed: %v", err)
+	}
+
+	if *output == "json" {
+		printJSON(resp)
+		return
+	}
+	if !resp.Success {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Tunnels on %s (%d):\n", *serverAddr, len(resp.Tunnels))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLISTEN\tNODE\tTARGET\tACTIVE\tMAX\tTOTAL\tREJECTED\tFAILED\tSENT\tRECEIVED")
+	for _, t := range resp.Tunnels {
+		limit := "-"
+		if t.MaxConns > 0 {
+			limit = fmt.Sprintf("%d", t.MaxConns)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			t.Name, t.Listen, t.Node, t.Target, t.ActiveConns, limit,
+			t.TotalConns, t.RejectedConns, t.FailedConns, t.BytesSent, t.BytesReceived)
+	}
+	w.Flush()
+}
+
+func doSendFile(client pb.ControlServiceClient) {
+	if *destNode == "" || *filePath == "" {
+		fmt.Fprintf(os.Stderr, "Error: -dest and -file are required for sendfile command\n")
+		os.Exit(1)
+	}
+
+	// 节点只发送其发送目录（file_transfer.send_dir）中的文件，相对路径相对于该目录
+	path := *filePath
+	fmt.Printf("Sending %s from %s to %s...\n", path, *serverAddr, *destNode)
+
+	// 传输时长取决于文件大小，不使用默认的请求超时
+	resp, err := client.SendFile(context.Background(), &pb.SendFileRequest{
+		Destination: *destNode,
+		Path:        path,
+		Name:        *tunnelName,
+	})
+	if err != nil {
+		log.Fatalf("SendFile failed: %v", err)
+	}
+
+	if *output == "json" {
+		printJSON(resp)
+		if !resp.Success {
+			os.Exit(1)
+		}
+		return
+	}
+	if !resp.Success {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ %s\n", resp.Message)
+	fmt.Printf("  Name:     %s\n", resp.Name)
+	fmt.Printf("  Size:     %d bytes\n", resp.Size)
+	fmt.Printf("  SHA-256:  %s\n", resp.Sha256)
+	if resp.ResumedFrom > 0 {
+		fmt.Printf("  Resumed:  from byte %d\n", resp.ResumedFrom)
+	}
+	fmt.Printf("  Sent:     %d bytes in %d attempt(s), %dms\n", resp.BytesSent, resp.Attempts, resp.DurationMs)
+}
+
 func doEnableSync(ctx context.Context, client pb.ControlServiceClient) {
 	fmt.Printf("Setting sync state to %v on %s...\n", *syncEnabled, *serverAddr)
 
@@ -185,3 +495,431 @@ func doEnableSync(ctx context.Context, client pb.ControlServiceClient) {
 		os.Exit(1)
 	}
 }
+
+// traceHopStat 某一跳在多次追踪中的 RTT 样本
+type traceHopStat struct {
+	hop     int
+	node    string
+	nextHop string
+	rtts    []time.Duration
+}
+
+func doTraceroute(client pb.ControlServiceClient) {
+	if *destNode == "" {
+		fmt.Fprintf(os.Stderr, "Error: -dest is required for traceroute command\n")
+		os.Exit(1)
+	}
+	if *traceCount < 1 {
+		*traceCount = 1
+	}
+
+	fmt.Printf("Tracing route from %s to %s (%d probes)...\n", *serverAddr, *destNode, *traceCount)
+
+	var stats []*traceHopStat
+	index := make(map[string]*traceHopStat)
+	var expected []string
+	failed := 0
+
+	for i := 0; i < *traceCount; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		resp, err := client.Traceroute(ctx, &pb.TracerouteRequest{Destination: *destNode})
+		cancel()
+		if err != nil {
+			log.Fatalf("Traceroute failed: %v", err)
+		}
+
+		expected = resp.ExpectedPath
+		if !resp.Success {
+			failed++
+			fmt.Printf("✗ Probe %d: %s\n", i+1, resp.Message)
+		}
+		if resp.Diverged {
+			fmt.Printf("⚠ Probe %d: actual path %v diverges from computed route %v\n",
+				i+1, traceNodes(resp.Hops), resp.ExpectedPath)
+		}
+
+		if len(resp.Hops) == 0 {
+			continue
+		}
+
+		// 各跳 RTT = 源节点下游往返时间 - 该跳下游往返时间，只依赖各节点自身时钟
+		base := resp.Hops[0].DownstreamRttNanos
+		for h, hop := range resp.Hops {
+			key := fmt.Sprintf("%d/%s", h, hop.NodeId)
+			stat, ok := index[key]
+			if !ok {
+				stat = &traceHopStat{hop: h, node: hop.NodeId, nextHop: hop.NextHop}
+				index[key] = stat
+				stats = append(stats, stat)
+			}
+
+			rtt := time.Duration(0)
+			if h > 0 {
+				rtt = time.Duration(base - hop.DownstreamRttNanos)
+			}
+			stat.rtts = append(stat.rtts, rtt)
+		}
+	}
+
+	fmt.Printf("  Expected path: %v\n", expected)
+	fmt.Printf("%-4s %-12s %-12s %-6s %-10s %-10s %-10s\n",
+		"HOP", "NODE", "NEXT HOP", "RECV", "MIN(ms)", "AVG(ms)", "MAX(ms)")
+	for _, stat := range stats {
+		minRTT, maxRTT, sum := stat.rtts[0], stat.rtts[0], time.Duration(0)
+		for _, rtt := range stat.rtts {
+			if rtt < minRTT {
+				minRTT = rtt
+			}
+			if rtt > maxRTT {
+				maxRTT = rtt
+			}
+			sum += rtt
+		}
+		avgRTT := sum / time.Duration(len(stat.rtts))
+
+		nextHop := stat.nextHop
+		if nextHop == "" {
+			nextHop = "-"
+		}
+		fmt.Printf("%-4d %-12s %-12s %-6s %-10.3f %-10.3f %-10.3f\n",
+			stat.hop, stat.node, nextHop,
+			fmt.Sprintf("%d/%d", len(stat.rtts), *traceCount),
+			durationMs(minRTT), durationMs(avgRTT), durationMs(maxRTT))
+	}
+
+	if failed > 0 {
+		fmt.Printf("✗ %d/%d probes failed\n", failed, *traceCount)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Trace completed\n")
+}
+
+// traceNodes 提取追踪记录中的节点序列
+func traceNodes(hops []*pb.TraceHop) []string {
+	nodes := make([]string, 0, len(hops))
+	for _, hop := range hops {
+		nodes = append(nodes, hop.NodeId)
+	}
+	return nodes
+}
+
+// durationMs 转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func doRoutes(ctx context.Context, client pb.ControlServiceClient) {
+	// 指定 -dest 时只查询到该目标的路由
+	if *destNode != "" {
+		resp, err := client.GetRoute(ctx, &pb.GetRouteRequest{Destination: *destNode})
+		if err != nil {
+			log.Fatalf("GetRoute failed: %v", err)
+		}
+		if *output == "json" {
+			printJSON(resp)
+		} else if resp.Success {
+			printRoutes([]*pb.RouteEntry{resp.Route})
+		} else {
+			fmt.Printf("✗ Failed: %s\n", resp.Message)
+		}
+		if !resp.Success {
+			os.Exit(1)
+		}
+		return
+	}
+
+	resp, err := client.GetRoutes(ctx, &pb.GetRoutesRequest{})
+	if err != nil {
+		log.Fatalf("GetRoutes failed: %v", err)
+	}
+	if *output == "json" {
+		printJSON(resp)
+	} else if resp.Success {
+		fmt.Printf("Route table of %s (%d routes):\n", resp.NodeId, len(resp.Routes))
+		printRoutes(resp.Routes)
+	} else {
+		fmt.Printf("✗ Failed: %s\n", resp.Message)
+	}
+	if !resp.Success {
+		os.Exit(1)
+	}
+}
+
+func doTopology(ctx context.Context, client pb.ControlServiceClient) {
+	resp, err := client.GetTopology(ctx, &pb.GetTopologyRequest{})
+	if err != nil {
+		log.Fatalf("GetTopology failed: %v", err)
+	}
+
+	if *output == "json" {
+		printJSON(resp)
+		return
+	}
+
+	fmt.Printf("Topology seen by %s (%d nodes, %d links):\n", resp.NodeId, len(resp.Nodes), len(resp.Links))
+
+	fmt.Println("\nNodes:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tADDRESS\tSTATUS")
+	for _, node := range resp.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", node.Id, node.Address, node.Status)
+	}
+	w.Flush()
+
+	fmt.Println("\nLinks:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FROM\tTO\tCOST\tMODE")
+	for _, link := range resp.Links {
+		mode := "probed"
+		if link.Pinned {
+			mode = "pinned"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\n", link.From, link.To, link.Cost, mode)
+	}
+	w.Flush()
+}
+
+func doStats(client pb.ControlServiceClient) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		resp, err := client.GetStats(ctx, &pb.GetStatsRequest{})
+		cancel()
+		if err != nil {
+			log.Fatalf("GetStats failed: %v", err)
+		}
+		if !resp.Success {
+			fmt.Printf("✗ Failed: %s\n", resp.Message)
+			os.Exit(1)
+		}
+
+		if *watchInterval > 0 && *output != "json" {
+			// 清屏后重新输出
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Every %v: %s\n\n", *watchInterval, time.Now().Format("15:04:05"))
+		}
+
+		if *output == "json" {
+			printJSON(resp)
+		} else {
+			printStats(resp)
+		}
+
+		if *watchInterval <= 0 {
+			return
+		}
+		time.Sleep(*watchInterval)
+	}
+}
+
+// printStats 以表格形式输出转发统计
+func printStats(resp *pb.GetStatsResponse) {
+	fmt.Printf("Forward statistics of %s:\n", resp.NodeId)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Sent:\t%d packets\t%d bytes\n", resp.PacketsSent, resp.BytesSent)
+	fmt.Fprintf(w, "  Forwarded:\t%d packets\t%d bytes\n", resp.PacketsForwarded, resp.BytesForwarded)
+	fmt.Fprintf(w, "  Delivered:\t%d packets\t%d bytes\n", resp.PacketsDelivered, resp.BytesDelivered)
+	fmt.Fprintf(w, "  Received:\t%d packets\t\n", resp.PacketsReceived)
+	fmt.Fprintf(w, "  Dropped:\t%d packets\t\n", resp.PacketsDropped)
+	fmt.Fprintf(w, "  Duplicates:\t%d packets\t\n", resp.PacketsDuplicate)
+	fmt.Fprintf(w, "  Control:\t%d sent\t%d received\n", resp.ControlSent, resp.ControlReceived)
+	w.Flush()
+
+	if len(resp.DropReasons) > 0 {
+		fmt.Println("\nDrop reasons:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, reason := range sortedKeys(resp.DropReasons) {
+			fmt.Fprintf(w, "  %s\t%d\n", reason, resp.DropReasons[reason])
+		}
+		w.Flush()
+	}
+
+	fmt.Println("\nPer neighbor:")
+	printTrafficTable("NEIGHBOR", resp.Neighbors)
+
+	fmt.Println("\nPer destination:")
+	printTrafficTable("DESTINATION", resp.Destinations)
+
+	if len(resp.Subscribers) > 0 {
+		fmt.Println("\nPer topic subscriber:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  TOPIC\tSUBSCRIBER\tDELIVERED\tFAILED\tBYTES\tLAST DELIVERED\n")
+		for _, sub := range resp.Subscribers {
+			last := "-"
+			if sub.LastDeliveredUnixNano > 0 {
+				last = time.Unix(0, sub.LastDeliveredUnixNano).Format("15:04:05.000")
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%d\t%d\t%d\t%s\n",
+				sub.Topic, sub.Subscriber, sub.Delivered, sub.Failed, sub.BytesDelivered, last)
+		}
+		w.Flush()
+	}
+}
+
+// printTrafficTable 输出按邻居或目标细分的统计表
+func printTrafficTable(title string, entries map[string]*pb.TrafficStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tSENT\tFORWARDED\tDROPPED\tBYTES\tAVG(ms)\tP50(ms)\tP99(ms)\tMAX(ms)\tDROP REASONS\n", title)
+	for _, id := range sortedKeys(entries) {
+		entry := entries[id]
+		latency := entry.GetLatency()
+
+		avg := 0.0
+		if latency.GetCount() > 0 {
+			avg = durationMs(time.Duration(latency.GetSumNanos() / int64(latency.GetCount())))
+		}
+
+		var reasons []string
+		for _, reason := range sortedKeys(entry.DropReasons) {
+			reasons = append(reasons, fmt.Sprintf("%s=%d", reason, entry.DropReasons[reason]))
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.2f\t%s\t%s\t%.2f\t%s\n",
+			id, entry.PacketsSent, entry.PacketsForwarded, entry.PacketsDropped, entry.BytesForwarded,
+			avg, histogramQuantile(latency, 0.5), histogramQuantile(latency, 0.99),
+			durationMs(time.Duration(latency.GetMaxNanos())), strings.Join(reasons, " "))
+	}
+	w.Flush()
+}
+
+// histogramQuantile 根据直方图估算分位数（返回所在桶的上界）
+func histogramQuantile(h *pb.LatencyHistogram, q float64) string {
+	if h.GetCount() == 0 {
+		return "-"
+	}
+
+	target := uint64(float64(h.GetCount())*q + 0.5)
+	if target == 0 {
+		target = 1
+	}
+
+	var cumulative uint64
+	for i, count := range h.GetCounts() {
+		cumulative += count
+		if cumulative < target {
+			continue
+		}
+		if i >= len(h.GetBoundsNanos()) {
+			return fmt.Sprintf(">%.0f", durationMs(time.Duration(h.GetBoundsNanos()[len(h.GetBoundsNanos())-1])))
+		}
+		return fmt.Sprintf("<=%.0f", durationMs(time.Duration(h.GetBoundsNanos()[i])))
+	}
+	return "-"
+}
+
+// sortedKeys 返回按字典序排序的 map 键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func doWatch(client pb.ControlServiceClient) {
+	types, err := parseEventTypes(*eventTypes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stream, err := client.WatchEvents(context.Background(), &pb.WatchEventsRequest{Types: types})
+	if err != nil {
+		log.Fatalf("WatchEvents failed: %v", err)
+	}
+
+	if *output != "json" {
+		fmt.Printf("Watching events on %s (Ctrl+C to stop)...\n", *serverAddr)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("Event stream closed: %v", err)
+		}
+
+		if *output == "json" {
+			data, err := protojson.Marshal(event)
+			if err != nil {
+				log.Fatalf("Failed to encode JSON: %v", err)
+			}
+			fmt.Println(string(data))
+			continue
+		}
+		fmt.Println(formatEvent(event))
+	}
+}
+
+// parseEventTypes 解析逗号分隔的事件类型，支持 member / link / route 分组
+func parseEventTypes(spec string) ([]pb.EventType, error) {
+	var types []pb.EventType
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		matched := false
+		for value, typeName := range pb.EventType_name {
+			short := strings.TrimPrefix(typeName, "EVENT_TYPE_")
+			if value != 0 && (short == name || strings.HasPrefix(short, name+"_")) {
+				types = append(types, pb.EventType(value))
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unknown event type: %s", strings.ToLower(name))
+		}
+	}
+	return types, nil
+}
+
+// formatEvent 将事件格式化为一行文本
+func formatEvent(event *pb.Event) string {
+	ts := time.Unix(0, event.UnixNano).Format("15:04:05.000")
+	kind := strings.ToLower(strings.TrimPrefix(event.Type.String(), "EVENT_TYPE_"))
+
+	var detail string
+	switch event.Type {
+	case pb.EventType_EVENT_TYPE_MEMBER_JOIN, pb.EventType_EVENT_TYPE_MEMBER_LEAVE, pb.EventType_EVENT_TYPE_MEMBER_FAILED:
+		detail = fmt.Sprintf("node=%s addr=%s", event.Node, event.Address)
+	case pb.EventType_EVENT_TYPE_LINK_ADD:
+		detail = fmt.Sprintf("link=%s-%s cost=%.2f pinned=%v", event.From, event.To, event.Cost, event.Pinned)
+	case pb.EventType_EVENT_TYPE_LINK_UPDATE:
+		detail = fmt.Sprintf("link=%s-%s cost=%.2f->%.2f pinned=%v", event.From, event.To, event.OldCost, event.Cost, event.Pinned)
+	case pb.EventType_EVENT_TYPE_LINK_REMOVE:
+		detail = fmt.Sprintf("link=%s-%s", event.From, event.To)
+	case pb.EventType_EVENT_TYPE_ROUTE_ADD:
+		detail = fmt.Sprintf("dest=%s via=%s cost=%.2f path=%s",
+			event.Node, event.NextHop, event.Cost, strings.Join(event.Path, " -> "))
+	case pb.EventType_EVENT_TYPE_ROUTE_CHANGE:
+		detail = fmt.Sprintf("dest=%s via=%s->%s cost=%.2f->%.2f path=%s",
+			event.Node, event.OldNextHop, event.NextHop, event.OldCost, event.Cost, strings.Join(event.Path, " -> "))
+	case pb.EventType_EVENT_TYPE_ROUTE_REMOVE:
+		detail = fmt.Sprintf("dest=%s (was via %s)", event.Node, event.OldNextHop)
+	}
+
+	return fmt.Sprintf("%s [%s] %-13s %s", ts, event.Reporter, kind, detail)
+}
+
+// printRoutes 以表格形式输出路由条目
+func printRoutes(routes []*pb.RouteEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DESTINATION\tNEXT HOP\tCOST\tPATH")
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\n", route.Destination, route.NextHop, route.Cost, strings.Join(route.Path, " -> "))
+	}
+	w.Flush()
+}
+
+// printJSON 以 JSON 形式输出响应
+func printJSON(msg proto.Message) {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		log.Fatalf("Failed to encode JSON: %v", err)
+	}
+	fmt.Println(string(data))
+}
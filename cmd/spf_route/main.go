@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"spfnet/internal/route"
@@ -23,6 +24,19 @@ var ( // 通用配置
 	nodePort = flag.Int("port", 5001, "gRPC port")
 	serfPort = flag.Int("serf-port", 7001, "Serf port")
 	join     = flag.String("join", "", "Address of node to join (e.g., 127.0.0.1:7001)")
+	services = flag.String("services", "", "Comma-separated service names provided by this node (overrides config file)")
+	groups   = flag.String("groups", "", "Comma-separated multicast groups joined by this node (overrides config file)")
+
+	// 端口转发隧道
+	forwards     = flag.String("forward", "", "Comma-separated port forwards [bind:]port:node:host:hostport (added to config file tunnels)")
+	tunnelAllows = flag.String("tunnel-allow", "", "Comma-separated targets (host:port or *) this node may connect to as a tunnel exit (overrides config file)")
+
+	// UDP 中继
+	udpRelays = flag.String("udp-relay", "", "Comma-separated UDP relays [bind:]port:node:host:hostport (added to config file relays)")
+	udpAllows = flag.String("udp-allow", "", "Comma-separated UDP targets (host:port or *) this node may emit relayed datagrams to (overrides config file)")
+
+	// 本地应用服务
+	appListen = flag.String("app-listen", "", "Local application service address, unix:/path/to.sock or 127.0.0.1:port (overrides config file)")
 )
 
 func main() {
@@ -45,6 +59,42 @@ func main() {
 	}
 	defer rtConfig.Close()
 
+	if *services != "" {
+		rtConfig.Services = strings.Split(*services, ",")
+	}
+	if *groups != "" {
+		rtConfig.Groups = strings.Split(*groups, ",")
+	}
+	if *forwards != "" {
+		for _, f := range strings.Split(*forwards, ",") {
+			spec, err := route.ParseTunnelSpec(f)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -forward: %v\n", err)
+				os.Exit(1)
+			}
+			rtConfig.AppConfig.Tunnel.Forwards = append(rtConfig.AppConfig.Tunnel.Forwards, spec)
+		}
+	}
+	if *tunnelAllows != "" {
+		rtConfig.AppConfig.Tunnel.AllowedTargets = strings.Split(*tunnelAllows, ",")
+	}
+	if *udpRelays != "" {
+		for _, r := range strings.Split(*udpRelays, ",") {
+			spec, err := route.ParseUDPRelaySpec(r)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -udp-relay: %v\n", err)
+				os.Exit(1)
+			}
+			rtConfig.AppConfig.UDP.Relays = append(rtConfig.AppConfig.UDP.Relays, spec)
+		}
+	}
+	if *udpAllows != "" {
+		rtConfig.AppConfig.UDP.AllowedTargets = strings.Split(*udpAllows, ",")
+	}
+	if *appListen != "" {
+		rtConfig.AppConfig.AppService.Listen = *appListen
+	}
+
 	// 创建并启动应用
 	node := route.NewRouteNode(rtConfig)
 	if err := node.Init(); err != nil {
@@ -92,14 +92,13 @@ func multipleNodes() {
 
 	time.Sleep(2 * time.Second)
 
-	// 向多个目标发送数据
-	targets := []string{"nodeA", "nodeB", "nodeC"}
-	for _, target := range targets {
-		if err := c.Send(target, []byte("Broadcast message")); err != nil {
-			log.Printf("Failed to send to %s: %v", target, err)
-		} else {
-			log.Printf("Sent to %s successfully", target)
-		}
+	// 向集群中所有节点广播，数据只在路径分叉处复制，共享链路上只传输一份
+	result, err := c.Broadcast([]byte("Broadcast message"))
+	if err != nil {
+		log.Printf("Broadcast failed: %v", err)
+	}
+	if result != nil {
+		log.Printf("Broadcast delivered to %v", result.Delivered())
 	}
 }
 
@@ -0,0 +1,300 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	DefaultAppReceiveBuffer  = 256              // 每个 Receive 流默认缓存的消息数
+	DefaultAppRequestTimeout = 10 * time.Second // Request 默认等待应答的时间
+)
+
+// AppServer 本地应用服务，让同机的非 Go 应用（sidecar 模式）通过 gRPC 使用覆盖网络
+// 只监听 Unix 域套接字或回环地址，不做身份认证
+type AppServer struct {
+	pb.UnimplementedAppServiceServer
+
+	nodeID  string
+	fm      *ForwardManager
+	control *ControlServer // 路由和拓扑查询复用控制服务的实现
+	bufSize int
+
+	mu        sync.Mutex
+	receivers map[uint64]chan *pb.AppMessage
+	nextID    uint64
+	pending   map[string]*pendingRequest // 请求 ID -> 等待中的请求
+
+	server     *grpc.Server
+	socketPath string // Unix 域套接字路径，停止时删除
+}
+
+// pendingRequest 等待应答的请求
+type pendingRequest struct {
+	destination string // 请求的目的节点，只接受来自该节点的应答
+	ch          chan *pb.Packet
+}
+
+// NewAppServer 创建本地应用服务
+func NewAppServer(nodeID string, fm *ForwardManager, control *ControlServer, bufSize int) *AppServer {
+	if bufSize <= 0 {
+		bufSize = DefaultAppReceiveBuffer
+	}
+	return &AppServer{
+		nodeID:    nodeID,
+		fm:        fm,
+		control:   control,
+		bufSize:   bufSize,
+		receivers: make(map[uint64]chan *pb.AppMessage),
+		pending:   make(map[string]*pendingRequest),
+	}
+}
+
+// listenApp 解析监听地址并建立监听
+// "unix:/path/to.sock" 表示 Unix 域套接字，其他按 "host:port" 处理且必须是回环地址
+func listenApp(addr string) (net.Listener, string, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if path == "" {
+			return nil, "", fmt.Errorf("invalid app service address %q", addr)
+		}
+		// 清理上次异常退出遗留的套接字文件，其他类型的文件不动
+		if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		lis, err := net.Listen("unix", path)
+		return lis, path, err
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid app service address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, "", fmt.Errorf("app service must listen on a unix socket or loopback address, got %q", addr)
+	}
+	lis, err := net.Listen("tcp", addr)
+	return lis, "", err
+}
+
+// Start 在指定地址启动服务
+func (s *AppServer) Start(addr string) error {
+	lis, path, err := listenApp(addr)
+	if err != nil {
+		return err
+	}
+	s.socketPath = path
+
+	s.server = grpc.NewServer()
+	pb.RegisterAppServiceServer(s.server, s)
+	go func() {
+		log.Printf("[%s] App service started on %s", s.nodeID, addr)
+		if err := s.server.Serve(lis); err != nil {
+			log.Printf("[%s] App service error: %v", s.nodeID, err)
+		}
+	}()
+	return nil
+}
+
+// Stop 停止服务，断开所有 Receive 流
+func (s *AppServer) Stop() {
+	if s.server != nil {
+		s.server.Stop()
+	}
+	if s.socketPath != "" {
+		os.Remove(s.socketPath)
+	}
+}
+
+// HandleDelivery 处理投递到本节点的业务数据包
+// 等待中的请求的应答由本服务消费并返回 true，其他数据包分发给所有 Receive 流并返回 false
+// 只有来源为请求目的节点的数据包才被当作应答，其他节点无法通过猜测请求 ID 伪造应答
+func (s *AppServer) HandleDelivery(packet *pb.Packet) bool {
+	if packet.ReplyTo != "" {
+		s.mu.Lock()
+		req, ok := s.pending[packet.ReplyTo]
+		matched := ok && req.destination == packet.Source
+		if matched {
+			delete(s.pending, packet.ReplyTo)
+		}
+		s.mu.Unlock()
+		if matched {
+			req.ch <- packet
+			return true
+		}
+		if ok {
+			log.Printf("[%s] ✗ Packet %s from %s is not accepted as reply to request %s (sent to %s)",
+				s.nodeID, packet.PacketId, packet.Source, packet.ReplyTo, req.destination)
+		}
+	}
+
+	msg := packetToAppMessage(packet)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, ch := range s.receivers {
+		select {
+		case ch <- msg:
+		default:
+			log.Printf("[%s] ✗ App receiver %d is full, dropping packet %s", s.nodeID, id, packet.PacketId)
+		}
+	}
+	return false
+}
+
+// packetToAppMessage 将数据包转换为应用消息
+func packetToAppMessage(packet *pb.Packet) *pb.AppMessage {
+	return &pb.AppMessage{
+		PacketId:    packet.PacketId,
+		Source:      packet.Source,
+		Destination: packet.Destination,
+		Path:        append([]string(nil), packet.VisitedNodes...),
+		Payload:     packet.Payload,
+		Service:     packet.Service,
+		Group:       packet.Group,
+		Topic:       packet.Topic,
+		RequestId:   packet.RequestId,
+		Headers:     packet.Headers,
+	}
+}
+
+// newRequestID 生成请求 ID
+func (s *AppServer) newRequestID() string {
+	return fmt.Sprintf("req-%s-%d", s.nodeID, time.Now().UnixNano())
+}
+
+func (s *AppServer) Send(ctx context.Context, req *pb.AppSendRequest) (*pb.AppSendResponse, error) {
+	if req.Service != "" {
+		provider, err := s.fm.SendToServiceWithOptions(ctx, req.Service, req.Payload, SendOptions{Headers: req.Headers})
+		if err != nil {
+			return &pb.AppSendResponse{Success: false, Message: err.Error()}, nil
+		}
+		return &pb.AppSendResponse{
+			Success:     true,
+			Message:     fmt.Sprintf("sent to service %s via %s", req.Service, provider),
+			Destination: provider,
+		}, nil
+	}
+
+	if req.Destination == "" {
+		return &pb.AppSendResponse{Success: false, Message: "destination or service is required"}, nil
+	}
+	err := s.fm.SendPacketWithOptions(ctx, req.Destination, req.Payload, SendOptions{Headers: req.Headers})
+	if err != nil {
+		return &pb.AppSendResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &pb.AppSendResponse{
+		Success:     true,
+		Message:     fmt.Sprintf("sent to %s", req.Destination),
+		Destination: req.Destination,
+	}, nil
+}
+
+func (s *AppServer) Receive(req *pb.AppReceiveRequest, stream pb.AppService_ReceiveServer) error {
+	ch := make(chan *pb.AppMessage, s.bufSize)
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.receivers[id] = ch
+	s.mu.Unlock()
+
+	log.Printf("[%s] App receiver %d attached", s.nodeID, id)
+	defer func() {
+		s.mu.Lock()
+		delete(s.receivers, id)
+		s.mu.Unlock()
+		log.Printf("[%s] App receiver %d detached", s.nodeID, id)
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case msg := <-ch:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *AppServer) Request(ctx context.Context, req *pb.AppRequest) (*pb.AppResponse, error) {
+	if req.Destination == "" {
+		return &pb.AppResponse{Success: false, Message: "destination cannot be empty"}, nil
+	}
+
+	timeout := DefaultAppRequestTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	requestID := s.newRequestID()
+	ch := make(chan *pb.Packet, 1)
+	s.mu.Lock()
+	s.pending[requestID] = &pendingRequest{destination: req.Destination, ch: ch}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, requestID)
+		s.mu.Unlock()
+	}()
+
+	err := s.fm.SendPacketWithOptions(ctx, req.Destination, req.Payload, SendOptions{RequestID: requestID})
+	if err != nil {
+		return &pb.AppResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	select {
+	case reply := <-ch:
+		return &pb.AppResponse{
+			Success: true,
+			Message: fmt.Sprintf("reply from %s", reply.Source),
+			Source:  reply.Source,
+			Payload: reply.Payload,
+		}, nil
+	case <-ctx.Done():
+		msg := ctx.Err().Error()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			msg = fmt.Sprintf("no reply from %s within %v", req.Destination, timeout)
+		}
+		return &pb.AppResponse{Success: false, Message: msg}, nil
+	}
+}
+
+func (s *AppServer) Reply(ctx context.Context, req *pb.AppReplyRequest) (*pb.AppReplyResponse, error) {
+	if req.Destination == "" || req.RequestId == "" {
+		return &pb.AppReplyResponse{Success: false, Message: "destination and request_id are required"}, nil
+	}
+
+	err := s.fm.SendPacketWithOptions(ctx, req.Destination, req.Payload, SendOptions{ReplyTo: req.RequestId})
+	if err != nil {
+		return &pb.AppReplyResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &pb.AppReplyResponse{
+		Success: true,
+		Message: fmt.Sprintf("replied to %s", req.Destination),
+	}, nil
+}
+
+func (s *AppServer) GetRoute(ctx context.Context, req *pb.GetRouteRequest) (*pb.GetRouteResponse, error) {
+	return s.control.GetRoute(ctx, req)
+}
+
+func (s *AppServer) GetRoutes(ctx context.Context, req *pb.GetRoutesRequest) (*pb.GetRoutesResponse, error) {
+	return s.control.GetRoutes(ctx, req)
+}
+
+func (s *AppServer) GetTopology(ctx context.Context, req *pb.GetTopologyRequest) (*pb.GetTopologyResponse, error) {
+	return s.control.GetTopology(ctx, req)
+}
@@ -0,0 +1,31 @@
+package route
+
+import (
+	"testing"
+
+	pb "spfnet/proto"
+)
+
+// 只接受来自请求目的节点的应答，其他节点携带相同请求 ID 的数据包不会完成请求
+func TestAppServerReplyMustComeFromDestination(t *testing.T) {
+	s := NewAppServer("n1", nil, nil, 0)
+	ch := make(chan *pb.Packet, 1)
+	s.pending["req-1"] = &pendingRequest{destination: "n2", ch: ch}
+
+	if s.HandleDelivery(&pb.Packet{Source: "n3", PacketId: "p1", ReplyTo: "req-1"}) {
+		t.Fatal("reply from a node other than the destination was accepted")
+	}
+	if len(ch) != 0 {
+		t.Fatal("spoofed reply reached the pending request")
+	}
+
+	if !s.HandleDelivery(&pb.Packet{Source: "n2", PacketId: "p2", ReplyTo: "req-1"}) {
+		t.Fatal("reply from the destination was not accepted")
+	}
+	if reply := <-ch; reply.PacketId != "p2" {
+		t.Fatalf("pending request got packet %s, want p2", reply.PacketId)
+	}
+	if _, ok := s.pending["req-1"]; ok {
+		t.Fatal("pending request not removed after reply")
+	}
+}
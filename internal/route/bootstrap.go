@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	pb "spfnet/proto"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // RouteNode 代表整个路由节点应用
@@ -19,8 +21,23 @@ type RouteNode struct {
 	node           *Node
 	routeManager   *RouteManager
 	forwardManager *ForwardManager
+	streamManager  *StreamManager
+	tunnelManager  *TunnelManager
+	udpRelays      *UDPRelayManager
+	fileTransfer   *FileTransferManager
+	appServer      *AppServer
 	topologySync   *TopologySync
 	grpcServer     *grpc.Server
+	outbox         *Outbox
+	events         *EventBus
+	tls            *NodeTLS // 为 nil 时 gRPC 服务和客户端使用明文连接
+
+	deliveryMtx sync.RWMutex
+	onDeliver   func(*pb.Packet) // 用户设置的投递回调，见 SetDeliveryHandler
+
+	interceptors []Interceptor // Init 之前设置的拦截器，见 SetInterceptors
+
+	membershipMtx sync.Mutex // 串行化服务、多播组和主题列表的读取-修改-写入
 }
 
 // NewRouteNode 创建一个新的 RouteNode 实例
@@ -44,17 +61,154 @@ func (n *RouteNode) Init() error {
 	// 2. 创建核心组件
 	n.node = NewNode(n.config.NodeID, n.config.NodeIP, n.config.GRPCPort)
 	topology := n.node.GetTopology()
+	if len(n.config.Services) > 0 {
+		if err := n.node.SetServices(n.config.Services); err != nil {
+			return err
+		}
+		log.Printf("[%s] Providing services: %v", n.config.NodeID, n.config.Services)
+	}
+	if len(n.config.Groups) > 0 {
+		if err := n.node.SetGroups(n.config.Groups); err != nil {
+			return err
+		}
+		log.Printf("[%s] Joined multicast groups: %v", n.config.NodeID, n.config.Groups)
+	}
 
 	n.routeManager = NewRouteManager(n.config.NodeID, topology)
 	n.forwardManager = NewForwardManager(n.config.NodeID, topology, n.routeManager)
+	n.forwardManager.SetDeliveryHandler(n.deliver)
+	n.forwardManager.SetInterceptors(n.interceptors)
 	n.topologySync = NewTopologySync(n.node, topology)
 
+	// 成员、链路和路由变化事件
+	n.events = NewEventBus(n.config.NodeID)
+	n.topologySync.SetEventBus(n.events)
+	n.routeManager.SetEventBus(n.events)
+
 	// 3. 应用拓扑配置
 	if n.config.AppConfig.Topology.SyncInterval > 0 {
 		syncInterval := time.Duration(n.config.AppConfig.Topology.SyncInterval) * time.Second
 		n.topologySync.SetSyncInterval(syncInterval)
 		log.Printf("[%s] Set topology sync interval to %v", n.config.NodeID, syncInterval)
 	}
+	if n.config.AppConfig.Topology.ProbeInterval > 0 {
+		probeInterval := time.Duration(n.config.AppConfig.Topology.ProbeInterval) * time.Second
+		n.topologySync.SetProbeInterval(probeInterval)
+		log.Printf("[%s] Set link re-probe interval to %v", n.config.NodeID, probeInterval)
+	}
+
+	forwardCfg := n.config.AppConfig.Forward
+	if forwardCfg.TTL > 0 {
+		n.forwardManager.SetDefaultTTL(uint32(forwardCfg.TTL))
+	}
+	n.forwardManager.SetMaxPayloadSize(forwardCfg.MaxPayloadSize)
+	n.forwardManager.SetBlockedDestinations(forwardCfg.BlockedDestinations)
+	if err := n.forwardManager.SetForwardMode(forwardCfg.Mode); err != nil {
+		return err
+	}
+	n.forwardManager.SetRetryPolicy(forwardCfg.QueueSize, forwardCfg.MaxAttempts,
+		time.Duration(forwardCfg.RetryIntervalMs)*time.Millisecond)
+
+	// 载荷压缩
+	compressionCfg := n.config.AppConfig.Compression
+	if err := n.forwardManager.SetCompression(compressionCfg.Algorithm, compressionCfg.MinSize, compressionCfg.Level); err != nil {
+		return err
+	}
+	if compressionCfg.Algorithm != "" && compressionCfg.Algorithm != "none" {
+		log.Printf("[%s] Payload compression enabled: %s (min_size=%d)",
+			n.config.NodeID, compressionCfg.Algorithm, compressionCfg.MinSize)
+	}
+
+	// 节点间 gRPC 连接的 TLS
+	if tlsCfg := n.config.AppConfig.TLS; tlsCfg.Enabled {
+		if tlsCfg.CertFile == "" || tlsCfg.KeyFile == "" {
+			return fmt.Errorf("tls.cert_file and tls.key_file are required when TLS is enabled")
+		}
+		tlsCfg.CertFile = strings.ReplaceAll(tlsCfg.CertFile, "{node_id}", n.config.NodeID)
+		tlsCfg.KeyFile = strings.ReplaceAll(tlsCfg.KeyFile, "{node_id}", n.config.NodeID)
+		nodeTLS, err := LoadNodeTLS(tlsCfg)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		n.tls = nodeTLS
+		n.forwardManager.SetTLS(nodeTLS)
+		n.routeManager.SetTLS(nodeTLS)
+		n.topologySync.SetTLS(nodeTLS)
+		log.Printf("[%s] TLS enabled (cert: %s, client_auth: %s)", n.config.NodeID, tlsCfg.CertFile, tlsCfg.ClientAuth)
+	}
+
+	// 端到端加密：公钥通过 Serf 标签发布，需在节点启动前设置
+	if encryptionCfg := n.config.AppConfig.Encryption; encryptionCfg.Enabled {
+		keyPath := filepath.Join(encryptionCfg.KeyDir, n.config.NodeID+".key")
+		key, err := LoadOrCreateKey(keyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load encryption key: %w", err)
+		}
+		n.node.SetPublicKey(key.PublicKey().Bytes())
+		n.forwardManager.SetEncryption(key, encryptionCfg.Require)
+		log.Printf("[%s] End-to-end encryption enabled (key: %s, require: %v)",
+			n.config.NodeID, keyPath, encryptionCfg.Require)
+	}
+
+	// 目的节点去重
+	if dedupCfg := n.config.AppConfig.Dedup; dedupCfg.Enabled {
+		window := time.Duration(dedupCfg.WindowSeconds) * time.Second
+		n.forwardManager.SetDedupCache(NewDedupCache(window, dedupCfg.MaxEntries))
+		log.Printf("[%s] Duplicate suppression enabled: window=%v, max_entries=%d",
+			n.config.NodeID, window, dedupCfg.MaxEntries)
+	}
+
+	// 打开持久化发件箱
+	if outboxCfg := n.config.AppConfig.Outbox; outboxCfg.Enabled {
+		outbox, err := OpenOutbox(
+			filepath.Join(outboxCfg.Dir, n.config.NodeID),
+			int64(outboxCfg.MaxSizeMB)<<20,
+			int64(outboxCfg.SegmentSizeMB)<<20,
+			outboxCfg.Fsync,
+			time.Duration(outboxCfg.FsyncIntervalMs)*time.Millisecond,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to open outbox: %w", err)
+		}
+		n.outbox = outbox
+		n.forwardManager.SetOutbox(outbox)
+	}
+
+	// 覆盖网络字节流
+	n.streamManager = NewStreamManager(n.config.NodeID, n.forwardManager)
+	n.streamManager.SetConfig(n.config.AppConfig.Stream)
+
+	// 端口转发隧道
+	n.tunnelManager = NewTunnelManager(n.config.NodeID, n.streamManager)
+	n.tunnelManager.SetAllowedTargets(n.config.AppConfig.Tunnel.AllowedTargets)
+
+	// UDP 中继
+	udpCfg := n.config.AppConfig.UDP
+	n.udpRelays = NewUDPRelayManager(n.config.NodeID, n.forwardManager)
+	if udpCfg.MaxDatagramSize > 0 {
+		if err := n.udpRelays.SetMaxDatagramSize(udpCfg.MaxDatagramSize); err != nil {
+			return err
+		}
+	}
+	n.udpRelays.SetAllowedTargets(udpCfg.AllowedTargets)
+
+	// 文件传输
+	fileCfg := n.config.AppConfig.FileTransfer
+	n.fileTransfer = NewFileTransferManager(n.config.NodeID, n.streamManager)
+	n.fileTransfer.SetMaxAttempts(fileCfg.MaxAttempts)
+	n.fileTransfer.SetMaxFileSize(int64(fileCfg.MaxFileSizeMB) << 20)
+	if fileCfg.Enabled {
+		inbox := filepath.Join(fileCfg.InboxDir, n.config.NodeID)
+		n.fileTransfer.SetInboxDir(inbox)
+		log.Printf("[%s] Receiving files into %s", n.config.NodeID, inbox)
+		if fileCfg.MaxFileSizeMB == 0 {
+			log.Printf("[%s] Warning: file_transfer.max_file_size_mb is 0, any cluster node can send files of unlimited size", n.config.NodeID)
+		}
+	}
+	if fileCfg.SendDir != "" {
+		n.fileTransfer.SetSendDir(fileCfg.SendDir)
+		log.Printf("[%s] Control service may send files from %s", n.config.NodeID, fileCfg.SendDir)
+	}
 
 	// 4. 设置拓扑变化回调
 	n.topologySync.SetTopologyChangeCallback(func() {
@@ -94,8 +248,9 @@ func (n *RouteNode) Start() error {
 		}
 	}
 
-	// 3. 启动拓扑同步
+	// 3. 启动拓扑同步和后台转发队列
 	n.topologySync.Start()
+	n.forwardManager.Start()
 
 	// 4. 启动 gRPC 服务器
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", n.config.GRPCPort))
@@ -103,9 +258,10 @@ func (n *RouteNode) Start() error {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
-	n.grpcServer = grpc.NewServer()
-	pb.RegisterNodeServiceServer(n.grpcServer, NewNodeServer(n.config.NodeID, n.forwardManager))
-	pb.RegisterControlServiceServer(n.grpcServer, NewControlServer(n.config.NodeID, topology, n.forwardManager, n.topologySync))
+	n.grpcServer = grpc.NewServer(n.tls.ServerOptions()...)
+	pb.RegisterNodeServiceServer(n.grpcServer, NewNodeServer(n.config.NodeID, n.forwardManager, n.tls))
+	controlServer := NewControlServer(n.config.NodeID, topology, n.forwardManager, n.topologySync, n.routeManager, n.events, n.tunnelManager, n.fileTransfer, n.tls)
+	pb.RegisterControlServiceServer(n.grpcServer, controlServer)
 
 	go func() {
 		log.Printf("[%s] gRPC server started on :%d\n", n.config.NodeID, n.config.GRPCPort)
@@ -114,12 +270,44 @@ func (n *RouteNode) Start() error {
 		}
 	}()
 
-	// 5. 打印初始拓扑
+	// 5. 启动端口转发隧道、UDP 中继和文件接收
+	if err := n.tunnelManager.Start(); err != nil {
+		return err
+	}
+	if err := n.fileTransfer.Start(); err != nil {
+		return err
+	}
+	for _, spec := range n.config.AppConfig.Tunnel.Forwards {
+		if _, err := n.tunnelManager.Add(spec); err != nil {
+			return fmt.Errorf("failed to start tunnel %s: %w", spec.Listen, err)
+		}
+	}
+	for _, spec := range n.config.AppConfig.UDP.Relays {
+		if _, err := n.udpRelays.Add(spec); err != nil {
+			return fmt.Errorf("failed to start udp relay %s: %w", spec.Listen, err)
+		}
+	}
+
+	// 6. 启动本地应用服务
+	if appCfg := n.config.AppConfig.AppService; appCfg.Listen != "" {
+		appServer := NewAppServer(n.config.NodeID, n.forwardManager, controlServer, appCfg.ReceiveBuffer)
+		if err := appServer.Start(appCfg.Listen); err != nil {
+			return fmt.Errorf("failed to start app service: %w", err)
+		}
+		n.deliveryMtx.Lock()
+		n.appServer = appServer
+		n.deliveryMtx.Unlock()
+	}
+
+	// 7. 打印初始拓扑
 	time.Sleep(1 * time.Second)
 	log.Printf("\n[%s] Initial Topology:", n.config.NodeID)
 	log.Println(topology.String())
 
-	// 6. 定期打印拓扑
+	// 重放上次退出前未处理完成的数据包（路由未就绪时由后台队列重试）
+	go n.forwardManager.ReplayOutbox()
+
+	// 8. 定期打印拓扑
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
@@ -135,9 +323,32 @@ func (n *RouteNode) Start() error {
 // Stop 停止应用
 func (n *RouteNode) Stop() {
 	log.Printf("\n[%s] Shutting down...", n.config.NodeID)
+	if n.appServer != nil {
+		n.appServer.Stop()
+	}
+	if n.tunnelManager != nil {
+		n.tunnelManager.Close()
+	}
+	if n.udpRelays != nil {
+		n.udpRelays.Close()
+	}
+	if n.fileTransfer != nil {
+		n.fileTransfer.Close()
+	}
+	if n.streamManager != nil {
+		n.streamManager.Close()
+	}
 	if n.grpcServer != nil {
 		n.grpcServer.GracefulStop()
 	}
+	if n.forwardManager != nil {
+		n.forwardManager.Close()
+	}
+	if n.outbox != nil {
+		if err := n.outbox.Close(); err != nil {
+			log.Printf("[%s] Failed to close outbox: %v", n.config.NodeID, err)
+		}
+	}
 	log.Printf("[%s] Shutdown complete", n.config.NodeID)
 }
 
@@ -168,69 +379,440 @@ func (n *RouteNode) SendPacket(ctx context.Context, destination string, payload
 	return n.forwardManager.SendPacket(ctx, destination, payload)
 }
 
-// AddLink 添加到邻居节点的链路
-func (n *RouteNode) AddLink(ctx context.Context, neighborID, neighborAddr string, cost float64, autoProbe bool) error {
+// SendPacketWithOptions 使用指定选项发送数据包到目标节点
+func (n *RouteNode) SendPacketWithOptions(ctx context.Context, destination string, payload []byte, opts SendOptions) error {
+	if n.forwardManager == nil {
+		return fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.SendPacketWithOptions(ctx, destination, payload, opts)
+}
+
+// SetDeliveryHandler 设置业务数据包投递到本节点时的回调
+func (n *RouteNode) SetDeliveryHandler(handler func(*pb.Packet)) {
+	n.deliveryMtx.Lock()
+	defer n.deliveryMtx.Unlock()
+	n.onDeliver = handler
+}
+
+// deliver 将投递到本节点的数据包交给本地应用服务和用户回调
+func (n *RouteNode) deliver(packet *pb.Packet) {
+	n.deliveryMtx.RLock()
+	appServer, handler := n.appServer, n.onDeliver
+	n.deliveryMtx.RUnlock()
+
+	if appServer != nil && appServer.HandleDelivery(packet) {
+		return
+	}
+	if handler != nil {
+		handler(packet)
+	}
+}
+
+// SetInterceptors 设置业务数据包拦截器链（替换已有的拦截器），可在 Init 之前调用
+func (n *RouteNode) SetInterceptors(interceptors ...Interceptor) {
+	n.interceptors = append([]Interceptor(nil), interceptors...)
+	if n.forwardManager != nil {
+		n.forwardManager.SetInterceptors(n.interceptors)
+	}
+}
+
+// AddInterceptor 在拦截器链末尾追加拦截器，可在 Init 之前调用
+func (n *RouteNode) AddInterceptor(interceptor Interceptor) {
+	n.interceptors = append(n.interceptors, interceptor)
+	if n.forwardManager != nil {
+		n.forwardManager.AddInterceptor(interceptor)
+	}
+}
+
+// SetControlHandler 设置收到控制报文（数据包无法投递）时的回调
+func (n *RouteNode) SetControlHandler(handler func(*ControlError)) {
+	if n.forwardManager != nil {
+		n.forwardManager.SetControlHandler(handler)
+	}
+}
+
+// NodeID 返回本节点 ID
+func (n *RouteNode) NodeID() string {
+	return n.config.NodeID
+}
+
+// PublicKey 返回本节点的端到端加密公钥，未启用加密时返回 nil
+func (n *RouteNode) PublicKey() []byte {
+	if n.node == nil || len(n.node.PublicKey) == 0 {
+		return nil
+	}
+	return append([]byte(nil), n.node.PublicKey...)
+}
+
+// GetRouteTable 获取当前路由表
+func (n *RouteNode) GetRouteTable() *RouteTable {
+	if n.routeManager == nil {
+		return NewRouteTable(n.config.NodeID)
+	}
+	return n.routeManager.GetRouteTable()
+}
+
+// GetTopology 获取本节点视角下的集群拓扑
+func (n *RouteNode) GetTopology() *Topology {
 	if n.node == nil {
-		return fmt.Errorf("node not initialized")
+		return NewTopology()
+	}
+	return n.node.GetTopology()
+}
+
+// Services 返回本节点提供的服务
+func (n *RouteNode) Services() []string {
+	if n.node == nil {
+		return append([]string(nil), n.config.Services...)
 	}
+	return n.node.GetServices()
+}
 
-	topology := n.node.GetTopology()
+// AdvertiseService 声明本节点提供指定服务，通过 Serf 标签通知集群
+func (n *RouteNode) AdvertiseService(service string) error {
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
 
-	// 添加邻居节点到拓扑
-	neighborNode := &NodeInfo{
-		ID:      neighborID,
-		RPCAddr: neighborAddr,
-		Status:  NodeStatusUnknown,
+	services := n.Services()
+	for _, name := range services {
+		if name == service {
+			return nil
+		}
 	}
-	topology.AddNode(neighborNode)
+	return n.setServices(append(services, service))
+}
 
-	// 确定链路成本
-	finalCost := cost
-	if autoProbe || finalCost <= 0 {
-		// 自动探测链路质量
-		probedCost, err := n.probeLinkCost(ctx, neighborAddr)
-		if err != nil {
-			return fmt.Errorf("failed to probe link: %w", err)
+// WithdrawService 撤销本节点提供的指定服务
+func (n *RouteNode) WithdrawService(service string) error {
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
+
+	var services []string
+	for _, name := range n.Services() {
+		if name != service {
+			services = append(services, name)
+		}
+	}
+	return n.setServices(services)
+}
+
+// setServices 更新本节点提供的服务
+func (n *RouteNode) setServices(services []string) error {
+	if n.node == nil {
+		n.config.Services = services
+		return nil
+	}
+
+	if err := n.node.SetServices(services); err != nil {
+		return err
+	}
+	log.Printf("[%s] Providing services: %v", n.config.NodeID, services)
+	return nil
+}
+
+// ServiceProviders 返回提供指定服务且可达的节点，按路由成本从低到高排序
+func (n *RouteNode) ServiceProviders(service string) []string {
+	if n.forwardManager == nil {
+		return nil
+	}
+	return n.forwardManager.ServiceProviders(service)
+}
+
+// SendToService 发送数据包到最近的服务提供者，不可达时自动改投下一个提供者
+// 返回最终接收数据包的节点 ID
+func (n *RouteNode) SendToService(ctx context.Context, service string, payload []byte) (string, error) {
+	if n.forwardManager == nil {
+		return "", fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.SendToService(ctx, service, payload)
+}
+
+// SendToServiceWithOptions 使用指定选项发送数据包到最近的服务提供者
+func (n *RouteNode) SendToServiceWithOptions(ctx context.Context, service string, payload []byte, opts SendOptions) (string, error) {
+	if n.forwardManager == nil {
+		return "", fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.SendToServiceWithOptions(ctx, service, payload, opts)
+}
+
+// Groups 返回本节点加入的多播组
+func (n *RouteNode) Groups() []string {
+	if n.node == nil {
+		return append([]string(nil), n.config.Groups...)
+	}
+	return n.node.GetGroups()
+}
+
+// JoinGroup 加入多播组，通过 Serf 标签通知集群
+func (n *RouteNode) JoinGroup(group string) error {
+	if group == "" || group == BroadcastGroup {
+		return fmt.Errorf("invalid group name %q", group)
+	}
+
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
+
+	groups := n.Groups()
+	if containsString(groups, group) {
+		return nil
+	}
+	return n.setGroups(append(groups, group))
+}
+
+// LeaveGroup 离开多播组
+func (n *RouteNode) LeaveGroup(group string) error {
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
+
+	var groups []string
+	for _, name := range n.Groups() {
+		if name != group {
+			groups = append(groups, name)
 		}
-		finalCost = probedCost
-		log.Printf("[%s] Auto-probed link cost to %s: %.2f", n.config.NodeID, neighborID, finalCost)
+	}
+	return n.setGroups(groups)
+}
+
+// setGroups 更新本节点加入的多播组
+func (n *RouteNode) setGroups(groups []string) error {
+	if n.node == nil {
+		n.config.Groups = groups
+		return nil
+	}
+
+	if err := n.node.SetGroups(groups); err != nil {
+		return err
+	}
+	log.Printf("[%s] Joined multicast groups: %v", n.config.NodeID, groups)
+	return nil
+}
+
+// GroupMembers 返回多播组中当前可达的成员（不含本节点）
+func (n *RouteNode) GroupMembers(group string) []string {
+	if n.forwardManager == nil {
+		return nil
+	}
+	return n.forwardManager.GroupMembers(group)
+}
+
+// SendMulticast 向多播组的所有可达成员发送数据包，数据包只在最短路径树的分支处复制
+func (n *RouteNode) SendMulticast(ctx context.Context, group string, payload []byte) (*MulticastResult, error) {
+	if n.forwardManager == nil {
+		return nil, fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.SendMulticast(ctx, group, payload)
+}
+
+// SendBroadcast 向集群中所有可达节点发送数据包
+func (n *RouteNode) SendBroadcast(ctx context.Context, payload []byte) (*MulticastResult, error) {
+	if n.forwardManager == nil {
+		return nil, fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.SendBroadcast(ctx, payload)
+}
+
+// Topics 返回本节点订阅的主题
+func (n *RouteNode) Topics() []string {
+	if n.node == nil {
+		return nil
+	}
+	return n.node.GetTopics()
+}
+
+// SubscribeTopic 订阅主题，通过 Serf 标签通知集群
+func (n *RouteNode) SubscribeTopic(topic string) error {
+	if n.node == nil {
+		return fmt.Errorf("node not initialized")
+	}
+	if topic == "" {
+		return fmt.Errorf("topic cannot be empty")
+	}
+
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
+
+	topics := n.node.GetTopics()
+	if containsString(topics, topic) {
+		return nil
+	}
+	return n.setTopics(append(topics, topic))
+}
+
+// UnsubscribeTopic 取消订阅主题
+func (n *RouteNode) UnsubscribeTopic(topic string) error {
+	if n.node == nil {
+		return fmt.Errorf("node not initialized")
 	}
 
-	// 更新拓扑中的链路
-	topology.UpdateLink(n.config.NodeID, neighborID, finalCost)
+	n.membershipMtx.Lock()
+	defer n.membershipMtx.Unlock()
 
-	// 通过 Serf 广播链路更新事件
-	if n.topologySync != nil {
-		if err := n.topologySync.RegisterLink(n.config.NodeID, neighborID, finalCost); err != nil {
-			log.Printf("[%s] Warning: Failed to broadcast link update: %v", n.config.NodeID, err)
+	var topics []string
+	for _, name := range n.node.GetTopics() {
+		if name != topic {
+			topics = append(topics, name)
 		}
 	}
+	return n.setTopics(topics)
+}
 
-	log.Printf("[%s] ✓ Successfully added link to %s with cost %.2f", n.config.NodeID, neighborID, finalCost)
+// setTopics 更新本节点订阅的主题
+func (n *RouteNode) setTopics(topics []string) error {
+	if err := n.node.SetTopics(topics); err != nil {
+		return err
+	}
+	log.Printf("[%s] Subscribed topics: %v", n.config.NodeID, topics)
 	return nil
 }
 
-// probeLinkCost 探测链路成本
-func (n *RouteNode) probeLinkCost(ctx context.Context, targetAddr string) (float64, error) {
-	conn, err := grpc.Dial(targetAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return 0, fmt.Errorf("failed to connect: %w", err)
+// TopicSubscribers 返回订阅指定主题且可达的节点（含本节点）
+func (n *RouteNode) TopicSubscribers(topic string) []string {
+	if n.forwardManager == nil {
+		return nil
 	}
-	defer conn.Close()
+	return n.forwardManager.TopicSubscribers(topic)
+}
 
-	client := pb.NewNodeServiceClient(conn)
-	resp, err := client.ProbeLinkQuality(ctx, &pb.ProbeRequest{
-		Source:    n.config.NodeID,
-		Target:    "",
-		SelfDebug: true,
-	})
+// Publish 向订阅指定主题的所有可达节点发送数据包
+func (n *RouteNode) Publish(ctx context.Context, topic string, payload []byte) (*MulticastResult, error) {
+	if n.forwardManager == nil {
+		return nil, fmt.Errorf("forward manager not initialized")
+	}
+	return n.forwardManager.Publish(ctx, topic, payload)
+}
+
+// SubscriberStats 返回本节点发布的主题按订阅者细分的投递统计（topic 为空表示全部主题）
+func (n *RouteNode) SubscriberStats(topic string) []SubscriberStats {
+	if n.forwardManager == nil {
+		return nil
+	}
+	return n.forwardManager.GetSubscriberStats(topic)
+}
+
+// Listen 在覆盖网络的指定端口上监听字节流连接
+func (n *RouteNode) Listen(port uint32) (*StreamListener, error) {
+	if n.streamManager == nil {
+		return nil, fmt.Errorf("stream manager not initialized")
+	}
+	return n.streamManager.Listen(port)
+}
+
+// Dial 与目的节点指定端口建立字节流连接
+func (n *RouteNode) Dial(ctx context.Context, destination string, port uint32) (*StreamConn, error) {
+	if n.streamManager == nil {
+		return nil, fmt.Errorf("stream manager not initialized")
+	}
+	return n.streamManager.Dial(ctx, destination, port)
+}
+
+// AddTunnel 添加端口转发隧道
+func (n *RouteNode) AddTunnel(spec TunnelSpec) (TunnelStats, error) {
+	if n.tunnelManager == nil {
+		return TunnelStats{}, fmt.Errorf("tunnel manager not initialized")
+	}
+	return n.tunnelManager.Add(spec)
+}
+
+// RemoveTunnel 删除端口转发隧道
+func (n *RouteNode) RemoveTunnel(name string) error {
+	if n.tunnelManager == nil {
+		return fmt.Errorf("tunnel manager not initialized")
+	}
+	return n.tunnelManager.Remove(name)
+}
+
+// Tunnels 返回端口转发隧道及其统计
+func (n *RouteNode) Tunnels() []TunnelStats {
+	if n.tunnelManager == nil {
+		return nil
+	}
+	return n.tunnelManager.List()
+}
+
+// AddUDPRelay 添加 UDP 中继
+func (n *RouteNode) AddUDPRelay(spec UDPRelaySpec) (UDPRelayStats, error) {
+	if n.udpRelays == nil {
+		return UDPRelayStats{}, fmt.Errorf("udp relay manager not initialized")
+	}
+	return n.udpRelays.Add(spec)
+}
+
+// RemoveUDPRelay 删除 UDP 中继
+func (n *RouteNode) RemoveUDPRelay(name string) error {
+	if n.udpRelays == nil {
+		return fmt.Errorf("udp relay manager not initialized")
+	}
+	return n.udpRelays.Remove(name)
+}
+
+// UDPRelays 返回 UDP 中继及其统计
+func (n *RouteNode) UDPRelays() []UDPRelayStats {
+	if n.udpRelays == nil {
+		return nil
+	}
+	return n.udpRelays.List()
+}
+
+// SendFile 将本地文件发送到目标节点的收件目录，name 为空时使用源文件名
+func (n *RouteNode) SendFile(ctx context.Context, destination, path, name string) (*FileTransferResult, error) {
+	if n.fileTransfer == nil {
+		return nil, fmt.Errorf("file transfer not initialized")
+	}
+	return n.fileTransfer.SendFile(ctx, destination, path, name)
+}
+
+// SubscribeEvents 订阅成员、链路和路由变化事件（types 为空表示全部）
+// 返回事件通道和取消订阅函数
+func (n *RouteNode) SubscribeEvents(types ...pb.EventType) (<-chan *Event, func()) {
+	if n.events == nil {
+		ch := make(chan *Event)
+		close(ch)
+		return ch, func() {}
+	}
+	return n.events.Subscribe(0, types...)
+}
+
+// AddLink 添加到邻居节点的链路
+func (n *RouteNode) AddLink(ctx context.Context, neighborID, neighborAddr string, cost float64, autoProbe bool) error {
+	if n.topologySync == nil {
+		return fmt.Errorf("node not initialized")
+	}
+
+	finalCost, _, err := n.topologySync.AddLocalLink(ctx, neighborID, neighborAddr, cost, autoProbe)
 	if err != nil {
-		return 0, fmt.Errorf("probe request failed: %w", err)
+		return err
+	}
+
+	log.Printf("[%s] ✓ Successfully added link to %s with cost %.2f", n.config.NodeID, neighborID, finalCost)
+	return nil
+}
+
+// RemoveLink 删除到邻居节点的链路，并广播到整个集群
+func (n *RouteNode) RemoveLink(neighborID string) error {
+	if n.topologySync == nil {
+		return fmt.Errorf("topology sync not initialized")
 	}
 
-	if !resp.Success {
-		return 0, fmt.Errorf("probe failed: %s", resp.Message)
+	if err := n.topologySync.RemoveLocalLink(neighborID); err != nil {
+		return err
 	}
 
-	return resp.Cost, nil
+	log.Printf("[%s] ✓ Successfully removed link to %s", n.config.NodeID, neighborID)
+	return nil
+}
+
+// SetLinkCost 修改到邻居节点的链路成本
+// autoProbe 为 true 时重新探测成本，否则使用 cost 并将链路标记为手动固定
+func (n *RouteNode) SetLinkCost(ctx context.Context, neighborID string, cost float64, autoProbe bool) error {
+	if n.topologySync == nil {
+		return fmt.Errorf("topology sync not initialized")
+	}
+
+	finalCost, pinned, err := n.topologySync.SetLocalLinkCost(ctx, neighborID, cost, autoProbe)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[%s] ✓ Link cost to %s set to %.2f (pinned=%v)", n.config.NodeID, neighborID, finalCost, pinned)
+	return nil
 }
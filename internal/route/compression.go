@@ -0,0 +1,160 @@
+package route
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	pb "spfnet/proto"
+)
+
+// 压缩算法名称（配置文件中使用）
+const (
+	CompressionGzip    = "gzip"
+	CompressionDeflate = "deflate"
+)
+
+// DefaultCompressionMinSize 默认的压缩阈值，载荷小于该字节数时不压缩
+const DefaultCompressionMinSize = 1024
+
+// maxDecompressedSize 解压后载荷的上限，防止恶意构造的数据包耗尽内存
+const maxDecompressedSize = 64 << 20
+
+// parseCompression 解析压缩算法名称，空字符串或 "none" 表示不压缩
+func parseCompression(name string) (pb.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return pb.Compression_COMPRESSION_NONE, nil
+	case CompressionGzip:
+		return pb.Compression_COMPRESSION_GZIP, nil
+	case CompressionDeflate:
+		return pb.Compression_COMPRESSION_DEFLATE, nil
+	default:
+		return pb.Compression_COMPRESSION_NONE, fmt.Errorf("unknown compression algorithm %q (expected gzip, deflate or none)", name)
+	}
+}
+
+// compressionName 返回压缩算法在配置文件中的名称
+func compressionName(algo pb.Compression) string {
+	return strings.ToLower(strings.TrimPrefix(algo.String(), "COMPRESSION_"))
+}
+
+// SetCompression 设置本节点发出的业务数据包的载荷压缩
+// algorithm 为空或 "none" 时不压缩；载荷小于 minSize 字节或压缩后没有变小时按原样发送；
+// level 为 1-9，0 表示默认级别
+func (fm *ForwardManager) SetCompression(algorithm string, minSize, level int) error {
+	algo, err := parseCompression(algorithm)
+	if err != nil {
+		return err
+	}
+	if level == 0 {
+		level = flate.DefaultCompression
+	} else if level < flate.BestSpeed || level > flate.BestCompression {
+		return fmt.Errorf("invalid compression level %d (expected 1-9)", level)
+	}
+
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.compression = algo
+	fm.compressionMinSize = minSize
+	fm.compressionLevel = level
+	return nil
+}
+
+// compress 按本节点的压缩设置压缩数据包载荷（已压缩的数据包不处理）
+func (fm *ForwardManager) compress(packet *pb.Packet) {
+	fm.policyMtx.RLock()
+	algo, minSize, level := fm.compression, fm.compressionMinSize, fm.compressionLevel
+	fm.policyMtx.RUnlock()
+
+	if algo == pb.Compression_COMPRESSION_NONE || packet.Compression != pb.Compression_COMPRESSION_NONE ||
+		len(packet.Payload) < minSize {
+		return
+	}
+
+	compressed, err := compressPayload(algo, level, packet.Payload)
+	if err != nil {
+		log.Printf("[%s] ✗ Failed to compress packet %s: %v", fm.nodeID, packet.PacketId, err)
+		return
+	}
+	if len(compressed) >= len(packet.Payload) {
+		return
+	}
+
+	log.Printf("[%s] Packet %s payload compressed with %s: %d -> %d bytes",
+		fm.nodeID, packet.PacketId, compressionName(algo), len(packet.Payload), len(compressed))
+	packet.Payload = compressed
+	packet.Compression = algo
+}
+
+// decompress 解压数据包载荷，成功后清除压缩标记
+func (fm *ForwardManager) decompress(packet *pb.Packet) error {
+	if packet.Compression == pb.Compression_COMPRESSION_NONE {
+		return nil
+	}
+
+	payload, err := decompressPayload(packet.Compression, packet.Payload)
+	if err != nil {
+		return err
+	}
+	packet.Payload = payload
+	packet.Compression = pb.Compression_COMPRESSION_NONE
+	return nil
+}
+
+// compressPayload 使用指定算法压缩数据
+func compressPayload(algo pb.Compression, level int, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch algo {
+	case pb.Compression_COMPRESSION_GZIP:
+		w, err = gzip.NewWriterLevel(&buf, level)
+	case pb.Compression_COMPRESSION_DEFLATE:
+		w, err = flate.NewWriter(&buf, level)
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", algo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressPayload 解压数据，解压后超过 maxDecompressedSize 时返回错误
+func decompressPayload(algo pb.Compression, data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	switch algo {
+	case pb.Compression_COMPRESSION_GZIP:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	case pb.Compression_COMPRESSION_DEFLATE:
+		r = flate.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", algo)
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", maxDecompressedSize)
+	}
+	return out, nil
+}
@@ -3,6 +3,7 @@ package route
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -16,13 +17,113 @@ type LogConfig struct {
 
 // TopologyConfig 拓扑配置
 type TopologyConfig struct {
-	SyncInterval int `toml:"sync_interval"` // 全量拓扑同步间隔（秒）
+	SyncInterval  int `toml:"sync_interval"`  // 全量拓扑同步间隔（秒）
+	ProbeInterval int `toml:"probe_interval"` // 自动探测链路的重新探测间隔（秒），0 表示不重新探测
+}
+
+// ForwardConfig 转发策略配置
+type ForwardConfig struct {
+	TTL                 int      `toml:"ttl"`                  // 数据包默认跳数上限
+	MaxPayloadSize      int      `toml:"max_payload_size"`     // 允许转发的最大载荷字节数，0 表示不限制
+	BlockedDestinations []string `toml:"blocked_destinations"` // 禁止经本节点转发的目标节点
+	Mode                string   `toml:"mode"`                 // 转发模式："sync" 或 "store_and_forward"
+	QueueSize           int      `toml:"queue_size"`           // 后台转发队列长度
+	MaxAttempts         int      `toml:"max_attempts"`         // 后台转发最大尝试次数
+	RetryIntervalMs     int      `toml:"retry_interval_ms"`    // 后台转发重试间隔（毫秒）
+}
+
+// OutboxConfig 持久化发件箱配置
+type OutboxConfig struct {
+	Enabled         bool   `toml:"enabled"`           // 是否启用
+	Dir             string `toml:"dir"`               // 存储目录，实际目录为 {dir}/{node_id}
+	MaxSizeMB       int    `toml:"max_size_mb"`       // 总大小上限（MB），超过时丢弃最旧的日志段
+	SegmentSizeMB   int    `toml:"segment_size_mb"`   // 单个日志段大小（MB）
+	Fsync           string `toml:"fsync"`             // fsync 策略："always"、"interval" 或 "never"
+	FsyncIntervalMs int    `toml:"fsync_interval_ms"` // fsync = "interval" 时的同步间隔（毫秒）
+}
+
+// DedupConfig 目的节点去重配置
+type DedupConfig struct {
+	Enabled       bool `toml:"enabled"`        // 是否启用
+	WindowSeconds int  `toml:"window_seconds"` // 去重时间窗口（秒）
+	MaxEntries    int  `toml:"max_entries"`    // 最多记录的数据包数量
+}
+
+// CompressionConfig 载荷压缩配置
+type CompressionConfig struct {
+	Algorithm string `toml:"algorithm"` // 压缩算法：gzip、deflate，为空或 "none" 时不压缩
+	MinSize   int    `toml:"min_size"`  // 载荷小于该字节数时不压缩
+	Level     int    `toml:"level"`     // 压缩级别 1-9，0 表示默认级别
+}
+
+// EncryptionConfig 端到端加密配置
+type EncryptionConfig struct {
+	Enabled bool   `toml:"enabled"` // 是否启用
+	KeyDir  string `toml:"key_dir"` // 密钥目录，私钥保存在 {key_dir}/{node_id}.key，不存在时自动生成
+	Require bool   `toml:"require"` // 拒绝发往没有公钥的节点，丢弃收到的未加密单播数据包
+}
+
+// TLSConfig 节点 gRPC 服务的 TLS 配置
+type TLSConfig struct {
+	Enabled    bool   `toml:"enabled"`     // 是否启用（需所有节点一致）
+	CAFile     string `toml:"ca_file"`     // 签发各节点证书的 CA 证书（PEM）
+	CertFile   string `toml:"cert_file"`   // 本节点证书（PEM），主题 CN 或 SAN 为节点 ID，路径中的 {node_id} 替换为节点 ID
+	KeyFile    string `toml:"key_file"`    // 本节点私钥（PEM），路径中的 {node_id} 替换为节点 ID
+	ClientAuth string `toml:"client_auth"` // 对客户端证书的要求："require"、"verify_if_given" 或 "none"
+}
+
+// StreamConfig 字节流配置
+type StreamConfig struct {
+	MSS           int `toml:"mss"`            // 单个分段的最大载荷字节数（不超过 forward.max_payload_size）
+	WindowSize    int `toml:"window_size"`    // 每个连接的接收窗口和发送缓冲区大小（字节）
+	RTOMs         int `toml:"rto_ms"`         // 初始重传超时（毫秒），每次超时加倍
+	MaxRetries    int `toml:"max_retries"`    // 连续重传次数上限，超过后连接失败
+	AcceptBacklog int `toml:"accept_backlog"` // 每个监听端口等待 Accept 的连接数上限
+}
+
+// TunnelConfig 端口转发隧道配置
+type TunnelConfig struct {
+	AllowedTargets []string     `toml:"allowed_targets"` // 本节点作为出口时允许连接的目标地址，"*" 表示任意地址，为空时拒绝所有隧道
+	Forwards       []TunnelSpec `toml:"forwards"`        // 本节点启动时建立的隧道
+}
+
+// UDPConfig UDP 中继配置
+type UDPConfig struct {
+	MaxDatagramSize int            `toml:"max_datagram_size"` // 允许中继的最大数据报字节数，超过时丢弃
+	AllowedTargets  []string       `toml:"allowed_targets"`   // 本节点作为目的节点时允许发往的 UDP 地址，"*" 表示任意地址，为空时丢弃
+	Relays          []UDPRelaySpec `toml:"relays"`            // 本节点启动时建立的 UDP 中继
+}
+
+// FileTransferConfig 文件传输配置
+type FileTransferConfig struct {
+	Enabled       bool   `toml:"enabled"`          // 是否接收其他节点发来的文件（发送不受影响）
+	InboxDir      string `toml:"inbox_dir"`        // 收件目录，实际目录为 {inbox_dir}/{node_id}
+	MaxFileSizeMB int    `toml:"max_file_size_mb"` // 允许接收的最大文件大小，0 表示不限制
+	MaxAttempts   int    `toml:"max_attempts"`     // 发送文件时的连接尝试次数，连接中断后自动续传
+	SendDir       string `toml:"send_dir"`         // 控制服务 sendfile 只能发送该目录下的文件，为空时禁止通过控制服务发送文件
+}
+
+// AppServiceConfig 本地应用服务配置
+type AppServiceConfig struct {
+	Listen        string `toml:"listen"`         // 监听地址，"unix:/path/to.sock" 或 "127.0.0.1:port"，为空时不启动
+	ReceiveBuffer int    `toml:"receive_buffer"` // 每个 Receive 流缓存的消息数，缓存满时丢弃新消息
 }
 
 // AppConfig 应用通用配置
 type AppConfig struct {
-	Log      LogConfig      `toml:"log"`
-	Topology TopologyConfig `toml:"topology"`
+	Log          LogConfig          `toml:"log"`
+	Topology     TopologyConfig     `toml:"topology"`
+	Forward      ForwardConfig      `toml:"forward"`
+	Outbox       OutboxConfig       `toml:"outbox"`
+	Dedup        DedupConfig        `toml:"dedup"`
+	Compression  CompressionConfig  `toml:"compression"`
+	Encryption   EncryptionConfig   `toml:"encryption"`
+	TLS          TLSConfig          `toml:"tls"`
+	Stream       StreamConfig       `toml:"stream"`
+	Tunnel       TunnelConfig       `toml:"tunnel"`
+	UDP          UDPConfig          `toml:"udp"`
+	FileTransfer FileTransferConfig `toml:"file_transfer"`
+	AppService   AppServiceConfig   `toml:"app_service"`
 }
 
 // NodeConfig 节点配置
@@ -30,8 +131,10 @@ type NodeConfig struct {
 	ID       string `toml:"id"`
 	IP       string `toml:"ip"`
 	GRPCPort int    `toml:"grpc_port"`
-	SerfPort int    `toml:"serf_port"`
-	Join     string `toml:"join"`
+	SerfPort int      `toml:"serf_port"`
+	Join     string   `toml:"join"`
+	Services []string `toml:"services"` // 节点提供的服务名
+	Groups   []string `toml:"groups"`   // 节点加入的多播组
 }
 
 // EdgeConfig 边配置
@@ -105,6 +208,69 @@ func LoadAppConfig(filename string) (*AppConfig, error) {
 	if config.Topology.SyncInterval == 0 {
 		config.Topology.SyncInterval = 30
 	}
+	if config.Forward.TTL == 0 {
+		config.Forward.TTL = DefaultTTL
+	}
+	if config.Forward.Mode == "" {
+		config.Forward.Mode = ForwardModeSync
+	}
+	if config.Outbox.Dir == "" {
+		config.Outbox.Dir = "data/outbox"
+	}
+	if config.Outbox.MaxSizeMB == 0 {
+		config.Outbox.MaxSizeMB = 64
+	}
+	if config.Outbox.SegmentSizeMB == 0 {
+		config.Outbox.SegmentSizeMB = 4
+	}
+	if config.Outbox.Fsync == "" {
+		config.Outbox.Fsync = FsyncInterval
+	}
+	if config.Outbox.FsyncIntervalMs == 0 {
+		config.Outbox.FsyncIntervalMs = 1000
+	}
+	if config.Dedup.WindowSeconds == 0 {
+		config.Dedup.WindowSeconds = 60
+	}
+	if config.Dedup.MaxEntries == 0 {
+		config.Dedup.MaxEntries = 10000
+	}
+	if config.Compression.MinSize == 0 {
+		config.Compression.MinSize = DefaultCompressionMinSize
+	}
+	if config.Encryption.KeyDir == "" {
+		config.Encryption.KeyDir = DefaultKeyDir
+	}
+	if config.TLS.ClientAuth == "" {
+		config.TLS.ClientAuth = ClientAuthRequire
+	}
+	if config.Stream.MSS == 0 {
+		config.Stream.MSS = DefaultStreamMSS
+	}
+	if config.Stream.WindowSize == 0 {
+		config.Stream.WindowSize = DefaultStreamWindow
+	}
+	if config.Stream.RTOMs == 0 {
+		config.Stream.RTOMs = int(DefaultStreamRTO / time.Millisecond)
+	}
+	if config.Stream.MaxRetries == 0 {
+		config.Stream.MaxRetries = DefaultStreamMaxRetries
+	}
+	if config.Stream.AcceptBacklog == 0 {
+		config.Stream.AcceptBacklog = DefaultStreamBacklog
+	}
+	if config.UDP.MaxDatagramSize == 0 {
+		config.UDP.MaxDatagramSize = DefaultMaxDatagramSize
+	}
+	if config.FileTransfer.InboxDir == "" {
+		config.FileTransfer.InboxDir = "data/inbox"
+	}
+	if config.FileTransfer.MaxAttempts == 0 {
+		config.FileTransfer.MaxAttempts = DefaultFileMaxAttempts
+	}
+	if config.AppService.ReceiveBuffer == 0 {
+		config.AppService.ReceiveBuffer = DefaultAppReceiveBuffer
+	}
 
 	return &config, nil
 }
@@ -116,6 +282,8 @@ type RuntimeConfig struct {
 	GRPCPort    int
 	SerfPort    int
 	JoinAddr    string
+	Services    []string
+	Groups      []string
 	Edges       []EdgeConfig
 	AppConfig   *AppConfig
 	logFile     *os.File // 用于延迟关闭日志文件
@@ -146,6 +314,15 @@ func LoadRuntimeConfig(
 			Topology: TopologyConfig{
 				SyncInterval: 30,
 			},
+			Forward: ForwardConfig{
+				TTL:  DefaultTTL,
+				Mode: ForwardModeSync,
+			},
+			Dedup: DedupConfig{
+				Enabled:       true,
+				WindowSeconds: 60,
+				MaxEntries:    10000,
+			},
 		}
 	}
 
@@ -172,6 +349,8 @@ func LoadRuntimeConfig(
 		rc.GRPCPort = nodeConfig.GRPCPort
 		rc.SerfPort = nodeConfig.SerfPort
 		rc.JoinAddr = nodeConfig.Join
+		rc.Services = nodeConfig.Services
+		rc.Groups = nodeConfig.Groups
 
 		// 加载边配置
 		rc.Edges = cfg.GetNodeEdges(rc.NodeID)
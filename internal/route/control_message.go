@@ -0,0 +1,81 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+
+	pb "spfnet/proto"
+)
+
+// 控制报文对应的错误类型，可通过 errors.Is 判断
+var (
+	ErrDestinationUnreachable = errors.New("destination unreachable")
+	ErrTTLExceeded            = errors.New("ttl exceeded")
+	ErrFragmentationNeeded    = errors.New("fragmentation needed")
+	ErrAdminProhibited        = errors.New("administratively prohibited")
+)
+
+// ControlError 转发路径上某一跳产生的差错（类似 ICMP 差错报文）
+type ControlError struct {
+	Code           pb.ControlCode
+	Reporter       string // 产生差错的节点 ID
+	PacketID       string // 原始数据包 ID
+	Destination    string // 原始数据包的目标节点 ID
+	Reason         string // 可读的错误原因
+	MaxPayloadSize uint32 // 报告节点允许的最大载荷长度（仅 FRAGMENTATION_NEEDED）
+}
+
+// newControlError 为指定数据包创建差错
+func newControlError(code pb.ControlCode, reporter string, packet *pb.Packet, format string, args ...interface{}) *ControlError {
+	return &ControlError{
+		Code:        code,
+		Reporter:    reporter,
+		PacketID:    packet.PacketId,
+		Destination: packet.Destination,
+		Reason:      fmt.Sprintf(format, args...),
+	}
+}
+
+// controlErrorFromProto 从控制报文还原差错
+func controlErrorFromProto(msg *pb.ControlMessage) *ControlError {
+	return &ControlError{
+		Code:           msg.Code,
+		Reporter:       msg.Reporter,
+		PacketID:       msg.OriginalPacketId,
+		Destination:    msg.OriginalDestination,
+		Reason:         msg.Reason,
+		MaxPayloadSize: msg.MaxPayloadSize,
+	}
+}
+
+// toProto 转换为控制报文
+func (e *ControlError) toProto() *pb.ControlMessage {
+	return &pb.ControlMessage{
+		Code:                e.Code,
+		Reporter:            e.Reporter,
+		OriginalPacketId:    e.PacketID,
+		OriginalDestination: e.Destination,
+		Reason:              e.Reason,
+		MaxPayloadSize:      e.MaxPayloadSize,
+	}
+}
+
+func (e *ControlError) Error() string {
+	return fmt.Sprintf("%v: %s (reported by %s)", e.Unwrap(), e.Reason, e.Reporter)
+}
+
+// Unwrap 返回差错类型对应的哨兵错误
+func (e *ControlError) Unwrap() error {
+	switch e.Code {
+	case pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE:
+		return ErrDestinationUnreachable
+	case pb.ControlCode_CONTROL_CODE_TTL_EXCEEDED:
+		return ErrTTLExceeded
+	case pb.ControlCode_CONTROL_CODE_FRAGMENTATION_NEEDED:
+		return ErrFragmentationNeeded
+	case pb.ControlCode_CONTROL_CODE_ADMIN_PROHIBITED:
+		return ErrAdminProhibited
+	default:
+		return errors.New("undeliverable")
+	}
+}
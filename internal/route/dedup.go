@@ -0,0 +1,147 @@
+package route
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DedupCache 目的节点的重复数据包过滤缓存
+// 按 (source, packet_id) 记录窗口期内已投递和正在投递的数据包，容量和时间窗口均有上限
+type DedupCache struct {
+	mtx        sync.Mutex
+	window     time.Duration
+	maxEntries int
+	entries    map[dedupKey]*list.Element
+	order      *list.List // 按首次出现时间排序，最旧的在前
+}
+
+type dedupKey struct {
+	source   string
+	packetID string
+}
+
+type dedupEntry struct {
+	key     dedupKey
+	seen    time.Time
+	pending bool // 正在投递，投递成功后由 Confirm 确认，失败时由 Release 删除
+}
+
+// NewDedupCache 创建去重缓存
+// 参数：
+//   - window: 去重时间窗口，超过窗口的记录被淘汰
+//   - maxEntries: 最大记录数，超过时淘汰最旧的记录
+func NewDedupCache(window time.Duration, maxEntries int) *DedupCache {
+	return &DedupCache{
+		window:     window,
+		maxEntries: maxEntries,
+		entries:    make(map[dedupKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Contains 检查数据包是否在窗口期内已经投递过或正在投递（不记录）
+func (c *DedupCache) Contains(source, packetID string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.evictExpired(time.Now())
+	_, exists := c.entries[dedupKey{source: source, packetID: packetID}]
+	return exists
+}
+
+// Mark 记录一个已投递的数据包，窗口期内再次到达时视为重复
+func (c *DedupCache) Mark(source, packetID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	c.evictExpired(now)
+
+	key := dedupKey{source: source, packetID: packetID}
+	if _, exists := c.entries[key]; exists {
+		return
+	}
+	c.insert(key, now, false)
+}
+
+// Reserve 原子地检查并记录一个正在投递的数据包，返回 false 表示窗口期内已投递过或正在投递
+// 投递成功后调用 Confirm，投递失败时调用 Release，发送方的重试不会被当作重复数据包
+func (c *DedupCache) Reserve(source, packetID string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	c.evictExpired(now)
+
+	key := dedupKey{source: source, packetID: packetID}
+	if _, exists := c.entries[key]; exists {
+		return false
+	}
+	c.insert(key, now, true)
+	return true
+}
+
+// Confirm 将正在投递的数据包标记为已投递，去重窗口从确认时开始计算
+func (c *DedupCache) Confirm(source, packetID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	key := dedupKey{source: source, packetID: packetID}
+	if elem, exists := c.entries[key]; exists {
+		entry := elem.Value.(*dedupEntry)
+		entry.seen = now
+		entry.pending = false
+		c.order.MoveToBack(elem)
+		return
+	}
+	// 投递期间记录已被淘汰，重新记录
+	c.evictExpired(now)
+	c.insert(key, now, false)
+}
+
+// Release 删除投递失败的数据包的预留记录，已确认的记录保持不变
+func (c *DedupCache) Release(source, packetID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := dedupKey{source: source, packetID: packetID}
+	if elem, exists := c.entries[key]; exists && elem.Value.(*dedupEntry).pending {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Len 返回当前记录数
+func (c *DedupCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.order.Len()
+}
+
+// insert 在末尾添加一条记录，超过容量时淘汰最旧的记录（调用方持有锁）
+func (c *DedupCache) insert(key dedupKey, now time.Time, pending bool) {
+	c.entries[key] = c.order.PushBack(&dedupEntry{key: key, seen: now, pending: pending})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.removeOldest()
+	}
+}
+
+// evictExpired 淘汰超出时间窗口的记录（调用方持有锁）
+func (c *DedupCache) evictExpired(now time.Time) {
+	for c.order.Len() > 0 {
+		entry := c.order.Front().Value.(*dedupEntry)
+		if now.Sub(entry.seen) <= c.window {
+			return
+		}
+		c.removeOldest()
+	}
+}
+
+// removeOldest 删除最旧的记录（调用方持有锁）
+func (c *DedupCache) removeOldest() {
+	elem := c.order.Front()
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*dedupEntry).key)
+}
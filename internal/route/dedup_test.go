@@ -0,0 +1,145 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+func TestDedupCacheContainsDoesNotMark(t *testing.T) {
+	c := NewDedupCache(time.Minute, 10)
+	if c.Contains("a", "p1") {
+		t.Fatal("empty cache reports packet as seen")
+	}
+	if c.Contains("a", "p1") {
+		t.Fatal("Contains must not record the packet")
+	}
+	c.Mark("a", "p1")
+	if !c.Contains("a", "p1") {
+		t.Fatal("marked packet not reported as seen")
+	}
+	if c.Contains("b", "p1") {
+		t.Fatal("packets are keyed by source and packet ID")
+	}
+}
+
+// 预留的记录在确认前同样视为重复，释放后可以重新预留，已确认的记录不会被释放
+func TestDedupCacheReserve(t *testing.T) {
+	c := NewDedupCache(time.Minute, 10)
+	if !c.Reserve("a", "p1") {
+		t.Fatal("first reserve failed")
+	}
+	if c.Reserve("a", "p1") {
+		t.Fatal("in-flight packet reserved twice")
+	}
+	c.Release("a", "p1")
+	if !c.Reserve("a", "p1") {
+		t.Fatal("released packet could not be reserved again")
+	}
+	c.Confirm("a", "p1")
+	c.Release("a", "p1")
+	if c.Reserve("a", "p1") || !c.Contains("a", "p1") {
+		t.Fatal("confirmed packet was released")
+	}
+}
+
+// 投递失败时不记录去重，发送方的重试仍能投递；成功投递后再到达的副本被过滤
+func TestFailedDeliveryIsNotMarkedDuplicate(t *testing.T) {
+	topology := NewTopology()
+	fm := NewForwardManager("n1", topology, NewRouteManager("n1", topology))
+	fm.SetDedupCache(NewDedupCache(time.Minute, 100))
+
+	failures := 1
+	fm.SetInterceptors([]Interceptor{InterceptorFunc(func(ctx context.Context, point InterceptPoint, packet *pb.Packet) error {
+		if point == InterceptDeliver && failures > 0 {
+			failures--
+			return errors.New("transient failure")
+		}
+		return nil
+	})})
+
+	delivered := 0
+	fm.SetDeliveryHandler(func(*pb.Packet) { delivered++ })
+
+	send := func() *pb.ForwardResponse {
+		resp, err := fm.HandleIncomingPacket(context.Background(), &pb.Packet{
+			Source:       "n0",
+			Destination:  "n1",
+			PacketId:     "pkt-1",
+			Payload:      []byte("hello"),
+			VisitedNodes: []string{"n0"},
+		})
+		if err != nil {
+			t.Fatalf("HandleIncomingPacket: %v", err)
+		}
+		return resp
+	}
+
+	if resp := send(); resp.Success {
+		t.Fatal("first delivery should fail")
+	}
+	if resp := send(); !resp.Success || delivered != 1 {
+		t.Fatalf("retry after failed delivery: success=%v delivered=%d, want true/1", resp.Success, delivered)
+	}
+	if resp := send(); !resp.Success || delivered != 1 {
+		t.Fatalf("duplicate after successful delivery: success=%v delivered=%d, want true/1", resp.Success, delivered)
+	}
+}
+
+// 原包仍在投递时并发到达的相同 (source, packet_id) 只投递一次
+func TestConcurrentDuplicatesDeliveredOnce(t *testing.T) {
+	topology := NewTopology()
+	fm := NewForwardManager("n1", topology, NewRouteManager("n1", topology))
+	fm.SetDedupCache(NewDedupCache(time.Minute, 100))
+
+	var delivered atomic.Int32
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	fm.SetDeliveryHandler(func(*pb.Packet) {
+		if delivered.Add(1) == 1 {
+			close(entered)
+		}
+		<-unblock
+	})
+
+	send := func() {
+		if _, err := fm.HandleIncomingPacket(context.Background(), &pb.Packet{
+			Source:       "n0",
+			Destination:  "n1",
+			PacketId:     "pkt-1",
+			Payload:      []byte("hello"),
+			VisitedNodes: []string{"n0"},
+		}); err != nil {
+			t.Errorf("HandleIncomingPacket: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		send()
+	}()
+	<-entered
+
+	// 原包还停在投递回调中时发送重试
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send()
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	if n := delivered.Load(); n != 1 {
+		t.Fatalf("delivered %d times, want 1", n)
+	}
+}
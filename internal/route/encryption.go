@@ -0,0 +1,274 @@
+package route
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultKeyDir 默认的密钥目录，节点私钥保存在 {key_dir}/{node_id}.key
+const DefaultKeyDir = "data/keys"
+
+// e2eInfo HKDF 派生会话密钥时使用的上下文信息
+const e2eInfo = "spfnet e2e v1"
+
+// EncryptionOverhead 加密后载荷增加的最大字节数（nonce、认证标签和 SealedPayload 编码）
+const EncryptionOverhead = 64
+
+// ErrNoPublicKey 目的节点或源节点没有发布公钥
+var ErrNoPublicKey = errors.New("no public key")
+
+// LoadOrCreateKey 读取节点私钥文件，文件不存在时生成新的 X25519 私钥并写入
+func LoadOrCreateKey(path string) (*ecdh.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+		}
+		key, err := ecdh.X25519().NewPrivateKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key.Bytes()) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// e2eSession 与某个节点之间的会话密钥（由双方的静态密钥派生，公钥变化时重新派生）
+type e2eSession struct {
+	peerKey []byte
+	aead    cipher.AEAD
+}
+
+// e2eCrypto 端到端加密状态
+type e2eCrypto struct {
+	key     *ecdh.PrivateKey
+	require bool // 拒绝发送和接收未加密的单播数据包
+
+	mu       sync.Mutex
+	sessions map[string]*e2eSession
+}
+
+// SetEncryption 设置本节点的端到端加密私钥，key 为 nil 时关闭加密
+// 对端公钥取自 Serf 标签，标签本身没有认证：能加入 Serf 集群或伪造成员消息的一方可以冒充节点公钥，
+// 加密因此只防范被动读取数据的中间节点，不防范控制集群成员信息的主动攻击者
+// require 为 true 时目的节点没有公钥则拒绝发送，收到未加密的单播数据包时丢弃
+func (fm *ForwardManager) SetEncryption(key *ecdh.PrivateKey, require bool) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	if key == nil {
+		fm.e2e = nil
+		return
+	}
+	fm.e2e = &e2eCrypto{
+		key:      key,
+		require:  require,
+		sessions: make(map[string]*e2eSession),
+	}
+}
+
+// getEncryption 返回当前的加密状态，未启用时返回 nil
+func (fm *ForwardManager) getEncryption() *e2eCrypto {
+	fm.policyMtx.RLock()
+	defer fm.policyMtx.RUnlock()
+	return fm.e2e
+}
+
+// session 返回与指定节点的会话密钥，对方没有发布公钥时返回 ErrNoPublicKey
+func (c *e2eCrypto) session(peer string, peerKey []byte) (cipher.AEAD, error) {
+	if len(peerKey) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoPublicKey, peer)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.sessions[peer]; ok && bytes.Equal(s.peerKey, peerKey) {
+		return s.aead, nil
+	}
+
+	pub, err := ecdh.X25519().NewPublicKey(peerKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key for %s: %w", peer, err)
+	}
+	shared, err := c.key.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	// 双方按字节序拼接两个公钥作为上下文，派生出相同的会话密钥
+	own := c.key.PublicKey().Bytes()
+	info := []byte(e2eInfo)
+	if bytes.Compare(own, peerKey) < 0 {
+		info = append(append(info, own...), peerKey...)
+	} else {
+		info = append(append(info, peerKey...), own...)
+	}
+	sessionKey, err := hkdf.Key(sha256.New, shared, nil, string(info), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	c.sessions[peer] = &e2eSession{peerKey: append([]byte(nil), peerKey...), aead: aead}
+	return aead, nil
+}
+
+// encrypt 用与目的节点的会话密钥加密数据包载荷和 headers（控制报文、多播和追踪包不加密）
+// 目的节点没有公钥时按原样发送，除非要求加密
+func (fm *ForwardManager) encrypt(packet *pb.Packet) error {
+	c := fm.getEncryption()
+	if c == nil || packet.Encrypted || packet.Trace ||
+		packet.Type == pb.PacketType_PACKET_TYPE_CONTROL || packet.Type == pb.PacketType_PACKET_TYPE_MULTICAST {
+		return nil
+	}
+
+	aead, err := c.session(packet.Destination, fm.topology.GetNodePublicKey(packet.Destination))
+	if errors.Is(err, ErrNoPublicKey) && !c.require {
+		log.Printf("[%s] Packet %s sent unencrypted: %v", fm.nodeID, packet.PacketId, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := proto.Marshal(&pb.SealedPayload{Payload: packet.Payload, Headers: packet.Headers})
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	ad, err := e2eAdditionalData(packet)
+	if err != nil {
+		return err
+	}
+	packet.Payload = aead.Seal(nonce, nonce, plaintext, ad)
+	packet.Headers = nil
+	packet.Encrypted = true
+	return nil
+}
+
+// decrypt 解密发往本节点的数据包，成功后清除加密标记
+// 要求加密时拒绝未加密的单播数据包
+func (fm *ForwardManager) decrypt(packet *pb.Packet) error {
+	c := fm.getEncryption()
+	if !packet.Encrypted {
+		if c != nil && c.require {
+			return errors.New("unencrypted packet rejected")
+		}
+		return nil
+	}
+	if c == nil {
+		return errors.New("encryption is not enabled on this node")
+	}
+
+	aead, err := c.session(packet.Source, fm.topology.GetNodePublicKey(packet.Source))
+	if err != nil {
+		return err
+	}
+	if len(packet.Payload) < aead.NonceSize() {
+		return errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := packet.Payload[:aead.NonceSize()], packet.Payload[aead.NonceSize():]
+	ad, err := e2eAdditionalData(packet)
+	if err != nil {
+		return err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return err
+	}
+
+	var sealed pb.SealedPayload
+	if err := proto.Unmarshal(plaintext, &sealed); err != nil {
+		return err
+	}
+	packet.Payload = sealed.Payload
+	packet.Headers = sealed.Headers
+	packet.Encrypted = false
+	return nil
+}
+
+// e2eAdditionalData 返回加密时认证的明文字段：中间节点可以读取但不能篡改
+// 包括字节流分段头（按确定性编码），转发过程中会变化的字段（next_hop、ttl、visited_nodes 等）不包含在内
+func e2eAdditionalData(packet *pb.Packet) ([]byte, error) {
+	stream, err := proto.MarshalOptions{Deterministic: true}.Marshal(packet.Stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stream header: %w", err)
+	}
+
+	var buf []byte
+	for _, field := range []string{
+		packet.Source,
+		packet.Destination,
+		packet.PacketId,
+		packet.Service,
+		packet.Group,
+		packet.Topic,
+		packet.FlowId,
+		packet.RequestId,
+		packet.ReplyTo,
+		packet.DatagramTarget,
+		string(stream),
+	} {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = binary.AppendUvarint(buf, uint64(packet.Type))
+	buf = binary.AppendUvarint(buf, uint64(packet.Compression))
+	buf = binary.AppendUvarint(buf, packet.Sequence)
+	buf = binary.AppendUvarint(buf, packet.FlowEpoch)
+	return buf, nil
+}
+
+// seal 在源节点加密数据包，失败时记录丢包
+func (fm *ForwardManager) seal(packet *pb.Packet) error {
+	if err := fm.encrypt(packet); err != nil {
+		log.Printf("[%s] ✗ Packet %s could not be encrypted: %v", fm.nodeID, packet.PacketId, err)
+		fm.recordDrop(packet, "", DropReasonEncryptFailed)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package route
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// newEncryptedPair 创建共享拓扑、互相知道公钥的两个启用加密的转发管理器
+func newEncryptedPair(t *testing.T) (a, b *ForwardManager) {
+	t.Helper()
+	topology := NewTopology()
+	managers := make([]*ForwardManager, 2)
+	for i, id := range []string{"a", "b"} {
+		key, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		topology.AddNode(&NodeInfo{ID: id, PublicKey: key.PublicKey().Bytes()})
+		managers[i] = NewForwardManager(id, topology, NewRouteManager(id, topology))
+		managers[i].SetEncryption(key, true)
+	}
+	return managers[0], managers[1]
+}
+
+// 中间节点篡改字节流分段头、主题或多播组后，目的节点解密失败
+func TestDecryptRejectsTamperedHeaders(t *testing.T) {
+	a, b := newEncryptedPair(t)
+
+	tamper := map[string]func(*pb.Packet){
+		"none":            func(*pb.Packet) {},
+		"stream dst port": func(p *pb.Packet) { p.Stream.DstPort = 23 },
+		"stream seq":      func(p *pb.Packet) { p.Stream.Seq += 100 },
+		"stream window":   func(p *pb.Packet) { p.Stream.Window = 0 },
+		"topic":           func(p *pb.Packet) { p.Topic = "other" },
+		"group":           func(p *pb.Packet) { p.Group = "other" },
+		"flow epoch":      func(p *pb.Packet) { p.FlowEpoch++ },
+	}
+	for name, fn := range tamper {
+		packet := &pb.Packet{
+			Source:      "a",
+			Destination: "b",
+			PacketId:    "p1",
+			Type:        pb.PacketType_PACKET_TYPE_STREAM,
+			Payload:     []byte("segment data"),
+			Stream:      &pb.StreamSegment{SrcPort: 50000, DstPort: 22, ConnId: 7, Seq: 1, Window: 65536},
+		}
+		if err := a.encrypt(packet); err != nil || !packet.Encrypted {
+			t.Fatalf("encrypt: encrypted=%v err=%v", packet.Encrypted, err)
+		}
+
+		received := proto.Clone(packet).(*pb.Packet)
+		fn(received)
+		err := b.decrypt(received)
+		if name == "none" {
+			if err != nil || string(received.Payload) != "segment data" {
+				t.Fatalf("untampered packet: payload=%q err=%v", received.Payload, err)
+			}
+		} else if err == nil {
+			t.Fatalf("%s: tampered packet decrypted", name)
+		}
+	}
+}
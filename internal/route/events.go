@@ -0,0 +1,201 @@
+package route
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// defaultEventBuffer 每个订阅者的默认事件缓冲区大小
+const defaultEventBuffer = 256
+
+// Event 成员、链路或路由变化事件
+type Event struct {
+	Type     pb.EventType
+	Time     time.Time
+	Reporter string // 产生事件的节点 ID
+
+	Node    string // 成员事件：节点 ID；路由事件：目标节点 ID
+	Address string // 成员事件：节点 gRPC 地址
+
+	From   string // 链路事件：链路两端节点 ID
+	To     string
+	Pinned bool // 链路事件：成本是否为手动固定
+
+	OldCost float64 // 链路或路由事件：变化前后的成本
+	Cost    float64
+
+	OldNextHop string // 路由事件：变化前后的下一跳
+	NextHop    string
+	OldPath    []string // 路由事件：变化前后的完整路径
+	Path       []string
+}
+
+// toProto 转换为 protobuf 消息
+func (e *Event) toProto() *pb.Event {
+	return &pb.Event{
+		Type:       e.Type,
+		UnixNano:   e.Time.UnixNano(),
+		Reporter:   e.Reporter,
+		Node:       e.Node,
+		Address:    e.Address,
+		From:       e.From,
+		To:         e.To,
+		Pinned:     e.Pinned,
+		OldCost:    e.OldCost,
+		Cost:       e.Cost,
+		OldNextHop: e.OldNextHop,
+		NextHop:    e.NextHop,
+		OldPath:    e.OldPath,
+		Path:       e.Path,
+	}
+}
+
+// EventBus 事件总线，将事件分发给多个订阅者
+// 发布不会阻塞：订阅者缓冲区已满时丢弃该事件
+type EventBus struct {
+	nodeID string
+
+	mtx    sync.RWMutex
+	nextID uint64
+	subs   map[uint64]*eventSubscription
+}
+
+// eventSubscription 单个订阅者
+type eventSubscription struct {
+	ch      chan *Event
+	types   map[pb.EventType]bool // 为空表示订阅全部类型
+	dropped atomic.Int64
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus(nodeID string) *EventBus {
+	return &EventBus{
+		nodeID: nodeID,
+		subs:   make(map[uint64]*eventSubscription),
+	}
+}
+
+// Subscribe 订阅指定类型的事件（types 为空表示全部）
+// 返回事件通道和取消订阅函数，取消后通道被关闭
+func (b *EventBus) Subscribe(buffer int, types ...pb.EventType) (<-chan *Event, func()) {
+	if buffer <= 0 {
+		buffer = defaultEventBuffer
+	}
+
+	sub := &eventSubscription{
+		ch:    make(chan *Event, buffer),
+		types: make(map[pb.EventType]bool, len(types)),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mtx.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs[id] = sub
+	b.mtx.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mtx.Lock()
+			delete(b.subs, id)
+			b.mtx.Unlock()
+			close(sub.ch)
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Publish 发布事件
+func (b *EventBus) Publish(event *Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Reporter == "" {
+		event.Reporter = b.nodeID
+	}
+
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	for _, sub := range b.subs {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				log.Printf("[%s] Event subscriber is too slow, %d events dropped", b.nodeID, dropped)
+			}
+		}
+	}
+}
+
+// diffRoutes 比较新旧路由表，返回路由增加、变化和删除事件
+func diffRoutes(oldRoutes, newRoutes map[string]*Route) []*Event {
+	var events []*Event
+
+	for dest, route := range newRoutes {
+		old, existed := oldRoutes[dest]
+		if !existed {
+			events = append(events, &Event{
+				Type:    pb.EventType_EVENT_TYPE_ROUTE_ADD,
+				Node:    dest,
+				Cost:    route.Cost,
+				NextHop: route.NextHop,
+				Path:    route.Path,
+			})
+			continue
+		}
+
+		if old.NextHop != route.NextHop || old.Cost != route.Cost || !samePath(old.Path, route.Path) {
+			events = append(events, &Event{
+				Type:       pb.EventType_EVENT_TYPE_ROUTE_CHANGE,
+				Node:       dest,
+				OldCost:    old.Cost,
+				Cost:       route.Cost,
+				OldNextHop: old.NextHop,
+				NextHop:    route.NextHop,
+				OldPath:    old.Path,
+				Path:       route.Path,
+			})
+		}
+	}
+
+	for dest, old := range oldRoutes {
+		if _, exists := newRoutes[dest]; !exists {
+			events = append(events, &Event{
+				Type:       pb.EventType_EVENT_TYPE_ROUTE_REMOVE,
+				Node:       dest,
+				OldCost:    old.Cost,
+				OldNextHop: old.NextHop,
+				OldPath:    old.Path,
+			})
+		}
+	}
+
+	return events
+}
+
+// samePath 判断两条路径是否相同
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
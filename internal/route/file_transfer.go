@@ -0,0 +1,557 @@
+package route
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileTransferPort 目标节点接收文件的覆盖网络端口
+const FileTransferPort = 2
+
+// DefaultFileMaxAttempts 发送文件时默认的连接尝试次数（连接中断后自动续传）
+const DefaultFileMaxAttempts = 5
+
+// 文件传输参数
+const (
+	fileChunkSize        = 64 << 10
+	fileFrameHeaderSize  = 16 // 偏移(8) + 长度(4) + CRC32(4)
+	fileHeaderLimit      = 4096
+	fileHandshakeTimeout = 10 * time.Second
+	fileIdleTimeout      = 30 * time.Second // 传输过程中单次读写的超时
+	fileVerifyTimeout    = 2 * time.Minute  // 等待目标节点校验整个文件的超时
+	fileRetryInterval    = time.Second
+	filePartialDir       = ".partial"
+	fileReplyOffset      = "OFFSET "
+	fileReplyOK          = "OK"
+	fileReplyFailure     = "ERR "
+	fileMaxNameSuffix    = 1000 // 收件目录中同名文件的最大编号
+)
+
+// ErrFileRejected 目标节点拒绝接收文件（不会重试）
+var ErrFileRejected = errors.New("file rejected")
+
+// FileTransferResult 文件发送结果
+type FileTransferResult struct {
+	Name        string        // 目标节点收件目录中的文件名（同名文件已存在时为目标节点选择的新文件名）
+	Size        int64         // 文件大小（字节）
+	SHA256      string        // 文件的 SHA-256 校验和（十六进制）
+	ResumedFrom int64         // 目标节点此前已收到的字节数
+	BytesSent   int64         // 本次实际发送的字节数（含重试）
+	Attempts    int           // 连接尝试次数
+	Duration    time.Duration // 传输耗时
+}
+
+// fileHeader 发送方在连接建立后发送的文件描述（一行 JSON）
+type fileHeader struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// FileTransferManager 经覆盖网络发送文件，并作为目标节点把收到的文件写入收件目录
+//
+// 文件传输是 FileTransferPort 上的覆盖网络字节流：发送方先发送一行文件描述，
+// 目标节点回复已收到的字节数 "OFFSET n"（或 "ERR 原因"），发送方从该偏移处开始按块发送，
+// 每块带偏移和 CRC32；全部收到后目标节点校验整个文件的 SHA-256，通过后移入收件目录并回复 "OK 文件名"。
+// 收件目录中已有同名文件时不覆盖，改用 "name (1).ext" 这样的新文件名。
+// 未完成的文件保存在收件目录的 .partial 子目录中，同一文件（按 SHA-256 区分）再次发送时从断点续传
+type FileTransferManager struct {
+	nodeID  string
+	streams *StreamManager
+
+	mtx         sync.Mutex
+	inboxDir    string // 为空时拒绝接收文件
+	sendDir     string // 控制服务可以发送的文件所在目录，为空时禁止通过控制服务发送文件
+	maxFileSize int64  // 0 表示不限制
+	maxAttempts int
+	receiving   map[string]struct{} // 正在接收的未完成文件路径
+	listener    *StreamListener
+}
+
+// NewFileTransferManager 创建文件传输管理器
+func NewFileTransferManager(nodeID string, streams *StreamManager) *FileTransferManager {
+	return &FileTransferManager{
+		nodeID:      nodeID,
+		streams:     streams,
+		maxAttempts: DefaultFileMaxAttempts,
+		receiving:   make(map[string]struct{}),
+	}
+}
+
+// SetInboxDir 设置接收文件的收件目录，为空时拒绝接收文件
+func (m *FileTransferManager) SetInboxDir(dir string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.inboxDir = dir
+}
+
+// SetSendDir 设置控制服务可以发送的文件所在目录，为空时禁止通过控制服务发送文件
+func (m *FileTransferManager) SetSendDir(dir string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.sendDir = dir
+}
+
+// ResolveSendPath 将控制服务请求中的文件路径解析为发送目录中的文件
+// 相对路径相对于发送目录；清理并解析符号链接后位于发送目录之外的路径被拒绝
+func (m *FileTransferManager) ResolveSendPath(path string) (string, error) {
+	m.mtx.Lock()
+	dir := m.sendDir
+	m.mtx.Unlock()
+
+	if dir == "" {
+		return "", errors.New("sending files via the control service is disabled (file_transfer.send_dir is not set)")
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid send directory: %w", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", fmt.Errorf("invalid send directory: %w", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the send directory", path)
+	}
+	return resolved, nil
+}
+
+// SetMaxFileSize 设置允许接收的最大文件字节数，0 表示不限制
+func (m *FileTransferManager) SetMaxFileSize(size int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.maxFileSize = size
+}
+
+// SetMaxAttempts 设置发送文件时的连接尝试次数
+func (m *FileTransferManager) SetMaxAttempts(attempts int) {
+	if attempts <= 0 {
+		attempts = DefaultFileMaxAttempts
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.maxAttempts = attempts
+}
+
+// Start 开始接收其他节点发来的文件
+func (m *FileTransferManager) Start() error {
+	lis, err := m.streams.Listen(FileTransferPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen for file transfers: %w", err)
+	}
+
+	m.mtx.Lock()
+	m.listener = lis
+	m.mtx.Unlock()
+
+	go m.serve(lis)
+	return nil
+}
+
+// Close 停止接收文件
+func (m *FileTransferManager) Close() {
+	m.mtx.Lock()
+	lis := m.listener
+	m.listener = nil
+	m.mtx.Unlock()
+
+	if lis != nil {
+		lis.Close()
+	}
+}
+
+// validFileName 检查收件目录中的文件名（不允许路径分隔符和以 "." 开头的名字）
+func validFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") &&
+		!strings.ContainsAny(name, "/\\\n\x00") && len(name) <= 255
+}
+
+// SendFile 将本地文件发送到目标节点的收件目录，name 为空时使用源文件名
+// 连接中断时自动重连并从目标节点已收到的位置续传，目标节点拒绝时返回 ErrFileRejected
+func (m *FileTransferManager) SendFile(ctx context.Context, destination, path, name string) (*FileTransferResult, error) {
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	if !validFileName(name) {
+		return nil, fmt.Errorf("invalid file name %q", name)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+
+	header := fileHeader{Name: name, Size: info.Size(), SHA256: hex.EncodeToString(hasher.Sum(nil))}
+	result := &FileTransferResult{Name: name, Size: header.Size, SHA256: header.SHA256, ResumedFrom: -1}
+
+	m.mtx.Lock()
+	maxAttempts := m.maxAttempts
+	m.mtx.Unlock()
+
+	start := time.Now()
+	log.Printf("[%s] Sending file %s (%d bytes, sha256 %s) to %s",
+		m.nodeID, name, header.Size, header.SHA256[:12], destination)
+
+	for {
+		result.Attempts++
+		offset, sent, received, err := m.sendOnce(ctx, destination, f, header)
+		result.BytesSent += sent
+		if result.ResumedFrom < 0 && offset >= 0 {
+			result.ResumedFrom = offset
+		}
+		if err == nil {
+			result.Name = received
+			break
+		}
+		if ctx.Err() != nil {
+			// 取消时连接被关闭，返回取消原因而不是连接错误
+			err = ctx.Err()
+		}
+
+		if errors.Is(err, ErrFileRejected) || ctx.Err() != nil || result.Attempts >= maxAttempts {
+			log.Printf("[%s] ✗ File %s to %s failed after %d attempts: %v",
+				m.nodeID, name, destination, result.Attempts, err)
+			return result, err
+		}
+		log.Printf("[%s] File %s to %s interrupted (attempt %d/%d): %v, resuming",
+			m.nodeID, name, destination, result.Attempts, maxAttempts, err)
+
+		select {
+		case <-time.After(fileRetryInterval):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+
+	if result.ResumedFrom < 0 {
+		result.ResumedFrom = 0
+	}
+	result.Duration = time.Since(start)
+	log.Printf("[%s] ✓ File %s delivered to %s as %s (%d bytes sent, resumed from %d, %d attempts, %v)",
+		m.nodeID, name, destination, result.Name, result.BytesSent, result.ResumedFrom, result.Attempts, result.Duration)
+	return result, nil
+}
+
+// sendOnce 建立一次连接并从目标节点已收到的位置发送文件
+// 返回目标节点报告的续传偏移（握手前失败时为 -1）、本次发送的字节数和目标节点收件目录中的文件名
+func (m *FileTransferManager) sendOnce(ctx context.Context, destination string, f *os.File, header fileHeader) (int64, int64, string, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, fileHandshakeTimeout)
+	conn, err := m.streams.Dial(dialCtx, destination, FileTransferPort)
+	cancel()
+	if err != nil {
+		return -1, 0, "", err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// 握手：发送文件描述，读取续传偏移
+	line, err := json.Marshal(header)
+	if err != nil {
+		return -1, 0, "", err
+	}
+	conn.SetDeadline(time.Now().Add(fileHandshakeTimeout))
+	if _, err := conn.Write(append(line, '\n')); err != nil {
+		return -1, 0, "", err
+	}
+	r := bufio.NewReader(conn)
+	reply, err := readFileLine(r)
+	if err != nil {
+		return -1, 0, "", fmt.Errorf("file handshake failed: %w", err)
+	}
+	if msg, ok := strings.CutPrefix(reply, fileReplyFailure); ok {
+		return -1, 0, "", fmt.Errorf("%w: %s", ErrFileRejected, msg)
+	}
+	offset, err := strconv.ParseInt(strings.TrimPrefix(reply, fileReplyOffset), 10, 64)
+	if err != nil || !strings.HasPrefix(reply, fileReplyOffset) || offset < 0 || offset > header.Size {
+		return -1, 0, "", fmt.Errorf("unexpected handshake reply %q", reply)
+	}
+
+	// 按块发送剩余数据
+	var sent int64
+	frame := make([]byte, fileFrameHeaderSize+fileChunkSize)
+	for pos := offset; pos < header.Size; {
+		n, err := f.ReadAt(frame[fileFrameHeaderSize:], pos)
+		if n == 0 {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = fmt.Errorf("file shrank to %d bytes during transfer", pos)
+			}
+			return offset, sent, "", err
+		}
+		if remaining := header.Size - pos; int64(n) > remaining {
+			n = int(remaining)
+		}
+		data := frame[fileFrameHeaderSize : fileFrameHeaderSize+n]
+		binary.BigEndian.PutUint64(frame[0:8], uint64(pos))
+		binary.BigEndian.PutUint32(frame[8:12], uint32(n))
+		binary.BigEndian.PutUint32(frame[12:16], crc32.ChecksumIEEE(data))
+
+		conn.SetDeadline(time.Now().Add(fileIdleTimeout))
+		if _, err := conn.Write(frame[:fileFrameHeaderSize+n]); err != nil {
+			return offset, sent, "", err
+		}
+		pos += int64(n)
+		sent += int64(n)
+	}
+	conn.CloseWrite()
+
+	// 等待目标节点校验整个文件
+	conn.SetDeadline(time.Now().Add(fileVerifyTimeout))
+	reply, err = readFileLine(r)
+	if err != nil {
+		return offset, sent, "", fmt.Errorf("no confirmation from %s: %w", destination, err)
+	}
+	if reply == fileReplyOK {
+		return offset, sent, header.Name, nil
+	}
+	if name, ok := strings.CutPrefix(reply, fileReplyOK+" "); ok && validFileName(name) {
+		return offset, sent, name, nil
+	}
+	return offset, sent, "", fmt.Errorf("%w: %s", ErrFileRejected, strings.TrimPrefix(reply, fileReplyFailure))
+}
+
+// readFileLine 读取一行握手消息
+func readFileLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) || len(line) > fileHeaderLimit {
+		return "", fmt.Errorf("file transfer header exceeds %d bytes", fileHeaderLimit)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(line), "\n"), nil
+}
+
+// serve 接受其他节点的文件传输连接
+func (m *FileTransferManager) serve(lis *StreamListener) {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		go m.handleConn(conn)
+	}
+}
+
+// rejectFile 回复拒绝原因
+func (m *FileTransferManager) rejectFile(conn net.Conn, name, reason string) {
+	log.Printf("[%s] ✗ File %s from %s rejected: %s", m.nodeID, name, conn.RemoteAddr(), reason)
+	io.WriteString(conn, fileReplyFailure+reason+"\n")
+}
+
+// handleConn 接收一个文件并写入收件目录
+func (m *FileTransferManager) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(fileHandshakeTimeout))
+	r := bufio.NewReaderSize(conn, fileHeaderLimit)
+	line, err := readFileLine(r)
+	if err != nil {
+		log.Printf("[%s] ✗ File handshake from %s failed: %v", m.nodeID, conn.RemoteAddr(), err)
+		return
+	}
+	var header fileHeader
+	if err := json.Unmarshal([]byte(line), &header); err != nil {
+		m.rejectFile(conn, "", "malformed header")
+		return
+	}
+	if sum, err := hex.DecodeString(header.SHA256); err != nil || len(sum) != sha256.Size ||
+		header.Size < 0 || !validFileName(header.Name) {
+		m.rejectFile(conn, header.Name, "invalid file description")
+		return
+	}
+
+	m.mtx.Lock()
+	inbox, maxSize := m.inboxDir, m.maxFileSize
+	m.mtx.Unlock()
+	if inbox == "" {
+		m.rejectFile(conn, header.Name, "file transfer disabled")
+		return
+	}
+	if maxSize > 0 && header.Size > maxSize {
+		m.rejectFile(conn, header.Name, fmt.Sprintf("file exceeds %d bytes", maxSize))
+		return
+	}
+
+	// 同一文件同时只允许一个连接写入
+	partial := filepath.Join(inbox, filePartialDir, header.SHA256[:16]+"-"+header.Name)
+	m.mtx.Lock()
+	_, busy := m.receiving[partial]
+	if !busy {
+		m.receiving[partial] = struct{}{}
+	}
+	m.mtx.Unlock()
+	if busy {
+		m.rejectFile(conn, header.Name, "transfer already in progress")
+		return
+	}
+	defer func() {
+		m.mtx.Lock()
+		delete(m.receiving, partial)
+		m.mtx.Unlock()
+	}()
+
+	if err := m.receiveFile(conn, r, header, inbox, partial); err != nil {
+		log.Printf("[%s] ✗ File %s from %s failed: %v", m.nodeID, header.Name, conn.RemoteAddr(), err)
+	}
+}
+
+// receiveFile 从断点处接收文件数据，校验后移入收件目录
+func (m *FileTransferManager) receiveFile(conn net.Conn, r *bufio.Reader, header fileHeader, inbox, partial string) error {
+	if err := os.MkdirAll(filepath.Dir(partial), 0755); err != nil {
+		m.rejectFile(conn, header.Name, "inbox unavailable")
+		return err
+	}
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		m.rejectFile(conn, header.Name, "inbox unavailable")
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		m.rejectFile(conn, header.Name, "inbox unavailable")
+		return err
+	}
+	offset := info.Size()
+	if offset > header.Size {
+		if err := f.Truncate(0); err != nil {
+			m.rejectFile(conn, header.Name, "inbox unavailable")
+			return err
+		}
+		offset = 0
+	}
+
+	if _, err := fmt.Fprintf(conn, "%s%d\n", fileReplyOffset, offset); err != nil {
+		return err
+	}
+	if offset > 0 {
+		log.Printf("[%s] Resuming file %s from %s at %d/%d bytes",
+			m.nodeID, header.Name, conn.RemoteAddr(), offset, header.Size)
+	}
+
+	frameHeader := make([]byte, fileFrameHeaderSize)
+	data := make([]byte, fileChunkSize)
+	for offset < header.Size {
+		conn.SetDeadline(time.Now().Add(fileIdleTimeout))
+		if _, err := io.ReadFull(r, frameHeader); err != nil {
+			return fmt.Errorf("interrupted at %d/%d bytes: %w", offset, header.Size, err)
+		}
+		pos := int64(binary.BigEndian.Uint64(frameHeader[0:8]))
+		n := int64(binary.BigEndian.Uint32(frameHeader[8:12]))
+		if pos != offset || n == 0 || n > fileChunkSize || pos+n > header.Size {
+			m.rejectFile(conn, header.Name, "unexpected chunk")
+			return fmt.Errorf("unexpected chunk at %d (%d bytes), expected %d", pos, n, offset)
+		}
+		if _, err := io.ReadFull(r, data[:n]); err != nil {
+			return fmt.Errorf("interrupted at %d/%d bytes: %w", offset, header.Size, err)
+		}
+		if crc32.ChecksumIEEE(data[:n]) != binary.BigEndian.Uint32(frameHeader[12:16]) {
+			m.rejectFile(conn, header.Name, "chunk checksum mismatch")
+			return fmt.Errorf("chunk checksum mismatch at %d", pos)
+		}
+		if _, err := f.WriteAt(data[:n], pos); err != nil {
+			m.rejectFile(conn, header.Name, "write failed")
+			return err
+		}
+		offset += n
+	}
+	if err := f.Sync(); err != nil {
+		m.rejectFile(conn, header.Name, "write failed")
+		return err
+	}
+
+	// 校验整个文件
+	conn.SetDeadline(time.Now().Add(fileVerifyTimeout))
+	rf, err := os.Open(partial)
+	if err != nil {
+		m.rejectFile(conn, header.Name, "inbox unavailable")
+		return err
+	}
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, rf)
+	rf.Close()
+	if err != nil {
+		m.rejectFile(conn, header.Name, "read failed")
+		return err
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != header.SHA256 {
+		os.Remove(partial)
+		m.rejectFile(conn, header.Name, "checksum mismatch")
+		return fmt.Errorf("sha256 %s does not match %s", sum, header.SHA256)
+	}
+
+	name, err := moveToInbox(partial, inbox, header.Name)
+	if err != nil {
+		m.rejectFile(conn, header.Name, "inbox unavailable")
+		return err
+	}
+	log.Printf("[%s] ✓ File %s received from %s (%d bytes) -> %s",
+		m.nodeID, header.Name, conn.RemoteAddr(), header.Size, filepath.Join(inbox, name))
+	_, err = io.WriteString(conn, fileReplyOK+" "+name+"\n")
+	return err
+}
+
+// moveToInbox 将校验通过的文件移入收件目录，返回最终的文件名
+// 不覆盖已有文件：同名文件存在时依次尝试 "name (1).ext"、"name (2).ext"……
+func moveToInbox(partial, inbox, name string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; i < fileMaxNameSuffix; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		// 硬链接在目标已存在时失败，检查和创建是原子的
+		err := os.Link(partial, filepath.Join(inbox, candidate))
+		if err == nil {
+			os.Remove(partial)
+			return candidate, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("too many files named %s in %s", name, inbox)
+}
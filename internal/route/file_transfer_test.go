@@ -0,0 +1,79 @@
+package route
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 控制服务只能发送发送目录中的文件，未配置发送目录时禁止发送
+func TestResolveSendPathStaysInSendDir(t *testing.T) {
+	base := t.TempDir()
+	sendDir := filepath.Join(base, "send")
+	if err := os.MkdirAll(filepath.Join(sendDir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{filepath.Join(sendDir, "sub", "a.txt"), filepath.Join(base, "secret.txt")} {
+		if err := os.WriteFile(name, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(base, "secret.txt"), filepath.Join(sendDir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewFileTransferManager("n1", nil)
+	if _, err := m.ResolveSendPath("sub/a.txt"); err == nil {
+		t.Fatal("sending allowed without a send directory")
+	}
+
+	m.SetSendDir(sendDir)
+	for _, path := range []string{"sub/a.txt", "sub/../sub/a.txt", filepath.Join(sendDir, "sub", "a.txt")} {
+		resolved, err := m.ResolveSendPath(path)
+		if err != nil {
+			t.Fatalf("ResolveSendPath(%q): %v", path, err)
+		}
+		if filepath.Base(resolved) != "a.txt" {
+			t.Fatalf("ResolveSendPath(%q) = %s", path, resolved)
+		}
+	}
+
+	for _, path := range []string{
+		"../secret.txt",
+		"sub/../../secret.txt",
+		filepath.Join(base, "secret.txt"),
+		"link.txt",
+		".",
+		"/etc/passwd",
+	} {
+		if resolved, err := m.ResolveSendPath(path); err == nil {
+			t.Fatalf("ResolveSendPath(%q) = %s, want error", path, resolved)
+		}
+	}
+}
+
+// 收件目录中已有同名文件时不覆盖，依次改用带编号的文件名
+func TestMoveToInboxKeepsExistingFiles(t *testing.T) {
+	inbox := t.TempDir()
+	if err := os.WriteFile(filepath.Join(inbox, "report.txt"), []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"report (1).txt", "report (2).txt"} {
+		partial := filepath.Join(inbox, "partial")
+		if err := os.WriteFile(partial, []byte{byte('a' + i)}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		name, err := moveToInbox(partial, inbox, "report.txt")
+		if err != nil || name != want {
+			t.Fatalf("moveToInbox = %q, %v; want %q", name, err, want)
+		}
+		if _, err := os.Stat(partial); !os.IsNotExist(err) {
+			t.Fatalf("partial file left behind: %v", err)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(inbox, "report.txt")); err != nil || string(data) != "first" {
+		t.Fatalf("existing file = %q, %v; want unchanged", data, err)
+	}
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -10,7 +11,7 @@ import (
 	pb "spfnet/proto"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/connectivity"
 )
 
 // ForwardManager 数据包转发管理器
@@ -25,6 +26,53 @@ type ForwardManager struct {
 
 	// 统计信息
 	stats ForwardStats
+
+	// 转发策略
+	policyMtx           sync.RWMutex
+	defaultTTL          uint32
+	maxPayloadSize      int
+	blockedDestinations map[string]bool
+
+	// 连接其他节点时使用的 TLS 配置（见 tls.go，为 nil 时使用明文连接）
+	tls *NodeTLS
+
+	// 载荷压缩（见 compression.go）
+	compression        pb.Compression
+	compressionMinSize int
+	compressionLevel   int
+
+	// 端到端加密（见 encryption.go，为 nil 时不加密）
+	e2e *e2eCrypto
+
+	// 控制报文回调（源节点收到差错报文时调用）
+	onControl func(*ControlError)
+
+	// 业务数据投递回调（本节点为目的地时调用）
+	onDeliver func(*pb.Packet)
+
+	// 字节流分段回调（本节点为目的地时调用，见 stream.go）
+	onStream func(*pb.Packet)
+
+	// UDP 中继数据报回调（本节点为目的地时调用，见 udp_relay.go）
+	onDatagram func(*pb.Packet)
+
+	// 业务数据包拦截器（见 interceptor.go）
+	interceptors []Interceptor
+
+	// 重复数据包过滤（为 nil 时不去重）
+	dedup *DedupCache
+
+	// 转发模式与后台转发队列（见 forward_queue.go）
+	mode          string
+	queueSize     int
+	maxAttempts   int
+	retryInterval time.Duration
+	queue         chan *queuedPacket
+	outbox        *Outbox
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // ForwardStats 转发统计
@@ -35,20 +83,123 @@ type ForwardStats struct {
 	PacketsForwarded int64
 	PacketsDelivered int64
 	PacketsDropped   int64
+	ControlSent      int64
+	ControlReceived  int64
+	PacketsDuplicate int64
+	BytesSent        int64
+	BytesForwarded   int64
+	BytesDelivered   int64
+
+	// 按丢包原因、邻居、目标、主题订阅者细分的统计（见 forward_stats.go）
+	dropReasons  map[string]int64
+	neighbors    map[string]*TrafficStats
+	destinations map[string]*TrafficStats
+	subscribers  map[subscriberKey]*SubscriberStats
 }
 
+// DefaultTTL 默认的数据包跳数上限
+const DefaultTTL = 64
+
 // NewForwardManager 创建转发管理器
 func NewForwardManager(nodeID string, topology *Topology, routeManager *RouteManager) *ForwardManager {
 	return &ForwardManager{
-		nodeID:       nodeID,
-		topology:     topology,
-		routeManager: routeManager,
-		connPool:     make(map[string]*grpc.ClientConn),
+		nodeID:        nodeID,
+		topology:      topology,
+		routeManager:  routeManager,
+		connPool:      make(map[string]*grpc.ClientConn),
+		defaultTTL:    DefaultTTL,
+		mode:          ForwardModeSync,
+		queueSize:     defaultQueueSize,
+		maxAttempts:   defaultMaxAttempts,
+		retryInterval: defaultRetryInterval,
 	}
 }
 
+// SetDefaultTTL 设置新数据包的默认 TTL
+func (fm *ForwardManager) SetDefaultTTL(ttl uint32) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.defaultTTL = ttl
+}
+
+// SetMaxPayloadSize 设置允许转发的最大载荷长度（0 表示不限制）
+func (fm *ForwardManager) SetMaxPayloadSize(size int) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.maxPayloadSize = size
+}
+
+// SetBlockedDestinations 设置禁止转发的目标节点
+func (fm *ForwardManager) SetBlockedDestinations(destinations []string) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+
+	fm.blockedDestinations = make(map[string]bool, len(destinations))
+	for _, dest := range destinations {
+		fm.blockedDestinations[dest] = true
+	}
+}
+
+// SetDeliveryHandler 设置业务数据包投递到本节点时的回调
+func (fm *ForwardManager) SetDeliveryHandler(handler func(*pb.Packet)) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.onDeliver = handler
+}
+
+// SetStreamHandler 设置字节流分段到达本节点时的回调
+func (fm *ForwardManager) SetStreamHandler(handler func(*pb.Packet)) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.onStream = handler
+}
+
+// SetDatagramHandler 设置 UDP 中继数据报到达本节点时的回调
+func (fm *ForwardManager) SetDatagramHandler(handler func(*pb.Packet)) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.onDatagram = handler
+}
+
+// SetTLS 设置连接其他节点时使用的 TLS 配置（已建立的连接不受影响）
+func (fm *ForwardManager) SetTLS(t *NodeTLS) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.tls = t
+}
+
+// SetDedupCache 设置目的节点的重复数据包过滤缓存
+func (fm *ForwardManager) SetDedupCache(cache *DedupCache) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.dedup = cache
+}
+
+// SetControlHandler 设置收到控制报文时的回调
+func (fm *ForwardManager) SetControlHandler(handler func(*ControlError)) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.onControl = handler
+}
+
+// SendOptions 发送数据包的可选参数
+type SendOptions struct {
+	FlowID    string            // 有序流标识，为空表示不要求有序
+	Sequence  uint64            // 有序流内的序号，从 1 开始
+	FlowEpoch uint64            // 有序流的纪元，发送端重新创建流时增大
+	Service   string            // 目标服务名（按服务名发送时使用）
+	RequestID string            // 请求 ID（发送方等待应答时使用）
+	ReplyTo   string            // 所应答的请求 ID（发送应答时使用）
+	Headers   map[string]string // 应用自定义的元数据，随数据包送达目的节点
+}
+
 // SendPacket 发送数据包到目的节点
 func (fm *ForwardManager) SendPacket(ctx context.Context, destination string, payload []byte) error {
+	return fm.SendPacketWithOptions(ctx, destination, payload, SendOptions{})
+}
+
+// SendPacketWithOptions 使用指定选项发送数据包到目的节点
+func (fm *ForwardManager) SendPacketWithOptions(ctx context.Context, destination string, payload []byte, opts SendOptions) error {
 	// 创建数据包
 	packet := &pb.Packet{
 		Source:       fm.nodeID,
@@ -56,30 +207,58 @@ func (fm *ForwardManager) SendPacket(ctx context.Context, destination string, pa
 		PacketId:     fmt.Sprintf("pkt-%s-%d", fm.nodeID, time.Now().UnixNano()),
 		Payload:      payload,
 		VisitedNodes: []string{fm.nodeID},
+		Ttl:          fm.getDefaultTTL(),
+		FlowId:       opts.FlowID,
+		Sequence:     opts.Sequence,
+		FlowEpoch:    opts.FlowEpoch,
+		Service:      opts.Service,
+		RequestId:    opts.RequestID,
+		ReplyTo:      opts.ReplyTo,
+		Headers:      opts.Headers,
 	}
 
-	log.Printf("[%s] Sending packet %s to %s (payload: %s)",
-		fm.nodeID, packet.PacketId, destination, string(payload))
+	log.Printf("[%s] Sending packet %s to %s (payload: %d bytes)",
+		fm.nodeID, packet.PacketId, destination, len(payload))
 
 	// 更新统计
-	fm.stats.mtx.Lock()
-	fm.stats.PacketsSent++
-	fm.stats.mtx.Unlock()
+	fm.recordSent(packet)
+
+	if cerr := fm.intercept(ctx, InterceptOutbound, packet); cerr != nil {
+		return cerr
+	}
+	fm.compress(packet)
+	if err := fm.seal(packet); err != nil {
+		return err
+	}
+
+	// 先落盘，节点在转发过程中重启后可重放
+	if err := fm.persist(packet); err != nil {
+		fm.recordDrop(packet, "", DropReasonPersistFailed)
+		return err
+	}
 
 	// 转发数据包
-	return fm.forwardPacket(ctx, packet)
+	err := fm.forwardPacket(ctx, packet)
+	fm.ack(packet)
+	return err
 }
 
 // forwardPacket 转发数据包到下一跳
 func (fm *ForwardManager) forwardPacket(ctx context.Context, packet *pb.Packet) error {
+	// 检查转发策略
+	if cerr := fm.checkPolicy(packet); cerr != nil {
+		log.Printf("[%s] ✗ Packet %s rejected: %v", fm.nodeID, packet.PacketId, cerr)
+		fm.recordDrop(packet, "", dropReasonForControl(cerr))
+		return cerr
+	}
+
 	// 查询路由
 	route, err := fm.routeManager.GetRoute(packet.Destination)
 	if err != nil {
 		log.Printf("[%s] ✗ No route to %s: %v", fm.nodeID, packet.Destination, err)
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDropped++
-		fm.stats.mtx.Unlock()
-		return fmt.Errorf("no route to %s: %w", packet.Destination, err)
+		fm.recordDrop(packet, "", DropReasonNoRoute)
+		return newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, packet,
+			"no route to %s: %v", packet.Destination, err)
 	}
 
 	// 设置下一跳
@@ -89,46 +268,91 @@ func (fm *ForwardManager) forwardPacket(ctx context.Context, packet *pb.Packet)
 	nextHopNode := fm.topology.GetNode(route.NextHop)
 	if nextHopNode == nil {
 		log.Printf("[%s] ✗ Next hop node %s not found", fm.nodeID, route.NextHop)
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDropped++
-		fm.stats.mtx.Unlock()
-		return fmt.Errorf("next hop node %s not found", route.NextHop)
+		fm.recordDrop(packet, route.NextHop, DropReasonNextHopUnknown)
+		return newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, packet,
+			"next hop node %s not found", route.NextHop)
 	}
 
 	// 获取 gRPC 客户端
 	client, err := fm.getClient(nextHopNode)
 	if err != nil {
 		log.Printf("[%s] ✗ Failed to get client for %s: %v", fm.nodeID, route.NextHop, err)
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDropped++
-		fm.stats.mtx.Unlock()
-		return err
+		fm.recordDrop(packet, route.NextHop, DropReasonNextHopUnreachable)
+		return newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, packet,
+			"next hop %s unreachable: %v", route.NextHop, err)
+	}
+
+	// 路径追踪：记录本节点选择的下一跳和转发时间
+	traceIdx := -1
+	if packet.Trace && len(packet.TraceHops) > 0 {
+		traceIdx = len(packet.TraceHops) - 1
+		hop := packet.TraceHops[traceIdx]
+		hop.NextHop = route.NextHop
+		hop.ForwardUnixNano = time.Now().UnixNano()
 	}
 
 	// 转发数据包
+	fm.recordAttempt(route.NextHop)
+	start := time.Now()
 	resp, err := client.ForwardPacket(ctx, packet)
+	latency := time.Since(start)
+	if err == nil && traceIdx >= 0 {
+		fm.mergeTraceHops(packet, resp, traceIdx)
+	}
 	if err != nil {
 		log.Printf("[%s] ✗ Failed to forward packet %s: %v", fm.nodeID, packet.PacketId, err)
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDropped++
-		fm.stats.mtx.Unlock()
-		return fmt.Errorf("failed to forward packet: %w", err)
+		fm.recordDrop(packet, route.NextHop, DropReasonNextHopUnreachable)
+		return newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, packet,
+			"next hop %s unreachable: %v", route.NextHop, err)
 	}
 
 	if !resp.Success {
 		log.Printf("[%s] ✗ Forward failed: %s", fm.nodeID, resp.Message)
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDropped++
-		fm.stats.mtx.Unlock()
+		fm.recordDrop(packet, route.NextHop, DropReasonDownstream)
+		// 下游节点生成的控制报文原样返回
+		if resp.Control != nil {
+			return controlErrorFromProto(resp.Control)
+		}
 		return fmt.Errorf("forward failed: %s", resp.Message)
 	}
 
 	log.Printf("[%s] ✓ Packet %s forwarded to %s (path: %v)",
 		fm.nodeID, packet.PacketId, route.NextHop, packet.VisitedNodes)
 
-	fm.stats.mtx.Lock()
-	fm.stats.PacketsForwarded++
-	fm.stats.mtx.Unlock()
+	fm.recordForwarded(packet, route.NextHop, latency)
+
+	return nil
+}
+
+// mergeTraceHops 用下游返回的追踪记录更新数据包，并填入本节点测得的下游往返时间
+func (fm *ForwardManager) mergeTraceHops(packet *pb.Packet, resp *pb.ForwardResponse, traceIdx int) {
+	if len(resp.TraceHops) <= traceIdx {
+		return
+	}
+
+	hop := resp.TraceHops[traceIdx]
+	hop.DownstreamRttNanos = time.Now().UnixNano() - packet.TraceHops[traceIdx].ForwardUnixNano
+	packet.TraceHops = resp.TraceHops
+}
+
+// checkPolicy 检查数据包是否满足本节点的转发策略
+func (fm *ForwardManager) checkPolicy(packet *pb.Packet) *ControlError {
+	fm.policyMtx.RLock()
+	maxPayloadSize := fm.maxPayloadSize
+	blocked := fm.blockedDestinations[packet.Destination]
+	fm.policyMtx.RUnlock()
+
+	if blocked {
+		return newControlError(pb.ControlCode_CONTROL_CODE_ADMIN_PROHIBITED, fm.nodeID, packet,
+			"forwarding to %s is prohibited", packet.Destination)
+	}
+
+	if maxPayloadSize > 0 && len(packet.Payload) > maxPayloadSize {
+		cerr := newControlError(pb.ControlCode_CONTROL_CODE_FRAGMENTATION_NEEDED, fm.nodeID, packet,
+			"payload size %d exceeds limit %d", len(packet.Payload), maxPayloadSize)
+		cerr.MaxPayloadSize = uint32(maxPayloadSize)
+		return cerr
+	}
 
 	return nil
 }
@@ -145,16 +369,137 @@ func (fm *ForwardManager) HandleIncomingPacket(ctx context.Context, packet *pb.P
 
 	// 记录经过的节点
 	packet.VisitedNodes = append(packet.VisitedNodes, fm.nodeID)
+	if packet.Trace {
+		packet.TraceHops = append(packet.TraceHops, &pb.TraceHop{
+			NodeId:       fm.nodeID,
+			RecvUnixNano: time.Now().UnixNano(),
+		})
+	}
+
+	// 多播数据包：投递给本节点并按分支复制转发
+	if packet.Type == pb.PacketType_PACKET_TYPE_MULTICAST {
+		return fm.handleMulticastPacket(ctx, packet), nil
+	}
 
 	// 如果是目的地，则接收
 	if packet.Destination == fm.nodeID {
-		log.Printf("[%s] ✓ Packet %s delivered! Path: %v",
-			fm.nodeID, packet.PacketId, packet.VisitedNodes)
-		log.Printf("[%s] Payload: %s", fm.nodeID, string(packet.Payload))
+		// 追踪包不向业务层投递，直接返回追踪记录
+		if packet.Trace {
+			log.Printf("[%s] ✓ Trace packet %s reached destination. Path: %v",
+				fm.nodeID, packet.PacketId, packet.VisitedNodes)
+			return &pb.ForwardResponse{
+				Success:   true,
+				Message:   "Trace completed",
+				TraceHops: packet.TraceHops,
+			}, nil
+		}
 
-		fm.stats.mtx.Lock()
-		fm.stats.PacketsDelivered++
-		fm.stats.mtx.Unlock()
+		if packet.Type == pb.PacketType_PACKET_TYPE_CONTROL {
+			fm.handleControlPacket(packet)
+			return &pb.ForwardResponse{
+				Success: true,
+				Message: "Control packet delivered",
+			}, nil
+		}
+
+		// 端到端加密的载荷只在目的节点解密（字节流分段和数据报同样需要先解密）
+		if err := fm.decrypt(packet); err != nil {
+			log.Printf("[%s] ✗ Packet %s from %s could not be decrypted: %v",
+				fm.nodeID, packet.PacketId, packet.Source, err)
+			fm.recordDrop(packet, "", DropReasonDecryptFailed)
+			return &pb.ForwardResponse{
+				Success: false,
+				Message: fmt.Sprintf("failed to decrypt payload: %v", err),
+			}, nil
+		}
+
+		// 字节流分段交给流管理器，由其负责重传和去重
+		if packet.Type == pb.PacketType_PACKET_TYPE_STREAM {
+			fm.policyMtx.RLock()
+			handler := fm.onStream
+			fm.policyMtx.RUnlock()
+			if handler != nil {
+				handler(packet)
+			}
+			return &pb.ForwardResponse{
+				Success: true,
+				Message: "Stream segment delivered",
+			}, nil
+		}
+
+		// UDP 中继数据报交给中继管理器从本地 UDP 地址发出
+		if packet.Type == pb.PacketType_PACKET_TYPE_DATAGRAM {
+			fm.policyMtx.RLock()
+			handler := fm.onDatagram
+			fm.policyMtx.RUnlock()
+			if handler != nil {
+				handler(packet)
+			}
+			return &pb.ForwardResponse{
+				Success: true,
+				Message: "Datagram delivered",
+			}, nil
+		}
+
+		// 本节点已不再提供目标服务，返回目标不可达，源节点会改投下一个提供者
+		if packet.Service != "" && !fm.providesService(packet.Service) {
+			cerr := newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, packet,
+				"service %s is not provided by %s", packet.Service, fm.nodeID)
+			log.Printf("[%s] ✗ Packet %s rejected: %v", fm.nodeID, packet.PacketId, cerr)
+			fm.recordDrop(packet, "", DropReasonServiceUnavailable)
+			return &pb.ForwardResponse{
+				Success: false,
+				Message: cerr.Error(),
+				Control: cerr.toProto(),
+			}, nil
+		}
+
+		// 重复的数据包（重试、重放等）只确认不投递，与正在投递的原包并发到达的副本同样被过滤
+		if !fm.reserveDelivery(packet) {
+			log.Printf("[%s] Duplicate packet %s from %s suppressed",
+				fm.nodeID, packet.PacketId, packet.Source)
+			fm.stats.mtx.Lock()
+			fm.stats.PacketsDuplicate++
+			fm.stats.mtx.Unlock()
+
+			return &pb.ForwardResponse{
+				Success: true,
+				Message: "Duplicate packet suppressed",
+			}, nil
+		}
+
+		if err := fm.decompress(packet); err != nil {
+			log.Printf("[%s] ✗ Packet %s from %s could not be decompressed: %v",
+				fm.nodeID, packet.PacketId, packet.Source, err)
+			fm.recordDrop(packet, "", DropReasonDecompressFailed)
+			fm.releaseDelivery(packet)
+			return &pb.ForwardResponse{
+				Success: false,
+				Message: fmt.Sprintf("failed to decompress payload: %v", err),
+			}, nil
+		}
+
+		if cerr := fm.intercept(ctx, InterceptDeliver, packet); cerr != nil {
+			fm.releaseDelivery(packet)
+			return &pb.ForwardResponse{
+				Success: false,
+				Message: cerr.Error(),
+				Control: cerr.toProto(),
+			}, nil
+		}
+
+		log.Printf("[%s] ✓ Packet %s delivered! Path: %v (payload: %d bytes)",
+			fm.nodeID, packet.PacketId, packet.VisitedNodes, len(packet.Payload))
+
+		fm.recordDelivered(packet)
+
+		fm.policyMtx.RLock()
+		handler := fm.onDeliver
+		fm.policyMtx.RUnlock()
+		if handler != nil {
+			handler(packet)
+		}
+		fm.confirmDelivery(packet)
 
 		return &pb.ForwardResponse{
 			Success: true,
@@ -162,28 +507,221 @@ func (fm *ForwardManager) HandleIncomingPacket(ctx context.Context, packet *pb.P
 		}, nil
 	}
 
-	// 否则继续转发
-	err := fm.forwardPacket(ctx, packet)
+	// 否则继续转发（TTL 为 0 表示未设置，使用默认值）
+	if packet.Ttl == 0 {
+		packet.Ttl = fm.getDefaultTTL()
+	}
+	packet.Ttl--
+
+	var err error
+	if packet.Ttl == 0 {
+		log.Printf("[%s] ✗ Packet %s TTL exceeded", fm.nodeID, packet.PacketId)
+		fm.recordDrop(packet, "", DropReasonTTLExceeded)
+		err = newControlError(pb.ControlCode_CONTROL_CODE_TTL_EXCEEDED, fm.nodeID, packet,
+			"ttl exceeded at %s", fm.nodeID)
+	} else if cerr := fm.intercept(ctx, InterceptForward, packet); cerr != nil {
+		err = cerr
+	} else if fm.isStoreAndForward() && !packet.Trace &&
+		packet.Type != pb.PacketType_PACKET_TYPE_STREAM && packet.Type != pb.PacketType_PACKET_TYPE_DATAGRAM {
+		// 存储转发：放入后台队列后立即确认（追踪包需要同步返回，仍走同步转发；
+		// 字节流分段由两端负责重传，UDP 数据报不重试，两者都不进入队列和发件箱）
+		if err = fm.enqueue(packet); err == nil {
+			log.Printf("[%s] Packet %s accepted for store-and-forward", fm.nodeID, packet.PacketId)
+			return &pb.ForwardResponse{
+				Success: true,
+				Message: "Packet accepted",
+			}, nil
+		}
+		fm.recordDrop(packet, "", DropReasonQueueFull)
+	} else {
+		err = fm.forwardPacket(ctx, packet)
+	}
+
 	if err != nil {
-		return &pb.ForwardResponse{
-			Success: false,
-			Message: err.Error(),
-		}, nil
+		resp := &pb.ForwardResponse{
+			Success:   false,
+			Message:   err.Error(),
+			TraceHops: packet.TraceHops,
+		}
+
+		// 差错沿同步调用链返回源节点，不再额外发送控制报文，避免源节点重复收到同一个差错
+		// （存储转发的节点已经确认了数据包，放弃转发时由 processQueued 通过控制报文通知源节点）
+		var cerr *ControlError
+		if errors.As(err, &cerr) {
+			resp.Control = cerr.toProto()
+		}
+		return resp, nil
 	}
 
 	return &pb.ForwardResponse{
-		Success: true,
-		Message: "Packet forwarded",
+		Success:   true,
+		Message:   "Packet forwarded",
+		TraceHops: packet.TraceHops,
 	}, nil
 }
 
+// TraceResult 路径追踪结果
+type TraceResult struct {
+	Hops         []*pb.TraceHop // 实际经过的每一跳
+	ExpectedPath []string       // 路由表计算出的路径
+	Diverged     bool           // 实际路径是否与计算路径不一致
+	RTT          time.Duration  // 总往返时间
+}
+
+// Traceroute 向目标节点发送追踪包，记录每一跳的节点、时间戳和下一跳
+// 追踪失败时仍返回到达失败节点为止的部分结果
+func (fm *ForwardManager) Traceroute(ctx context.Context, destination string) (*TraceResult, error) {
+	result := &TraceResult{}
+	if route, err := fm.routeManager.GetRoute(destination); err == nil {
+		result.ExpectedPath = append([]string(nil), route.Path...)
+	}
+
+	now := time.Now()
+	packet := &pb.Packet{
+		Source:       fm.nodeID,
+		Destination:  destination,
+		PacketId:     fmt.Sprintf("trace-%s-%d", fm.nodeID, now.UnixNano()),
+		VisitedNodes: []string{fm.nodeID},
+		Ttl:          fm.getDefaultTTL(),
+		Trace:        true,
+		TraceHops: []*pb.TraceHop{{
+			NodeId:       fm.nodeID,
+			RecvUnixNano: now.UnixNano(),
+		}},
+	}
+
+	log.Printf("[%s] Tracing route to %s (expected path: %v)", fm.nodeID, destination, result.ExpectedPath)
+
+	err := fm.forwardPacket(ctx, packet)
+	result.RTT = time.Since(now)
+	result.Hops = packet.TraceHops
+	result.Diverged = traceDiverged(result.Hops, result.ExpectedPath)
+
+	return result, err
+}
+
+// traceDiverged 判断追踪路径是否偏离计算路径（失败时只比较已走过的前缀）
+func traceDiverged(hops []*pb.TraceHop, expected []string) bool {
+	if len(expected) == 0 {
+		return false
+	}
+	if len(hops) > len(expected) {
+		return true
+	}
+	for i, hop := range hops {
+		if hop.NodeId != expected[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// reportToSource 异步向原始数据包的源节点发送控制报文
+func (fm *ForwardManager) reportToSource(packet *pb.Packet, cerr *ControlError) {
+	// 不为控制报文生成控制报文，避免循环；字节流分段的丢失由发送方重传处理，UDP 数据报丢失无需通知
+	switch packet.Type {
+	case pb.PacketType_PACKET_TYPE_CONTROL, pb.PacketType_PACKET_TYPE_STREAM, pb.PacketType_PACKET_TYPE_DATAGRAM:
+		return
+	}
+	if packet.Source == "" || packet.Source == fm.nodeID {
+		return
+	}
+
+	controlPacket := &pb.Packet{
+		Source:       fm.nodeID,
+		Destination:  packet.Source,
+		PacketId:     fmt.Sprintf("ctl-%s-%d", fm.nodeID, time.Now().UnixNano()),
+		VisitedNodes: []string{fm.nodeID},
+		Type:         pb.PacketType_PACKET_TYPE_CONTROL,
+		Ttl:          fm.getDefaultTTL(),
+		Control:      cerr.toProto(),
+	}
+
+	fm.stats.mtx.Lock()
+	fm.stats.ControlSent++
+	fm.stats.mtx.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := fm.forwardPacket(ctx, controlPacket); err != nil {
+			log.Printf("[%s] Failed to send control packet to %s: %v", fm.nodeID, packet.Source, err)
+		}
+	}()
+}
+
+// handleControlPacket 处理发给本节点的控制报文
+func (fm *ForwardManager) handleControlPacket(packet *pb.Packet) {
+	fm.stats.mtx.Lock()
+	fm.stats.ControlReceived++
+	fm.stats.mtx.Unlock()
+
+	if packet.Control == nil {
+		log.Printf("[%s] Control packet %s without control message", fm.nodeID, packet.PacketId)
+		return
+	}
+
+	cerr := controlErrorFromProto(packet.Control)
+	log.Printf("[%s] ✗ Packet %s undeliverable: %v", fm.nodeID, cerr.PacketID, cerr)
+
+	fm.policyMtx.RLock()
+	handler := fm.onControl
+	fm.policyMtx.RUnlock()
+
+	if handler != nil {
+		handler(cerr)
+	}
+}
+
+// reserveDelivery 在去重缓存中预留数据包，返回 false 表示在去重窗口内已经投递过或正在投递
+// 预留成功后必须调用 confirmDelivery 或 releaseDelivery
+func (fm *ForwardManager) reserveDelivery(packet *pb.Packet) bool {
+	dedup := fm.getDedupCache()
+	if dedup == nil || packet.PacketId == "" {
+		return true
+	}
+	return dedup.Reserve(packet.Source, packet.PacketId)
+}
+
+// confirmDelivery 在去重缓存中确认已成功投递的数据包
+func (fm *ForwardManager) confirmDelivery(packet *pb.Packet) {
+	if dedup := fm.getDedupCache(); dedup != nil && packet.PacketId != "" {
+		dedup.Confirm(packet.Source, packet.PacketId)
+	}
+}
+
+// releaseDelivery 投递失败时删除预留记录，发送方的重试仍能投递
+func (fm *ForwardManager) releaseDelivery(packet *pb.Packet) {
+	if dedup := fm.getDedupCache(); dedup != nil && packet.PacketId != "" {
+		dedup.Release(packet.Source, packet.PacketId)
+	}
+}
+
+// getDedupCache 获取去重缓存，未启用时返回 nil
+func (fm *ForwardManager) getDedupCache() *DedupCache {
+	fm.policyMtx.RLock()
+	defer fm.policyMtx.RUnlock()
+	return fm.dedup
+}
+
+// getDefaultTTL 获取默认 TTL
+func (fm *ForwardManager) getDefaultTTL() uint32 {
+	fm.policyMtx.RLock()
+	defer fm.policyMtx.RUnlock()
+	return fm.defaultTTL
+}
+
 // getClient 获取或创建到指定节点的 gRPC 客户端
 func (fm *ForwardManager) getClient(nodeInfo *NodeInfo) (pb.NodeServiceClient, error) {
+	// 使用 RPCAddr 字段，如果为空则尝试组合 IP:Port
+	addr := nodeInfo.GRPCAddress()
+
 	fm.poolMtx.RLock()
 	conn, exists := fm.connPool[nodeInfo.ID]
 	fm.poolMtx.RUnlock()
 
-	if exists && conn.GetState().String() == "READY" {
+	if exists && connUsable(conn, addr) {
 		return pb.NewNodeServiceClient(conn), nil
 	}
 
@@ -191,21 +729,19 @@ func (fm *ForwardManager) getClient(nodeInfo *NodeInfo) (pb.NodeServiceClient, e
 	fm.poolMtx.Lock()
 	defer fm.poolMtx.Unlock()
 
-	// 双重检查
+	// 双重检查：其他调用方可能已经重建了连接
 	if conn, exists := fm.connPool[nodeInfo.ID]; exists {
-		if conn.GetState().String() == "READY" {
+		if connUsable(conn, addr) {
 			return pb.NewNodeServiceClient(conn), nil
 		}
 		conn.Close()
 	}
 
-	// 使用 RPCAddr 字段，如果为空则尝试组合 IP:Port
-	addr := nodeInfo.RPCAddr
-	if addr == "" {
-		addr = fmt.Sprintf("%s:%d", nodeInfo.IP, nodeInfo.Port)
-	}
-	
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	fm.policyMtx.RLock()
+	creds := fm.tls.ClientCredentials(nodeInfo.ID)
+	fm.policyMtx.RUnlock()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
 	}
@@ -214,17 +750,38 @@ func (fm *ForwardManager) getClient(nodeInfo *NodeInfo) (pb.NodeServiceClient, e
 	return pb.NewNodeServiceClient(conn), nil
 }
 
+// connUsable 检查连接池中的连接是否可以继续使用
+// 新建的连接处于 IDLE 或 CONNECTING 状态，同样可用；连接失败、已关闭或地址变化时需要重建
+func connUsable(conn *grpc.ClientConn, addr string) bool {
+	switch conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return conn.Target() == addr
+}
+
 // GetStats 获取转发统计
 func (fm *ForwardManager) GetStats() ForwardStats {
 	fm.stats.mtx.RLock()
 	defer fm.stats.mtx.RUnlock()
 
+	return fm.totalsLocked()
+}
+
+// totalsLocked 复制全局计数（调用方持有 stats.mtx）
+func (fm *ForwardManager) totalsLocked() ForwardStats {
 	return ForwardStats{
 		PacketsSent:      fm.stats.PacketsSent,
 		PacketsReceived:  fm.stats.PacketsReceived,
 		PacketsForwarded: fm.stats.PacketsForwarded,
 		PacketsDelivered: fm.stats.PacketsDelivered,
 		PacketsDropped:   fm.stats.PacketsDropped,
+		ControlSent:      fm.stats.ControlSent,
+		ControlReceived:  fm.stats.ControlReceived,
+		PacketsDuplicate: fm.stats.PacketsDuplicate,
+		BytesSent:        fm.stats.BytesSent,
+		BytesForwarded:   fm.stats.BytesForwarded,
+		BytesDelivered:   fm.stats.BytesDelivered,
 	}
 }
 
@@ -237,11 +794,22 @@ func (fm *ForwardManager) PrintStats() {
 	fmt.Printf("Packets Forwarded: %d\n", stats.PacketsForwarded)
 	fmt.Printf("Packets Delivered: %d\n", stats.PacketsDelivered)
 	fmt.Printf("Packets Dropped:   %d\n", stats.PacketsDropped)
+	fmt.Printf("Control Sent:      %d\n", stats.ControlSent)
+	fmt.Printf("Control Received:  %d\n", stats.ControlReceived)
+	fmt.Printf("Duplicates:        %d\n", stats.PacketsDuplicate)
+	fmt.Printf("Bytes Sent:        %d\n", stats.BytesSent)
+	fmt.Printf("Bytes Forwarded:   %d\n", stats.BytesForwarded)
+	fmt.Printf("Bytes Delivered:   %d\n", stats.BytesDelivered)
 	fmt.Printf("================================\n")
 }
 
 // Close 关闭转发管理器
 func (fm *ForwardManager) Close() error {
+	if fm.cancel != nil {
+		fm.cancel()
+		fm.wg.Wait()
+	}
+
 	fm.poolMtx.Lock()
 	defer fm.poolMtx.Unlock()
 
@@ -0,0 +1,52 @@
+package route
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// 同步转发失败时差错只沿调用链返回，不再向源节点额外发送控制报文
+func TestSyncForwardFailureIsNotReportedTwice(t *testing.T) {
+	topology := NewTopology()
+	fm := NewForwardManager("n1", topology, NewRouteManager("n1", topology))
+
+	resp, err := fm.HandleIncomingPacket(context.Background(), &pb.Packet{
+		Source:       "n0",
+		Destination:  "n9",
+		PacketId:     "pkt-1",
+		Payload:      []byte("hello"),
+		VisitedNodes: []string{"n0"},
+	})
+	if err != nil {
+		t.Fatalf("HandleIncomingPacket: %v", err)
+	}
+	if resp.Success || resp.Control == nil || resp.Control.Code != pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE {
+		t.Fatalf("resp = %+v, want destination unreachable in the synchronous response", resp)
+	}
+	if sent := fm.GetStats().ControlSent; sent != 0 {
+		t.Fatalf("sent %d control packets, want 0", sent)
+	}
+}
+
+// 存储转发的节点放弃转发时没有同步调用方等待，通过控制报文通知源节点
+func TestQueuedForwardFailureIsReported(t *testing.T) {
+	topology := NewTopology()
+	fm := NewForwardManager("n1", topology, NewRouteManager("n1", topology))
+	fm.SetRetryPolicy(0, 1, time.Millisecond)
+	fm.Start()
+	defer fm.Close()
+
+	fm.processQueued(&queuedPacket{packet: &pb.Packet{
+		Source:       "n0",
+		Destination:  "n9",
+		PacketId:     "pkt-1",
+		Payload:      []byte("hello"),
+		VisitedNodes: []string{"n0", "n1"},
+	}})
+	if sent := fm.GetStats().ControlSent; sent != 1 {
+		t.Fatalf("sent %d control packets, want 1", sent)
+	}
+}
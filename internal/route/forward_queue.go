@@ -0,0 +1,231 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// 转发模式
+const (
+	ForwardModeSync            = "sync"              // 同步转发：沿调用链等待下游响应
+	ForwardModeStoreAndForward = "store_and_forward" // 存储转发：中间节点接收后立即确认，后台异步转发
+)
+
+// 存储转发队列默认参数
+const (
+	defaultQueueSize     = 1024
+	defaultMaxAttempts   = 5
+	defaultRetryInterval = 1 * time.Second
+	queueWorkers         = 4
+)
+
+// queuedPacket 等待后台转发的数据包
+type queuedPacket struct {
+	packet   *pb.Packet
+	attempts int
+}
+
+// SetForwardMode 设置转发模式（需在 Start 之前调用）
+func (fm *ForwardManager) SetForwardMode(mode string) error {
+	switch mode {
+	case "", ForwardModeSync:
+		mode = ForwardModeSync
+	case ForwardModeStoreAndForward:
+	default:
+		return fmt.Errorf("invalid forward mode: %s", mode)
+	}
+
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.mode = mode
+	return nil
+}
+
+// SetRetryPolicy 设置后台转发的重试策略
+func (fm *ForwardManager) SetRetryPolicy(queueSize, maxAttempts int, retryInterval time.Duration) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+
+	if queueSize > 0 {
+		fm.queueSize = queueSize
+	}
+	if maxAttempts > 0 {
+		fm.maxAttempts = maxAttempts
+	}
+	if retryInterval > 0 {
+		fm.retryInterval = retryInterval
+	}
+}
+
+// SetOutbox 设置持久化发件箱，启用后源节点和存储转发的中间节点会先落盘再转发
+func (fm *ForwardManager) SetOutbox(outbox *Outbox) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.outbox = outbox
+}
+
+// Start 启动后台转发队列
+func (fm *ForwardManager) Start() {
+	fm.policyMtx.RLock()
+	queueSize := fm.queueSize
+	fm.policyMtx.RUnlock()
+
+	fm.ctx, fm.cancel = context.WithCancel(context.Background())
+	fm.queue = make(chan *queuedPacket, queueSize)
+
+	fm.wg.Add(queueWorkers)
+	for i := 0; i < queueWorkers; i++ {
+		go fm.queueWorker()
+	}
+}
+
+// ReplayOutbox 将发件箱中未确认的数据包重新放入后台转发队列
+func (fm *ForwardManager) ReplayOutbox() {
+	outbox := fm.getOutbox()
+	if outbox == nil || fm.queue == nil {
+		return
+	}
+
+	packets := outbox.Pending()
+	if len(packets) == 0 {
+		return
+	}
+
+	log.Printf("[%s] Replaying %d packets from outbox", fm.nodeID, len(packets))
+	for _, packet := range packets {
+		select {
+		case fm.queue <- &queuedPacket{packet: packet}:
+		case <-fm.ctx.Done():
+			return
+		}
+	}
+}
+
+// isStoreAndForward 是否为存储转发模式
+func (fm *ForwardManager) isStoreAndForward() bool {
+	fm.policyMtx.RLock()
+	defer fm.policyMtx.RUnlock()
+	return fm.mode == ForwardModeStoreAndForward
+}
+
+// enqueue 持久化数据包并放入后台转发队列
+func (fm *ForwardManager) enqueue(packet *pb.Packet) error {
+	if fm.queue == nil {
+		return errors.New("forward queue not started")
+	}
+
+	if err := fm.persist(packet); err != nil {
+		return err
+	}
+
+	select {
+	case fm.queue <- &queuedPacket{packet: packet}:
+		return nil
+	default:
+		fm.ack(packet)
+		return fmt.Errorf("forward queue full at %s", fm.nodeID)
+	}
+}
+
+// persist 将数据包写入发件箱（未启用时忽略）
+func (fm *ForwardManager) persist(packet *pb.Packet) error {
+	outbox := fm.getOutbox()
+	if outbox == nil {
+		return nil
+	}
+
+	if err := outbox.Append(packet); err != nil {
+		return fmt.Errorf("failed to persist packet %s: %w", packet.PacketId, err)
+	}
+	return nil
+}
+
+// ack 标记数据包已处理完成（未启用发件箱时忽略）
+func (fm *ForwardManager) ack(packet *pb.Packet) {
+	outbox := fm.getOutbox()
+	if outbox == nil {
+		return
+	}
+
+	if err := outbox.Ack(packet.PacketId); err != nil {
+		log.Printf("[%s] Failed to ack packet %s in outbox: %v", fm.nodeID, packet.PacketId, err)
+	}
+}
+
+func (fm *ForwardManager) getOutbox() *Outbox {
+	fm.policyMtx.RLock()
+	defer fm.policyMtx.RUnlock()
+	return fm.outbox
+}
+
+// queueWorker 后台转发协程
+func (fm *ForwardManager) queueWorker() {
+	defer fm.wg.Done()
+
+	for {
+		select {
+		case <-fm.ctx.Done():
+			return
+		case item := <-fm.queue:
+			fm.processQueued(item)
+		}
+	}
+}
+
+// processQueued 转发一个队列中的数据包，可重试的错误延迟后重新入队
+func (fm *ForwardManager) processQueued(item *queuedPacket) {
+	item.attempts++
+
+	ctx, cancel := context.WithTimeout(fm.ctx, 10*time.Second)
+	err := fm.forwardPacket(ctx, item.packet)
+	cancel()
+
+	if err == nil {
+		fm.ack(item.packet)
+		return
+	}
+
+	fm.policyMtx.RLock()
+	maxAttempts := fm.maxAttempts
+	retryInterval := fm.retryInterval
+	fm.policyMtx.RUnlock()
+
+	if item.attempts < maxAttempts && isRetryable(err) {
+		log.Printf("[%s] Packet %s attempt %d/%d failed, retrying in %v: %v",
+			fm.nodeID, item.packet.PacketId, item.attempts, maxAttempts, retryInterval, err)
+		time.AfterFunc(retryInterval, func() {
+			select {
+			case fm.queue <- item:
+			case <-fm.ctx.Done():
+			}
+		})
+		return
+	}
+
+	log.Printf("[%s] ✗ Giving up on packet %s after %d attempts: %v",
+		fm.nodeID, item.packet.PacketId, item.attempts, err)
+	fm.ack(item.packet)
+
+	// 没有同步调用链可以返回错误，通过控制报文通知源节点
+	// 下游同步转发的节点只把差错返回给本节点，差错由下游产生时同样由本节点转告源节点
+	var cerr *ControlError
+	if !errors.As(err, &cerr) {
+		cerr = newControlError(pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE, fm.nodeID, item.packet,
+			"%v", err)
+	}
+	fm.reportToSource(item.packet, cerr)
+}
+
+// isRetryable 判断转发错误是否值得重试（路由或下一跳暂时不可用）
+func isRetryable(err error) bool {
+	var cerr *ControlError
+	if errors.As(err, &cerr) {
+		return cerr.Code == pb.ControlCode_CONTROL_CODE_DEST_UNREACHABLE
+	}
+	return true
+}
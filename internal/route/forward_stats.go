@@ -0,0 +1,412 @@
+package route
+
+import (
+	"sort"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// 丢包原因
+const (
+	DropReasonNoRoute            = "no_route"
+	DropReasonNextHopUnknown     = "next_hop_unknown"
+	DropReasonNextHopUnreachable = "next_hop_unreachable"
+	DropReasonDownstream         = "downstream_failure"
+	DropReasonTTLExceeded        = "ttl_exceeded"
+	DropReasonAdminProhibited    = "admin_prohibited"
+	DropReasonPayloadTooLarge    = "payload_too_large"
+	DropReasonQueueFull          = "queue_full"
+	DropReasonPersistFailed      = "persist_failed"
+	DropReasonServiceUnavailable = "service_unavailable"
+	DropReasonIntercepted        = "intercepted"
+	DropReasonDecompressFailed   = "decompress_failed"
+	DropReasonEncryptFailed      = "encrypt_failed"
+	DropReasonDecryptFailed      = "decrypt_failed"
+)
+
+// maxStatsKeys 单个维度（邻居/目标）最多跟踪的条目数，防止无效目标撑大统计表
+const maxStatsKeys = 4096
+
+// LatencyBuckets 延迟直方图各桶的上界（最后还有一个 +Inf 桶）
+var LatencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// LatencyHistogram 转发延迟直方图（下一跳确认所需时间）
+type LatencyHistogram struct {
+	Counts []int64 // 与 LatencyBuckets 对应，最后一个为 +Inf 桶
+	Count  int64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+// observe 记录一次延迟
+func (h *LatencyHistogram) observe(d time.Duration) {
+	if h.Counts == nil {
+		h.Counts = make([]int64, len(LatencyBuckets)+1)
+	}
+
+	idx := len(LatencyBuckets)
+	for i, bound := range LatencyBuckets {
+		if d <= bound {
+			idx = i
+			break
+		}
+	}
+	h.Counts[idx]++
+	h.Count++
+	h.Sum += d
+	if d > h.Max {
+		h.Max = d
+	}
+}
+
+// clone 复制直方图
+func (h *LatencyHistogram) clone() LatencyHistogram {
+	c := *h
+	c.Counts = append([]int64(nil), h.Counts...)
+	return c
+}
+
+// Mean 平均延迟
+func (h *LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// TrafficStats 某个邻居或目标节点的转发统计
+//
+// 对邻居：PacketsSent 为交给该邻居的次数，PacketsForwarded 为被该邻居接收的次数；
+// 对目标：PacketsSent 为本节点发起的数据包数，PacketsForwarded 为成功转发到下一跳的数据包数（含中转）
+type TrafficStats struct {
+	PacketsSent      int64
+	PacketsForwarded int64
+	PacketsDropped   int64
+	BytesForwarded   int64
+	DropReasons      map[string]int64
+	Latency          LatencyHistogram
+}
+
+// clone 复制统计
+func (ts *TrafficStats) clone() TrafficStats {
+	c := *ts
+	c.DropReasons = make(map[string]int64, len(ts.DropReasons))
+	for reason, count := range ts.DropReasons {
+		c.DropReasons[reason] = count
+	}
+	c.Latency = ts.Latency.clone()
+	return c
+}
+
+// SubscriberStats 发布者视角下某个主题订阅者的投递统计
+type SubscriberStats struct {
+	Topic          string
+	Subscriber     string
+	Delivered      int64     // 已确认投递的消息数
+	Failed         int64     // 投递失败的消息数
+	BytesDelivered int64     // 已确认投递的字节数
+	LastDelivered  time.Time // 最近一次确认投递的时间
+}
+
+// subscriberKey 按订阅者细分统计的键
+type subscriberKey struct {
+	topic      string
+	subscriber string
+}
+
+// DetailedStats 转发统计快照（全局计数 + 按邻居 / 目标 / 主题订阅者细分）
+type DetailedStats struct {
+	Totals       ForwardStats
+	DropReasons  map[string]int64
+	Neighbors    map[string]TrafficStats
+	Destinations map[string]TrafficStats
+	Subscribers  []SubscriberStats // 按主题、订阅者排序
+}
+
+// trafficEntry 获取或创建统计条目，超过上限时返回 nil（调用方持有 stats.mtx）
+func trafficEntry(m map[string]*TrafficStats, key string) *TrafficStats {
+	if key == "" {
+		return nil
+	}
+	entry, ok := m[key]
+	if !ok {
+		if len(m) >= maxStatsKeys {
+			return nil
+		}
+		entry = &TrafficStats{DropReasons: make(map[string]int64)}
+		m[key] = entry
+	}
+	return entry
+}
+
+// initDetail 初始化细分统计表（调用方持有 stats.mtx）
+func (s *ForwardStats) initDetail() {
+	if s.neighbors == nil {
+		s.neighbors = make(map[string]*TrafficStats)
+		s.destinations = make(map[string]*TrafficStats)
+		s.dropReasons = make(map[string]int64)
+		s.subscribers = make(map[subscriberKey]*SubscriberStats)
+	}
+}
+
+// recordSent 记录本节点发起的数据包
+func (fm *ForwardManager) recordSent(packet *pb.Packet) {
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.initDetail()
+	fm.stats.PacketsSent++
+	fm.stats.BytesSent += int64(len(packet.Payload))
+	if dest := trafficEntry(fm.stats.destinations, packet.Destination); dest != nil {
+		dest.PacketsSent++
+	}
+}
+
+// recordAttempt 记录一次交给邻居的转发
+func (fm *ForwardManager) recordAttempt(nextHop string) {
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.initDetail()
+	if neighbor := trafficEntry(fm.stats.neighbors, nextHop); neighbor != nil {
+		neighbor.PacketsSent++
+	}
+}
+
+// recordForwarded 记录一次成功转发及下一跳确认的延迟
+func (fm *ForwardManager) recordForwarded(packet *pb.Packet, nextHop string, latency time.Duration) {
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.initDetail()
+	size := int64(len(packet.Payload))
+	fm.stats.PacketsForwarded++
+	fm.stats.BytesForwarded += size
+
+	for _, entry := range []*TrafficStats{
+		trafficEntry(fm.stats.neighbors, nextHop),
+		trafficEntry(fm.stats.destinations, packet.Destination),
+	} {
+		if entry == nil {
+			continue
+		}
+		entry.PacketsForwarded++
+		entry.BytesForwarded += size
+		entry.Latency.observe(latency)
+	}
+}
+
+// recordDelivered 记录一次投递到本节点
+func (fm *ForwardManager) recordDelivered(packet *pb.Packet) {
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.PacketsDelivered++
+	fm.stats.BytesDelivered += int64(len(packet.Payload))
+}
+
+// recordDrop 记录一次丢包（nextHop 为空表示还未选出下一跳）
+func (fm *ForwardManager) recordDrop(packet *pb.Packet, nextHop, reason string) {
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.initDetail()
+	fm.stats.PacketsDropped++
+	fm.stats.dropReasons[reason]++
+
+	for _, entry := range []*TrafficStats{
+		trafficEntry(fm.stats.neighbors, nextHop),
+		trafficEntry(fm.stats.destinations, packet.Destination),
+	} {
+		if entry == nil {
+			continue
+		}
+		entry.PacketsDropped++
+		entry.DropReasons[reason]++
+	}
+}
+
+// recordPublish 记录一次按主题发布在每个订阅者上的投递结果
+func (fm *ForwardManager) recordPublish(topic string, result *MulticastResult, size int) {
+	failed := make(map[string]bool, len(result.Failed))
+	for _, id := range result.Failed {
+		failed[id] = true
+	}
+
+	fm.stats.mtx.Lock()
+	defer fm.stats.mtx.Unlock()
+
+	fm.stats.initDetail()
+	now := time.Now()
+	for _, id := range result.Targets {
+		key := subscriberKey{topic: topic, subscriber: id}
+		entry, ok := fm.stats.subscribers[key]
+		if !ok {
+			if len(fm.stats.subscribers) >= maxStatsKeys {
+				continue
+			}
+			entry = &SubscriberStats{Topic: topic, Subscriber: id}
+			fm.stats.subscribers[key] = entry
+		}
+
+		if failed[id] {
+			entry.Failed++
+			continue
+		}
+		entry.Delivered++
+		entry.BytesDelivered += int64(size)
+		entry.LastDelivered = now
+	}
+}
+
+// GetSubscriberStats 获取本节点发布的主题按订阅者细分的投递统计（topic 为空表示全部主题）
+func (fm *ForwardManager) GetSubscriberStats(topic string) []SubscriberStats {
+	fm.stats.mtx.RLock()
+	defer fm.stats.mtx.RUnlock()
+
+	return fm.subscriberStatsLocked(topic)
+}
+
+// subscriberStatsLocked 复制按订阅者细分的统计并排序（调用方持有 stats.mtx）
+func (fm *ForwardManager) subscriberStatsLocked(topic string) []SubscriberStats {
+	var stats []SubscriberStats
+	for key, entry := range fm.stats.subscribers {
+		if topic == "" || key.topic == topic {
+			stats = append(stats, *entry)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Topic != stats[j].Topic {
+			return stats[i].Topic < stats[j].Topic
+		}
+		return stats[i].Subscriber < stats[j].Subscriber
+	})
+	return stats
+}
+
+// dropReasonForControl 根据差错类型确定丢包原因
+func dropReasonForControl(cerr *ControlError) string {
+	switch cerr.Code {
+	case pb.ControlCode_CONTROL_CODE_ADMIN_PROHIBITED:
+		return DropReasonAdminProhibited
+	case pb.ControlCode_CONTROL_CODE_FRAGMENTATION_NEEDED:
+		return DropReasonPayloadTooLarge
+	case pb.ControlCode_CONTROL_CODE_TTL_EXCEEDED:
+		return DropReasonTTLExceeded
+	default:
+		return DropReasonNoRoute
+	}
+}
+
+// GetDetailedStats 获取按邻居和目标细分的转发统计
+func (fm *ForwardManager) GetDetailedStats() *DetailedStats {
+	fm.stats.mtx.RLock()
+	defer fm.stats.mtx.RUnlock()
+
+	snapshot := &DetailedStats{
+		DropReasons:  make(map[string]int64, len(fm.stats.dropReasons)),
+		Neighbors:    make(map[string]TrafficStats, len(fm.stats.neighbors)),
+		Destinations: make(map[string]TrafficStats, len(fm.stats.destinations)),
+	}
+	snapshot.Totals = fm.totalsLocked()
+
+	for reason, count := range fm.stats.dropReasons {
+		snapshot.DropReasons[reason] = count
+	}
+	for id, entry := range fm.stats.neighbors {
+		snapshot.Neighbors[id] = entry.clone()
+	}
+	for id, entry := range fm.stats.destinations {
+		snapshot.Destinations[id] = entry.clone()
+	}
+	snapshot.Subscribers = fm.subscriberStatsLocked("")
+	return snapshot
+}
+
+// statsToProto 将统计快照转换为 GetStats 响应
+func statsToProto(nodeID string, stats *DetailedStats) *pb.GetStatsResponse {
+	resp := &pb.GetStatsResponse{
+		Success:          true,
+		NodeId:           nodeID,
+		PacketsSent:      uint64(stats.Totals.PacketsSent),
+		PacketsReceived:  uint64(stats.Totals.PacketsReceived),
+		PacketsForwarded: uint64(stats.Totals.PacketsForwarded),
+		PacketsDelivered: uint64(stats.Totals.PacketsDelivered),
+		PacketsDropped:   uint64(stats.Totals.PacketsDropped),
+		PacketsDuplicate: uint64(stats.Totals.PacketsDuplicate),
+		ControlSent:      uint64(stats.Totals.ControlSent),
+		ControlReceived:  uint64(stats.Totals.ControlReceived),
+		BytesSent:        uint64(stats.Totals.BytesSent),
+		BytesForwarded:   uint64(stats.Totals.BytesForwarded),
+		BytesDelivered:   uint64(stats.Totals.BytesDelivered),
+		DropReasons:      make(map[string]uint64, len(stats.DropReasons)),
+		Neighbors:        make(map[string]*pb.TrafficStats, len(stats.Neighbors)),
+		Destinations:     make(map[string]*pb.TrafficStats, len(stats.Destinations)),
+	}
+
+	for reason, count := range stats.DropReasons {
+		resp.DropReasons[reason] = uint64(count)
+	}
+	for id, entry := range stats.Neighbors {
+		resp.Neighbors[id] = trafficToProto(entry)
+	}
+	for id, entry := range stats.Destinations {
+		resp.Destinations[id] = trafficToProto(entry)
+	}
+	for _, entry := range stats.Subscribers {
+		msg := &pb.SubscriberStats{
+			Topic:          entry.Topic,
+			Subscriber:     entry.Subscriber,
+			Delivered:      uint64(entry.Delivered),
+			Failed:         uint64(entry.Failed),
+			BytesDelivered: uint64(entry.BytesDelivered),
+		}
+		if !entry.LastDelivered.IsZero() {
+			msg.LastDeliveredUnixNano = entry.LastDelivered.UnixNano()
+		}
+		resp.Subscribers = append(resp.Subscribers, msg)
+	}
+	return resp
+}
+
+// trafficToProto 将单项统计转换为 protobuf 消息
+func trafficToProto(ts TrafficStats) *pb.TrafficStats {
+	msg := &pb.TrafficStats{
+		PacketsSent:      uint64(ts.PacketsSent),
+		PacketsForwarded: uint64(ts.PacketsForwarded),
+		PacketsDropped:   uint64(ts.PacketsDropped),
+		BytesForwarded:   uint64(ts.BytesForwarded),
+		DropReasons:      make(map[string]uint64, len(ts.DropReasons)),
+		Latency: &pb.LatencyHistogram{
+			Count:    uint64(ts.Latency.Count),
+			SumNanos: int64(ts.Latency.Sum),
+			MaxNanos: int64(ts.Latency.Max),
+		},
+	}
+
+	for reason, count := range ts.DropReasons {
+		msg.DropReasons[reason] = uint64(count)
+	}
+	for _, bound := range LatencyBuckets {
+		msg.Latency.BoundsNanos = append(msg.Latency.BoundsNanos, int64(bound))
+	}
+	msg.Latency.Counts = make([]uint64, len(LatencyBuckets)+1)
+	for i, count := range ts.Latency.Counts {
+		msg.Latency.Counts[i] = uint64(count)
+	}
+	return msg
+}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"path/filepath"
+	"sort"
 	"time"
 
 	pb "spfnet/proto"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // NodeServer 实现 gRPC 服务
@@ -18,6 +19,7 @@ type NodeServer struct {
 	pb.UnimplementedNodeServiceServer
 	NodeID         string
 	ForwardManager *ForwardManager
+	TLS            *NodeTLS // 校验转发请求方与上一跳一致，为 nil 时不校验
 }
 
 // ControlServer 实现控制管理服务
@@ -27,21 +29,32 @@ type ControlServer struct {
 	Topology       *Topology
 	ForwardManager *ForwardManager
 	TopologySync   *TopologySync
+	RouteManager   *RouteManager
+	Events         *EventBus
+	Tunnels        *TunnelManager
+	Files          *FileTransferManager
+	TLS            *NodeTLS // 连接其他节点时使用的 TLS 配置，为 nil 时使用明文连接
 }
 
-func NewNodeServer(nodeID string, forwardManager *ForwardManager) *NodeServer {
+func NewNodeServer(nodeID string, forwardManager *ForwardManager, nodeTLS *NodeTLS) *NodeServer {
 	return &NodeServer{
 		NodeID:         nodeID,
 		ForwardManager: forwardManager,
+		TLS:            nodeTLS,
 	}
 }
 
-func NewControlServer(nodeID string, topology *Topology, forwardManager *ForwardManager, topologySync *TopologySync) *ControlServer {
+func NewControlServer(nodeID string, topology *Topology, forwardManager *ForwardManager, topologySync *TopologySync, routeManager *RouteManager, events *EventBus, tunnels *TunnelManager, files *FileTransferManager, nodeTLS *NodeTLS) *ControlServer {
 	return &ControlServer{
 		NodeID:         nodeID,
 		Topology:       topology,
 		ForwardManager: forwardManager,
 		TopologySync:   topologySync,
+		RouteManager:   routeManager,
+		Events:         events,
+		Tunnels:        tunnels,
+		Files:          files,
+		TLS:            nodeTLS,
 	}
 }
 
@@ -52,6 +65,13 @@ func (s *NodeServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingRes
 }
 
 func (s *NodeServer) ForwardPacket(ctx context.Context, packet *pb.Packet) (*pb.ForwardResponse, error) {
+	if err := s.TLS.verifyPreviousHop(ctx, packet); err != nil {
+		log.Printf("[%s] ✗ Packet %s rejected: %v", s.NodeID, packet.PacketId, err)
+		return &pb.ForwardResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
 	return s.ForwardManager.HandleIncomingPacket(ctx, packet)
 }
 
@@ -106,7 +126,7 @@ func (s *ControlServer) SendPacket(ctx context.Context, req *pb.SendPacketReques
 	}
 
 	// 建立到源节点的 gRPC 连接
-	conn, err := grpc.Dial(req.SourceAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(req.SourceAddress, grpc.WithTransportCredentials(s.TLS.ClientCredentials(req.Packet.Source)))
 	if err != nil {
 		return &pb.SendPacketResponse{
 			Success:  false,
@@ -168,39 +188,20 @@ func (s *ControlServer) AddLink(ctx context.Context, req *pb.AddLinkRequest) (*p
 		}, nil
 	}
 
-	// 添加邻居节点到拓扑
-	neighborNode := &NodeInfo{
-		ID:      req.Neighbor,
-		RPCAddr: req.NeighborAddress,
-		Status:  NodeStatusUnknown,
-	}
-	s.Topology.AddNode(neighborNode)
-
-	// 确定链路成本
-	finalCost := req.Cost
-	if req.AutoProbe || finalCost <= 0 {
-		// 自动探测链路质量
-		probedCost, err := s.probeLinkCost(ctx, req.NeighborAddress)
-		if err != nil {
-			log.Printf("[%s] Failed to probe link to %s: %v", s.NodeID, req.Neighbor, err)
-			return &pb.AddLinkResponse{
-				Success: false,
-				Message: fmt.Sprintf("failed to probe link: %v", err),
-			}, nil
-		}
-		finalCost = probedCost
-		log.Printf("[%s] Auto-probed link cost to %s: %.2f", s.NodeID, req.Neighbor, finalCost)
+	if s.TopologySync == nil {
+		return &pb.AddLinkResponse{
+			Success: false,
+			Message: "topology sync is not initialized",
+		}, nil
 	}
 
-	// 更新拓扑中的链路
-	s.Topology.UpdateLink(s.NodeID, req.Neighbor, finalCost)
-
-	// 通过 Serf 广播链路更新事件，让集群中的其他节点知道
-	if s.TopologySync != nil {
-		if err := s.TopologySync.RegisterLink(s.NodeID, req.Neighbor, finalCost); err != nil {
-			log.Printf("[%s] Warning: Failed to broadcast link update: %v", s.NodeID, err)
-			// 不返回错误，因为本地拓扑已经更新成功
-		}
+	finalCost, _, err := s.TopologySync.AddLocalLink(ctx, req.Neighbor, req.NeighborAddress, req.Cost, req.AutoProbe)
+	if err != nil {
+		log.Printf("[%s] ✗ Failed to add link to %s: %v", s.NodeID, req.Neighbor, err)
+		return &pb.AddLinkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
 	}
 
 	log.Printf("[%s] ✓ Successfully added link to %s with cost %.2f", s.NodeID, req.Neighbor, finalCost)
@@ -243,34 +244,419 @@ func (s *ControlServer) EnableSync(ctx context.Context, req *pb.EnableSyncReques
 	}, nil
 }
 
-// probeLinkCost 探测链路成本
-func (s *ControlServer) probeLinkCost(ctx context.Context, address string) (float64, error) {
-	// 建立 gRPC 连接
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func (s *ControlServer) Traceroute(ctx context.Context, req *pb.TracerouteRequest) (*pb.TracerouteResponse, error) {
+	log.Printf("[%s] Received Traceroute request: destination=%s", s.NodeID, req.Destination)
+
+	if req.Destination == "" {
+		return &pb.TracerouteResponse{
+			Success: false,
+			Message: "destination cannot be empty",
+		}, nil
+	}
+
+	traceCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := s.ForwardManager.Traceroute(traceCtx, req.Destination)
+	resp := &pb.TracerouteResponse{
+		Success:      err == nil,
+		Hops:         result.Hops,
+		ExpectedPath: result.ExpectedPath,
+		Diverged:     result.Diverged,
+		RttNanos:     result.RTT.Nanoseconds(),
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to connect: %w", err)
+		resp.Message = fmt.Sprintf("trace failed: %v", err)
+		return resp, nil
 	}
-	defer conn.Close()
 
-	client := pb.NewNodeServiceClient(conn)
+	resp.Message = fmt.Sprintf("reached %s in %d hops", req.Destination, len(result.Hops)-1)
+	log.Printf("[%s] ✓ Traceroute to %s: %d hops, rtt=%v, diverged=%v",
+		s.NodeID, req.Destination, len(result.Hops)-1, result.RTT, result.Diverged)
+	return resp, nil
+}
+
+func (s *ControlServer) RemoveLink(ctx context.Context, req *pb.RemoveLinkRequest) (*pb.RemoveLinkResponse, error) {
+	log.Printf("[%s] Received RemoveLink request: neighbor=%s", s.NodeID, req.Neighbor)
+
+	if req.Neighbor == "" {
+		return &pb.RemoveLinkResponse{
+			Success: false,
+			Message: "neighbor ID cannot be empty",
+		}, nil
+	}
+
+	if s.TopologySync == nil {
+		return &pb.RemoveLinkResponse{
+			Success: false,
+			Message: "topology sync is not initialized",
+		}, nil
+	}
+
+	if err := s.TopologySync.RemoveLocalLink(req.Neighbor); err != nil {
+		return &pb.RemoveLinkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
 
-	// 发送探测请求
-	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	log.Printf("[%s] ✓ Successfully removed link to %s", s.NodeID, req.Neighbor)
+
+	return &pb.RemoveLinkResponse{
+		Success: true,
+		Message: fmt.Sprintf("link removed: %s -> %s", s.NodeID, req.Neighbor),
+	}, nil
+}
+
+func (s *ControlServer) SetLinkCost(ctx context.Context, req *pb.SetLinkCostRequest) (*pb.SetLinkCostResponse, error) {
+	log.Printf("[%s] Received SetLinkCost request: neighbor=%s, cost=%.2f, auto_probe=%v",
+		s.NodeID, req.Neighbor, req.Cost, req.AutoProbe)
+
+	if req.Neighbor == "" {
+		return &pb.SetLinkCostResponse{
+			Success: false,
+			Message: "neighbor ID cannot be empty",
+		}, nil
+	}
+
+	if s.TopologySync == nil {
+		return &pb.SetLinkCostResponse{
+			Success: false,
+			Message: "topology sync is not initialized",
+		}, nil
+	}
+
+	finalCost, pinned, err := s.TopologySync.SetLocalLinkCost(ctx, req.Neighbor, req.Cost, req.AutoProbe)
+	if err != nil {
+		log.Printf("[%s] ✗ Failed to set link cost to %s: %v", s.NodeID, req.Neighbor, err)
+		return &pb.SetLinkCostResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	log.Printf("[%s] ✓ Link cost to %s set to %.2f (pinned=%v)", s.NodeID, req.Neighbor, finalCost, pinned)
+
+	return &pb.SetLinkCostResponse{
+		Success: true,
+		Message: fmt.Sprintf("link cost updated: %s -> %s (cost: %.2f)", s.NodeID, req.Neighbor, finalCost),
+		Cost:    finalCost,
+		Pinned:  pinned,
+	}, nil
+}
+
+func (s *ControlServer) GetRoutes(ctx context.Context, req *pb.GetRoutesRequest) (*pb.GetRoutesResponse, error) {
+	if s.RouteManager == nil {
+		return &pb.GetRoutesResponse{
+			Success: false,
+			Message: "route manager is not initialized",
+			NodeId:  s.NodeID,
+		}, nil
+	}
+
+	routes := s.RouteManager.GetRouteTable().GetAllRoutes()
+	entries := make([]*pb.RouteEntry, 0, len(routes))
+	for _, route := range routes {
+		entries = append(entries, routeToProto(route))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Destination < entries[j].Destination })
+
+	return &pb.GetRoutesResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d routes", len(entries)),
+		NodeId:  s.NodeID,
+		Routes:  entries,
+	}, nil
+}
+
+func (s *ControlServer) GetRoute(ctx context.Context, req *pb.GetRouteRequest) (*pb.GetRouteResponse, error) {
+	if req.Destination == "" {
+		return &pb.GetRouteResponse{
+			Success: false,
+			Message: "destination cannot be empty",
+		}, nil
+	}
+
+	if s.RouteManager == nil {
+		return &pb.GetRouteResponse{
+			Success: false,
+			Message: "route manager is not initialized",
+		}, nil
+	}
+
+	route, err := s.RouteManager.GetRoute(req.Destination)
+	if err != nil {
+		return &pb.GetRouteResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetRouteResponse{
+		Success: true,
+		Message: fmt.Sprintf("route to %s via %s", route.Destination, route.NextHop),
+		Route:   routeToProto(route),
+	}, nil
+}
+
+func (s *ControlServer) GetTopology(ctx context.Context, req *pb.GetTopologyRequest) (*pb.GetTopologyResponse, error) {
+	nodes := s.Topology.GetAllNodes()
+	nodeEntries := make([]*pb.TopologyNode, 0, len(nodes))
+	for _, node := range nodes {
+		nodeEntries = append(nodeEntries, &pb.TopologyNode{
+			Id:      node.ID,
+			Address: node.GRPCAddress(),
+			Status:  node.Status.String(),
+		})
+	}
+	sort.Slice(nodeEntries, func(i, j int) bool { return nodeEntries[i].Id < nodeEntries[j].Id })
+
+	var linkEntries []*pb.TopologyLink
+	for _, link := range s.Topology.GetLinks() {
+		if link.Removed {
+			continue
+		}
+		// 统一按字典序排列链路两端，便于阅读和比较
+		from, to := link.From, link.To
+		if from > to {
+			from, to = to, from
+		}
+		linkEntries = append(linkEntries, &pb.TopologyLink{
+			From:     from,
+			To:       to,
+			Cost:     link.Cost,
+			Pinned:   link.Pinned,
+			Sequence: link.Sequence,
+		})
+	}
+	sort.Slice(linkEntries, func(i, j int) bool {
+		if linkEntries[i].From != linkEntries[j].From {
+			return linkEntries[i].From < linkEntries[j].From
+		}
+		return linkEntries[i].To < linkEntries[j].To
+	})
+
+	return &pb.GetTopologyResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d nodes, %d links", len(nodeEntries), len(linkEntries)),
+		NodeId:  s.NodeID,
+		Nodes:   nodeEntries,
+		Links:   linkEntries,
+	}, nil
+}
+
+func (s *ControlServer) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	if s.ForwardManager == nil {
+		return &pb.GetStatsResponse{
+			Success: false,
+			Message: "forward manager is not initialized",
+			NodeId:  s.NodeID,
+		}, nil
+	}
+
+	return statsToProto(s.NodeID, s.ForwardManager.GetDetailedStats()), nil
+}
+
+func (s *ControlServer) WatchEvents(req *pb.WatchEventsRequest, stream pb.ControlService_WatchEventsServer) error {
+	if s.Events == nil {
+		return fmt.Errorf("event bus is not initialized")
+	}
+
+	log.Printf("[%s] Event watcher connected (types: %v)", s.NodeID, req.Types)
+
+	events, cancel := s.Events.Subscribe(0, req.Types...)
 	defer cancel()
 
-	resp, err := client.ProbeLinkQuality(probeCtx, &pb.ProbeRequest{
-		Source:    s.NodeID,
-		Target:    "",
-		SelfDebug: true, // 使用调试模式
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Printf("[%s] Event watcher disconnected", s.NodeID)
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event.toProto()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *ControlServer) Multicast(ctx context.Context, req *pb.MulticastRequest) (*pb.MulticastResponse, error) {
+	log.Printf("[%s] Received Multicast request: group=%s, topic=%s, broadcast=%v",
+		s.NodeID, req.Group, req.Topic, req.Broadcast)
+
+	if s.ForwardManager == nil {
+		return &pb.MulticastResponse{
+			Success: false,
+			Message: "forward manager is not initialized",
+		}, nil
+	}
+
+	var result *MulticastResult
+	var err error
+	switch {
+	case req.Topic != "":
+		result, err = s.ForwardManager.Publish(ctx, req.Topic, req.Payload)
+	case req.Broadcast:
+		result, err = s.ForwardManager.SendBroadcast(ctx, req.Payload)
+	case req.Group != "":
+		result, err = s.ForwardManager.SendMulticast(ctx, req.Group, req.Payload)
+	default:
+		return &pb.MulticastResponse{
+			Success: false,
+			Message: "group or topic cannot be empty",
+		}, nil
+	}
+
+	resp := &pb.MulticastResponse{
+		PacketId:           result.PacketID,
+		Targets:            result.Targets,
+		FailedDestinations: result.Failed,
+	}
+	switch {
+	case err != nil:
+		resp.Message = fmt.Sprintf("multicast failed: %v", err)
+	case len(result.Failed) > 0:
+		resp.Message = fmt.Sprintf("delivered to %d of %d targets",
+			len(result.Targets)-len(result.Failed), len(result.Targets))
+	default:
+		resp.Success = true
+		resp.Message = fmt.Sprintf("delivered to %d targets", len(result.Targets))
+	}
+	return resp, nil
+}
+
+func (s *ControlServer) AddTunnel(ctx context.Context, req *pb.AddTunnelRequest) (*pb.AddTunnelResponse, error) {
+	log.Printf("[%s] Received AddTunnel request: %s -> %s -> %s", s.NodeID, req.Listen, req.Node, req.Target)
+
+	if s.Tunnels == nil {
+		return &pb.AddTunnelResponse{
+			Success: false,
+			Message: "tunnel manager is not initialized",
+		}, nil
+	}
+
+	stats, err := s.Tunnels.Add(TunnelSpec{
+		Name:     req.Name,
+		Listen:   req.Listen,
+		Node:     req.Node,
+		Target:   req.Target,
+		MaxConns: int(req.MaxConns),
 	})
+	if err != nil {
+		return &pb.AddTunnelResponse{
+			Success: false,
+			Message: fmt.Sprintf("failed to add tunnel: %v", err),
+		}, nil
+	}
+
+	return &pb.AddTunnelResponse{
+		Success: true,
+		Message: fmt.Sprintf("tunnel %s added", stats.Name),
+		Tunnel:  tunnelToProto(stats),
+	}, nil
+}
+
+func (s *ControlServer) RemoveTunnel(ctx context.Context, req *pb.RemoveTunnelRequest) (*pb.RemoveTunnelResponse, error) {
+	log.Printf("[%s] Received RemoveTunnel request: %s", s.NodeID, req.Name)
+
+	if s.Tunnels == nil {
+		return &pb.RemoveTunnelResponse{
+			Success: false,
+			Message: "tunnel manager is not initialized",
+		}, nil
+	}
+
+	if err := s.Tunnels.Remove(req.Name); err != nil {
+		return &pb.RemoveTunnelResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.RemoveTunnelResponse{
+		Success: true,
+		Message: fmt.Sprintf("tunnel %s removed", req.Name),
+	}, nil
+}
 
+func (s *ControlServer) ListTunnels(ctx context.Context, req *pb.ListTunnelsRequest) (*pb.ListTunnelsResponse, error) {
+	if s.Tunnels == nil {
+		return &pb.ListTunnelsResponse{
+			Success: false,
+			Message: "tunnel manager is not initialized",
+		}, nil
+	}
+
+	resp := &pb.ListTunnelsResponse{Success: true}
+	for _, stats := range s.Tunnels.List() {
+		resp.Tunnels = append(resp.Tunnels, tunnelToProto(stats))
+	}
+	return resp, nil
+}
+
+func (s *ControlServer) SendFile(ctx context.Context, req *pb.SendFileRequest) (*pb.SendFileResponse, error) {
+	if req.Destination == "" || req.Path == "" {
+		return &pb.SendFileResponse{
+			Success: false,
+			Message: "destination and path cannot be empty",
+		}, nil
+	}
+	if s.Files == nil {
+		return &pb.SendFileResponse{
+			Success: false,
+			Message: "file transfer is not initialized",
+		}, nil
+	}
+
+	// 控制服务只能读取发送目录中的文件
+	path, err := s.Files.ResolveSendPath(req.Path)
 	if err != nil {
-		return 0, fmt.Errorf("probe failed: %w", err)
+		log.Printf("[%s] ✗ SendFile rejected: %v", s.NodeID, err)
+		return &pb.SendFileResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	name := req.Name
+	if name == "" {
+		name = filepath.Base(req.Path)
 	}
 
-	if !resp.Success {
-		return 0, fmt.Errorf("probe unsuccessful: %s", resp.Message)
+	result, err := s.Files.SendFile(ctx, req.Destination, path, name)
+	if err != nil {
+		resp := &pb.SendFileResponse{Success: false, Message: err.Error()}
+		if result != nil {
+			resp.Name = result.Name
+			resp.Size = result.Size
+			resp.Sha256 = result.SHA256
+			resp.BytesSent = result.BytesSent
+			resp.Attempts = int32(result.Attempts)
+		}
+		return resp, nil
 	}
 
-	return resp.Cost, nil
+	return &pb.SendFileResponse{
+		Success:     true,
+		Message:     fmt.Sprintf("file %s delivered to %s", result.Name, req.Destination),
+		Name:        result.Name,
+		Size:        result.Size,
+		Sha256:      result.SHA256,
+		ResumedFrom: result.ResumedFrom,
+		BytesSent:   result.BytesSent,
+		Attempts:    int32(result.Attempts),
+		DurationMs:  result.Duration.Milliseconds(),
+	}, nil
+}
+
+// routeToProto 将路由条目转换为 protobuf 消息
+func routeToProto(route *Route) *pb.RouteEntry {
+	return &pb.RouteEntry{
+		Destination: route.Destination,
+		NextHop:     route.NextHop,
+		Cost:        route.Cost,
+		Path:        append([]string(nil), route.Path...),
+	}
 }
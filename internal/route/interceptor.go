@@ -0,0 +1,81 @@
+package route
+
+import (
+	"context"
+	"log"
+
+	pb "spfnet/proto"
+)
+
+// InterceptPoint 拦截器的调用位置
+type InterceptPoint int
+
+const (
+	InterceptOutbound InterceptPoint = iota // 本节点发出数据包时（源节点）
+	InterceptForward                        // 转发其他节点的数据包前（中间节点）
+	InterceptDeliver                        // 数据包投递给本节点的业务层前（目的节点）
+)
+
+// String 返回调用位置名称
+func (p InterceptPoint) String() string {
+	switch p {
+	case InterceptOutbound:
+		return "outbound"
+	case InterceptForward:
+		return "forward"
+	case InterceptDeliver:
+		return "deliver"
+	default:
+		return "unknown"
+	}
+}
+
+// Interceptor 数据包拦截器，在发送、转发和投递业务数据包（单播和多播）时按注册顺序调用
+// 可以直接修改数据包（载荷、元数据等）；返回错误时丢弃数据包，
+// 源节点收到 ADMIN_PROHIBITED 差错。控制报文、追踪包、字节流分段和 UDP 数据报不经过拦截器
+type Interceptor interface {
+	Intercept(ctx context.Context, point InterceptPoint, packet *pb.Packet) error
+}
+
+// InterceptorFunc 函数形式的拦截器
+type InterceptorFunc func(ctx context.Context, point InterceptPoint, packet *pb.Packet) error
+
+// Intercept 调用函数本身
+func (f InterceptorFunc) Intercept(ctx context.Context, point InterceptPoint, packet *pb.Packet) error {
+	return f(ctx, point, packet)
+}
+
+// SetInterceptors 设置拦截器链（替换已有的拦截器）
+func (fm *ForwardManager) SetInterceptors(interceptors []Interceptor) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.interceptors = append([]Interceptor(nil), interceptors...)
+}
+
+// AddInterceptor 在拦截器链末尾追加拦截器
+func (fm *ForwardManager) AddInterceptor(interceptor Interceptor) {
+	fm.policyMtx.Lock()
+	defer fm.policyMtx.Unlock()
+	fm.interceptors = append(fm.interceptors[:len(fm.interceptors):len(fm.interceptors)], interceptor)
+}
+
+// intercept 依次调用拦截器，任一拦截器返回错误时记录丢包并返回差错
+func (fm *ForwardManager) intercept(ctx context.Context, point InterceptPoint, packet *pb.Packet) *ControlError {
+	if packet.Trace || (packet.Type != pb.PacketType_PACKET_TYPE_DATA && packet.Type != pb.PacketType_PACKET_TYPE_MULTICAST) {
+		return nil
+	}
+
+	fm.policyMtx.RLock()
+	interceptors := fm.interceptors
+	fm.policyMtx.RUnlock()
+
+	for _, interceptor := range interceptors {
+		if err := interceptor.Intercept(ctx, point, packet); err != nil {
+			log.Printf("[%s] ✗ Packet %s dropped by %s interceptor: %v", fm.nodeID, packet.PacketId, point, err)
+			fm.recordDrop(packet, "", DropReasonIntercepted)
+			return newControlError(pb.ControlCode_CONTROL_CODE_ADMIN_PROHIBITED, fm.nodeID, packet,
+				"dropped by %s interceptor at %s: %v", point, fm.nodeID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// linkProbeTimeout 单次链路探测的超时时间
+	linkProbeTimeout = 5 * time.Second
+	// linkProbeThreshold 重新探测的成本相对变化超过该比例时才更新链路，避免探测抖动引起频繁的路由重算
+	linkProbeThreshold = 0.2
+	// linkProbeSamples 每次探测测量 RTT 的次数
+	linkProbeSamples = 3
+	// linkProbeMinCost 探测得到的最小链路成本，RTT 低于 1ms 的链路成本相同，避免亚毫秒抖动改变路由
+	linkProbeMinCost = 1.0
+)
+
+// SetTLS 设置探测邻居节点时使用的 TLS 配置
+func (ts *TopologySync) SetTLS(t *NodeTLS) {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+	ts.tls = t
+}
+
+// SetProbeInterval 设置自动探测链路的重新探测间隔，0 表示不重新探测（需在 Start 之前设置）
+func (ts *TopologySync) SetProbeInterval(interval time.Duration) {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+	ts.probeInterval = interval
+}
+
+// getProbeInterval 获取自动探测链路的重新探测间隔
+func (ts *TopologySync) getProbeInterval() time.Duration {
+	ts.mtx.RLock()
+	defer ts.mtx.RUnlock()
+	return ts.probeInterval
+}
+
+// AddLocalLink 添加本节点到邻居节点的链路并广播到集群
+// autoProbe 为 true 或 cost <= 0 时探测链路成本（链路随周期性探测更新），否则将成本固定为 cost
+// 返回最终的链路成本和是否手动固定
+func (ts *TopologySync) AddLocalLink(ctx context.Context, neighborID, neighborAddr string, cost float64, autoProbe bool) (float64, bool, error) {
+	// 添加邻居节点到拓扑，保留通过集群成员信息得到的服务列表、多播组、订阅的主题和公钥
+	ts.topology.AddNode(&NodeInfo{
+		ID:        neighborID,
+		RPCAddr:   neighborAddr,
+		Status:    NodeStatusUnknown,
+		Services:  ts.topology.GetNodeServices(neighborID),
+		Groups:    ts.topology.GetNodeGroups(neighborID),
+		Topics:    ts.topology.GetNodeTopics(neighborID),
+		PublicKey: ts.topology.GetNodePublicKey(neighborID),
+	})
+
+	pinned := !autoProbe && cost > 0
+	if !pinned {
+		probedCost, err := ts.probeLinkCost(ctx, neighborID, neighborAddr)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to probe link: %w", err)
+		}
+		cost = probedCost
+		log.Printf("[%s] Auto-probed link cost to %s: %.2f", ts.node.ID, neighborID, cost)
+	}
+
+	ts.setLocalLink(neighborID, cost, pinned)
+	return cost, pinned, nil
+}
+
+// SetLocalLinkCost 修改本节点到邻居节点的链路成本并广播到集群
+// autoProbe 为 true 时重新探测成本并恢复为探测模式，否则使用 cost 并将链路标记为手动固定
+// 返回最终的链路成本和是否手动固定
+func (ts *TopologySync) SetLocalLinkCost(ctx context.Context, neighborID string, cost float64, autoProbe bool) (float64, bool, error) {
+	if _, exists := ts.topology.GetCost(ts.node.ID, neighborID); !exists {
+		return 0, false, fmt.Errorf("link %s-%s not found", ts.node.ID, neighborID)
+	}
+
+	if autoProbe {
+		neighborNode := ts.topology.GetNode(neighborID)
+		if neighborNode == nil {
+			return 0, false, fmt.Errorf("neighbor node %s not found", neighborID)
+		}
+
+		probedCost, err := ts.probeLinkCost(ctx, neighborID, neighborNode.GRPCAddress())
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to probe link: %w", err)
+		}
+		cost = probedCost
+		log.Printf("[%s] Auto-probed link cost to %s: %.2f", ts.node.ID, neighborID, cost)
+	} else if cost <= 0 {
+		return 0, false, fmt.Errorf("cost must be positive when auto probe is disabled")
+	}
+
+	ts.setLocalLink(neighborID, cost, !autoProbe)
+	return cost, !autoProbe, nil
+}
+
+// RemoveLocalLink 删除本节点到邻居节点的链路并广播到集群
+func (ts *TopologySync) RemoveLocalLink(neighborID string) error {
+	if _, exists := ts.topology.GetCost(ts.node.ID, neighborID); !exists {
+		return fmt.Errorf("link %s-%s not found", ts.node.ID, neighborID)
+	}
+
+	// 广播失败时本地拓扑已更新，全量同步会把删除传播出去
+	if err := ts.UnregisterLink(ts.node.ID, neighborID); err != nil {
+		log.Printf("[%s] Warning: Failed to broadcast link removal: %v", ts.node.ID, err)
+	}
+	return nil
+}
+
+// setLocalLink 以新版本更新本节点到邻居的链路并广播
+// 广播失败时本地拓扑已更新，全量同步会把变更传播出去，只记录警告
+func (ts *TopologySync) setLocalLink(neighborID string, cost float64, pinned bool) {
+	if err := ts.UpdateLinkCost(ts.node.ID, neighborID, cost, pinned); err != nil {
+		log.Printf("[%s] Warning: Failed to broadcast link update: %v", ts.node.ID, err)
+	}
+}
+
+// linkProbeLoop 定期重新探测本节点的自动探测链路
+func (ts *TopologySync) linkProbeLoop(interval time.Duration) {
+	defer ts.node.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ts.node.ctx.Done():
+			return
+		case <-ticker.C:
+			ts.reprobeLinks()
+		}
+	}
+}
+
+// reprobeLinks 重新探测本节点发起的未固定链路，成本变化超过 linkProbeThreshold 且超过 linkProbeMinCost 时更新并广播
+// 手动固定的链路和由邻居节点最后设置的链路保持不变
+func (ts *TopologySync) reprobeLinks() {
+	for neighborID, oldCost := range ts.topology.GetNeighbors(ts.node.ID) {
+		meta, ok := ts.topology.GetLinkMeta(ts.node.ID, neighborID)
+		if !ok || meta.Pinned || meta.Origin != ts.node.ID {
+			continue
+		}
+		neighborNode := ts.topology.GetNode(neighborID)
+		if neighborNode == nil {
+			continue
+		}
+
+		cost, err := ts.probeLinkCost(ts.node.ctx, neighborID, neighborNode.GRPCAddress())
+		if err != nil {
+			log.Printf("[%s] Failed to re-probe link to %s: %v", ts.node.ID, neighborID, err)
+			continue
+		}
+		if math.Abs(cost-oldCost) <= math.Max(oldCost*linkProbeThreshold, linkProbeMinCost) {
+			continue
+		}
+
+		// 探测期间链路可能已被手动固定或删除
+		if current, ok := ts.topology.GetLinkMeta(ts.node.ID, neighborID); !ok || current != meta {
+			continue
+		}
+
+		log.Printf("[%s] Re-probed link cost to %s: %.2f -> %.2f", ts.node.ID, neighborID, oldCost, cost)
+		ts.setLocalLink(neighborID, cost, false)
+	}
+}
+
+// probeLinkCost 探测到邻居节点的链路成本
+// 建立连接后连续 Ping 邻居 linkProbeSamples 次，取最小 RTT（毫秒）作为成本，最小为 linkProbeMinCost
+func (ts *TopologySync) probeLinkCost(ctx context.Context, neighborID, address string) (float64, error) {
+	ts.mtx.RLock()
+	nodeTLS := ts.tls
+	ts.mtx.RUnlock()
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(nodeTLS.ClientCredentials(neighborID)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	probeCtx, cancel := context.WithTimeout(ctx, linkProbeTimeout)
+	defer cancel()
+
+	// 第一次 Ping 建立连接（包括 TLS 握手），不计入 RTT
+	client := pb.NewNodeServiceClient(conn)
+	if _, err := client.Ping(probeCtx, &pb.PingRequest{Msg: ts.node.ID}); err != nil {
+		return 0, fmt.Errorf("probe request failed: %w", err)
+	}
+
+	var minRTT time.Duration
+	for i := 0; i < linkProbeSamples; i++ {
+		start := time.Now()
+		if _, err := client.Ping(probeCtx, &pb.PingRequest{Msg: ts.node.ID}); err != nil {
+			return 0, fmt.Errorf("probe request failed: %w", err)
+		}
+		if rtt := time.Since(start); i == 0 || rtt < minRTT {
+			minRTT = rtt
+		}
+	}
+
+	return math.Max(linkProbeMinCost, float64(minRTT.Microseconds())/1000), nil
+}
@@ -0,0 +1,425 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// BroadcastGroup 广播使用的组名，集群中所有节点都视为该组成员
+const BroadcastGroup = "*"
+
+// ErrNoGroupMembers 多播组中没有可达的成员
+var ErrNoGroupMembers = errors.New("no reachable group members")
+
+// MulticastResult 多播发送结果
+type MulticastResult struct {
+	PacketID string   // 数据包 ID
+	Group    string   // 多播组名（广播时为 BroadcastGroup，按主题发布时为空）
+	Topic    string   // 发布的主题（按主题发布时）
+	Targets  []string // 发送时可达的所有目标节点
+	Failed   []string // 未能投递的目标节点
+}
+
+// Delivered 返回已确认投递的目标节点
+func (r *MulticastResult) Delivered() []string {
+	failed := make(map[string]bool, len(r.Failed))
+	for _, id := range r.Failed {
+		failed[id] = true
+	}
+
+	var delivered []string
+	for _, id := range r.Targets {
+		if !failed[id] {
+			delivered = append(delivered, id)
+		}
+	}
+	return delivered
+}
+
+// multicastBranch 从本节点出发经同一下一跳到达的一组目标
+type multicastBranch struct {
+	nextHop string
+	targets []*pb.MulticastTarget
+}
+
+// GroupMembers 返回多播组中当前可达的成员（不含本节点，按节点 ID 排序）
+func (fm *ForwardManager) GroupMembers(group string) []string {
+	var candidates []string
+	if group == BroadcastGroup {
+		for _, node := range fm.topology.GetAllNodes() {
+			candidates = append(candidates, node.ID)
+		}
+	} else {
+		candidates = fm.topology.GetGroupMembers(group)
+	}
+
+	var members []string
+	for _, id := range candidates {
+		if id == fm.nodeID {
+			continue
+		}
+		if _, err := fm.routeManager.GetRoute(id); err != nil {
+			continue
+		}
+		members = append(members, id)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// SendMulticast 向多播组的所有可达成员发送数据包
+// 源节点按路由表中的最短路径构建以自身为根的分发树，数据包只在分支处复制，
+// 共享链路上只传输一份。多播始终同步转发，不经过存储转发队列和持久化发件箱
+func (fm *ForwardManager) SendMulticast(ctx context.Context, group string, payload []byte) (*MulticastResult, error) {
+	packet := fm.newMulticastPacket(multicastDestination(group), payload)
+	packet.Group = group
+
+	result, err := fm.sendMulticast(ctx, packet, fm.GroupMembers(group))
+	if err == nil && len(result.Targets) == 0 {
+		err = fmt.Errorf("%w: %s", ErrNoGroupMembers, group)
+	}
+	return result, err
+}
+
+// SendBroadcast 向集群中所有可达节点发送数据包
+func (fm *ForwardManager) SendBroadcast(ctx context.Context, payload []byte) (*MulticastResult, error) {
+	return fm.SendMulticast(ctx, BroadcastGroup, payload)
+}
+
+// newMulticastPacket 创建多播数据包（调用方设置 group 或 topic）
+func (fm *ForwardManager) newMulticastPacket(destination string, payload []byte) *pb.Packet {
+	return &pb.Packet{
+		Source:       fm.nodeID,
+		Destination:  destination,
+		PacketId:     fmt.Sprintf("mcast-%s-%d", fm.nodeID, time.Now().UnixNano()),
+		Payload:      payload,
+		VisitedNodes: []string{fm.nodeID},
+		Type:         pb.PacketType_PACKET_TYPE_MULTICAST,
+		Ttl:          fm.getDefaultTTL(),
+	}
+}
+
+// sendMulticast 向 members 中的可达节点发送多播数据包
+// members 包含本节点时直接在本地投递；没有可达目标时返回的结果中 Targets 为空
+func (fm *ForwardManager) sendMulticast(ctx context.Context, packet *pb.Packet, members []string) (*MulticastResult, error) {
+	result := &MulticastResult{
+		PacketID: packet.PacketId,
+		Group:    packet.Group,
+		Topic:    packet.Topic,
+	}
+
+	local := false
+	for _, id := range members {
+		if id == fm.nodeID {
+			local = true
+			result.Targets = append(result.Targets, id)
+			continue
+		}
+		route, err := fm.routeManager.GetRoute(id)
+		if err != nil {
+			continue
+		}
+		packet.MulticastTargets = append(packet.MulticastTargets, &pb.MulticastTarget{
+			Destination: id,
+			Path:        append([]string(nil), route.Path...),
+		})
+		result.Targets = append(result.Targets, id)
+	}
+	if len(result.Targets) == 0 {
+		return result, nil
+	}
+
+	log.Printf("[%s] Multicasting packet %s to %s (%d targets)",
+		fm.nodeID, packet.PacketId, multicastLabel(packet), len(result.Targets))
+
+	fm.recordSent(packet)
+
+	if cerr := fm.checkPolicy(packet); cerr != nil {
+		log.Printf("[%s] ✗ Packet %s rejected: %v", fm.nodeID, packet.PacketId, cerr)
+		fm.recordDrop(packet, "", dropReasonForControl(cerr))
+		result.Failed = append(result.Failed, result.Targets...)
+		return result, cerr
+	}
+	if cerr := fm.intercept(ctx, InterceptOutbound, packet); cerr != nil {
+		result.Failed = append(result.Failed, result.Targets...)
+		return result, cerr
+	}
+	fm.compress(packet)
+
+	if local {
+		fm.deliverMulticast(ctx, packet)
+	}
+	if len(packet.MulticastTargets) > 0 {
+		result.Failed = fm.replicate(ctx, packet)
+	}
+	sort.Strings(result.Failed)
+	return result, nil
+}
+
+// handleMulticastPacket 处理收到的多播数据包：本节点是目标时投递，其余目标按分支继续复制转发
+func (fm *ForwardManager) handleMulticastPacket(ctx context.Context, packet *pb.Packet) *pb.ForwardResponse {
+	var remaining []*pb.MulticastTarget
+	for _, target := range packet.MulticastTargets {
+		if target.Destination == fm.nodeID {
+			fm.deliverMulticast(ctx, packet)
+			continue
+		}
+		remaining = append(remaining, target)
+	}
+	packet.MulticastTargets = remaining
+
+	var failed []string
+	if len(remaining) > 0 {
+		// TTL 为 0 表示未设置，使用默认值
+		if packet.Ttl == 0 {
+			packet.Ttl = fm.getDefaultTTL()
+		}
+		packet.Ttl--
+
+		if packet.Ttl == 0 {
+			log.Printf("[%s] ✗ Packet %s TTL exceeded", fm.nodeID, packet.PacketId)
+			fm.recordDrop(packet, "", DropReasonTTLExceeded)
+			failed = multicastDestinations(remaining)
+		} else if cerr := fm.intercept(ctx, InterceptForward, packet); cerr != nil {
+			failed = multicastDestinations(remaining)
+		} else {
+			failed = fm.replicate(ctx, packet)
+		}
+	}
+
+	if len(failed) > 0 {
+		return &pb.ForwardResponse{
+			Success:            false,
+			Message:            fmt.Sprintf("%d multicast targets failed", len(failed)),
+			FailedDestinations: failed,
+		}
+	}
+	return &pb.ForwardResponse{
+		Success: true,
+		Message: "Multicast packet forwarded",
+	}
+}
+
+// deliverMulticast 将多播数据包投递到本节点（本节点已离开该组或取消订阅该主题时不投递）
+func (fm *ForwardManager) deliverMulticast(ctx context.Context, packet *pb.Packet) {
+	if !fm.acceptsMulticast(packet) {
+		log.Printf("[%s] Multicast packet %s for %s ignored: not a member",
+			fm.nodeID, packet.PacketId, multicastLabel(packet))
+		return
+	}
+
+	if !fm.reserveDelivery(packet) {
+		log.Printf("[%s] Duplicate packet %s from %s suppressed",
+			fm.nodeID, packet.PacketId, packet.Source)
+		fm.stats.mtx.Lock()
+		fm.stats.PacketsDuplicate++
+		fm.stats.mtx.Unlock()
+		return
+	}
+
+	// 拦截器和回调可能修改或持有数据包，交给它们一份独立的副本，不影响继续复制转发的数据包
+	delivered := proto.Clone(packet).(*pb.Packet)
+	delivered.Destination = fm.nodeID
+	delivered.MulticastTargets = nil
+	if err := fm.decompress(delivered); err != nil {
+		log.Printf("[%s] ✗ Multicast packet %s from %s could not be decompressed: %v",
+			fm.nodeID, packet.PacketId, packet.Source, err)
+		fm.recordDrop(packet, "", DropReasonDecompressFailed)
+		fm.releaseDelivery(packet)
+		return
+	}
+	if cerr := fm.intercept(ctx, InterceptDeliver, delivered); cerr != nil {
+		fm.releaseDelivery(packet)
+		return
+	}
+
+	log.Printf("[%s] ✓ Multicast packet %s for %s delivered! Path: %v",
+		fm.nodeID, packet.PacketId, multicastLabel(packet), packet.VisitedNodes)
+
+	fm.recordDelivered(packet)
+
+	fm.policyMtx.RLock()
+	handler := fm.onDeliver
+	fm.policyMtx.RUnlock()
+	if handler != nil {
+		handler(delivered)
+	}
+	fm.confirmDelivery(packet)
+}
+
+// acceptsMulticast 检查本节点是否应接收该多播数据包
+func (fm *ForwardManager) acceptsMulticast(packet *pb.Packet) bool {
+	switch {
+	case packet.Topic != "":
+		return containsString(fm.topology.GetNodeTopics(fm.nodeID), packet.Topic)
+	case packet.Group == BroadcastGroup:
+		return true
+	default:
+		return containsString(fm.topology.GetNodeGroups(fm.nodeID), packet.Group)
+	}
+}
+
+// replicate 将多播数据包按下一跳分组，每个分支发送一份副本，返回未能投递的目标
+func (fm *ForwardManager) replicate(ctx context.Context, packet *pb.Packet) []string {
+	branches, failed := fm.multicastBranches(packet)
+
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	for _, branch := range branches {
+		wg.Add(1)
+		go func(branch *multicastBranch) {
+			defer wg.Done()
+
+			branchFailed := fm.forwardBranch(ctx, packet, branch)
+			mtx.Lock()
+			failed = append(failed, branchFailed...)
+			mtx.Unlock()
+		}(branch)
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// multicastBranches 按下一跳对剩余目标分组
+// 优先沿源节点计算的路径前进，本节点不在路径上时（例如路径已变化）退回本地路由表
+func (fm *ForwardManager) multicastBranches(packet *pb.Packet) ([]*multicastBranch, []string) {
+	fm.policyMtx.RLock()
+	blocked := fm.blockedDestinations
+	fm.policyMtx.RUnlock()
+
+	var failed []string
+	byNextHop := make(map[string]*multicastBranch)
+	var branches []*multicastBranch
+
+	for _, target := range packet.MulticastTargets {
+		if blocked[target.Destination] {
+			log.Printf("[%s] ✗ Multicast to %s prohibited", fm.nodeID, target.Destination)
+			fm.recordDrop(packet, "", DropReasonAdminProhibited)
+			failed = append(failed, target.Destination)
+			continue
+		}
+
+		nextHop := nextHopOnPath(target.Path, fm.nodeID)
+		if nextHop == "" {
+			route, err := fm.routeManager.GetRoute(target.Destination)
+			if err != nil {
+				log.Printf("[%s] ✗ No route to %s: %v", fm.nodeID, target.Destination, err)
+				fm.recordDrop(packet, "", DropReasonNoRoute)
+				failed = append(failed, target.Destination)
+				continue
+			}
+			nextHop = route.NextHop
+			target.Path = append([]string(nil), route.Path...)
+		}
+
+		branch, ok := byNextHop[nextHop]
+		if !ok {
+			branch = &multicastBranch{nextHop: nextHop}
+			byNextHop[nextHop] = branch
+			branches = append(branches, branch)
+		}
+		branch.targets = append(branch.targets, target)
+	}
+
+	return branches, failed
+}
+
+// forwardBranch 向一个分支的下一跳发送数据包副本，返回该分支上未能投递的目标
+func (fm *ForwardManager) forwardBranch(ctx context.Context, packet *pb.Packet, branch *multicastBranch) []string {
+	copyPacket := proto.Clone(packet).(*pb.Packet)
+	copyPacket.NextHop = branch.nextHop
+	copyPacket.MulticastTargets = branch.targets
+
+	all := multicastDestinations(branch.targets)
+
+	nextHopNode := fm.topology.GetNode(branch.nextHop)
+	if nextHopNode == nil {
+		log.Printf("[%s] ✗ Next hop node %s not found", fm.nodeID, branch.nextHop)
+		fm.recordDrop(copyPacket, branch.nextHop, DropReasonNextHopUnknown)
+		return all
+	}
+
+	client, err := fm.getClient(nextHopNode)
+	if err != nil {
+		log.Printf("[%s] ✗ Failed to get client for %s: %v", fm.nodeID, branch.nextHop, err)
+		fm.recordDrop(copyPacket, branch.nextHop, DropReasonNextHopUnreachable)
+		return all
+	}
+
+	fm.recordAttempt(branch.nextHop)
+	start := time.Now()
+	resp, err := client.ForwardPacket(ctx, copyPacket)
+	latency := time.Since(start)
+	if err != nil {
+		log.Printf("[%s] ✗ Failed to forward packet %s: %v", fm.nodeID, copyPacket.PacketId, err)
+		fm.recordDrop(copyPacket, branch.nextHop, DropReasonNextHopUnreachable)
+		return all
+	}
+
+	if !resp.Success {
+		log.Printf("[%s] ✗ Multicast branch via %s failed: %s", fm.nodeID, branch.nextHop, resp.Message)
+		fm.recordDrop(copyPacket, branch.nextHop, DropReasonDownstream)
+		if len(resp.FailedDestinations) > 0 {
+			return resp.FailedDestinations
+		}
+		return all
+	}
+
+	log.Printf("[%s] ✓ Packet %s forwarded to %s (%d targets)",
+		fm.nodeID, copyPacket.PacketId, branch.nextHop, len(branch.targets))
+
+	fm.recordForwarded(copyPacket, branch.nextHop, latency)
+	return nil
+}
+
+// nextHopOnPath 返回路径上 nodeID 之后的节点，nodeID 不在路径上或已是终点时返回空
+func nextHopOnPath(path []string, nodeID string) string {
+	for i, id := range path {
+		if id == nodeID && i+1 < len(path) {
+			return path[i+1]
+		}
+	}
+	return ""
+}
+
+// multicastDestinations 提取多播目标的节点 ID
+func multicastDestinations(targets []*pb.MulticastTarget) []string {
+	ids := make([]string, len(targets))
+	for i, target := range targets {
+		ids[i] = target.Destination
+	}
+	return ids
+}
+
+// multicastDestination 多播数据包的 destination 字段，用于日志和按目标统计
+func multicastDestination(group string) string {
+	return "group:" + group
+}
+
+// multicastLabel 多播数据包的描述，用于日志
+func multicastLabel(packet *pb.Packet) string {
+	if packet.Topic != "" {
+		return "topic " + packet.Topic
+	}
+	return "group " + packet.Group
+}
+
+// containsString 检查列表中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,13 @@ package route
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/serf/serf"
 )
@@ -16,6 +18,23 @@ const (
 	EventTopologySync  = "topology-sync"
 )
 
+// TagServices Serf 标签：节点提供的服务名（逗号分隔）
+const TagServices = "services"
+
+// TagGroups Serf 标签：节点加入的多播组（逗号分隔）
+const TagGroups = "groups"
+
+// TagTopics Serf 标签：节点订阅的主题（逗号分隔）
+const TagTopics = "topics"
+
+// TagPublicKey Serf 标签：节点的端到端加密公钥（X25519，base64 编码）
+const TagPublicKey = "pubkey"
+
+// MaxNameLength 服务名、多播组名和主题名的最大字节数
+// 名称通过 Serf 标签发布，一个节点的全部标签编码后不能超过 memberlist 的 512 字节元数据上限，
+// 其中地址和公钥约占 120 字节，名称总长度超过剩余空间时更新失败
+const MaxNameLength = 64
+
 type NodeStatus int
 
 const (
@@ -26,11 +45,27 @@ const (
 	NodeStatusLeft
 )
 
+func (s NodeStatus) String() string {
+	switch s {
+	case NodeStatusAlive:
+		return "alive"
+	case NodeStatusSuspect:
+		return "suspect"
+	case NodeStatusFailed:
+		return "failed"
+	case NodeStatusLeft:
+		return "left"
+	default:
+		return "unknown"
+	}
+}
+
 type Node struct {
 	NodeInfo
 	// serf 集群
 	serf    *serf.Serf
 	eventCh chan serf.Event
+	tagMtx  sync.Mutex // 串行化服务、多播组和主题的 Serf 标签更新
 
 	topology *Topology
 
@@ -45,23 +80,63 @@ type Topology struct {
 	nodes map[string]*NodeInfo
 	// 边集合 from -> to -> cost
 	edges map[string]map[string]float64
+	// 链路元数据 edgeID -> meta（仅通过带版本号的更新设置）
+	links map[string]*LinkMeta
+	// 已删除链路的墓碑 edgeID -> 删除记录，防止全量同步把已删除的链路加回来
+	tombstones map[string]linkTombstone
+	// 链路版本的 Lamport 时钟，不小于见过的所有版本号
+	clock int64
+}
+
+// linkTombstone 已删除链路的记录
+type linkTombstone struct {
+	from, to  string
+	version   LinkVersion
+	removedAt time.Time // 链路被删除的时间（按各节点转发时携带的经过时长换算为本地时间）
+}
+
+// LinkVersion 链路变更的版本：发起变更的节点上的 Lamport 时钟值和该节点 ID
+// 先比较 Sequence，相同时比较 Origin，并发的变更在所有节点上得到相同的结果
+type LinkVersion struct {
+	Sequence int64
+	Origin   string
+}
+
+// Less 判断版本 v 是否早于 other
+func (v LinkVersion) Less(other LinkVersion) bool {
+	if v.Sequence != other.Sequence {
+		return v.Sequence < other.Sequence
+	}
+	return v.Origin < other.Origin
+}
+
+// LinkMeta 链路元数据
+type LinkMeta struct {
+	LinkVersion
+	Pinned bool // 是否为手动固定的成本（否则为自动探测，由 Origin 节点定期重新探测）
 }
 
 type NodeInfo struct {
-	ID      string
-	IP      string
-	Port    int
-	RPCAddr string // gRPC 地址，格式 "ip:port"
-	Status  NodeStatus
+	ID        string
+	IP        string
+	Port      int
+	RPCAddr   string // gRPC 地址，格式 "ip:port"
+	Status    NodeStatus
+	Services  []string // 节点提供的服务名
+	Groups    []string // 节点加入的多播组
+	Topics    []string // 节点订阅的主题
+	PublicKey []byte   // 端到端加密公钥（X25519），未启用加密时为空
 }
 
 // 链路事件更新
 type LinkUpdateEvent struct {
-	From string  `json:"from"`
-	To   string  `json:"to"`
-	Cost float64 `json:"cost"` // 只在 add/update 时有意义
-	//Sequence int64   `json:"seq"`
-	Op string `json:"op"` // "add", "update", "remove"
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Cost     float64 `json:"cost"` // 只在 add/update 时有意义
+	Sequence int64   `json:"seq"`              // 版本号，0 表示旧版本节点发出的无版本事件
+	Origin   string  `json:"origin,omitempty"` // 发起变更的节点
+	Pinned   bool    `json:"pinned,omitempty"`
+	Op       string  `json:"op"` // "add", "update", "remove"
 }
 
 // 全量拓扑同步事件
@@ -72,9 +147,14 @@ type TopologySyncEvent struct {
 
 // 拓扑链路条目
 type TopologyLinkEntry struct {
-	From string  `json:"from"`
-	To   string  `json:"to"`
-	Cost float64 `json:"cost"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Cost     float64 `json:"cost"`
+	Sequence int64   `json:"seq,omitempty"`
+	Origin   string  `json:"origin,omitempty"`
+	Pinned   bool    `json:"pinned,omitempty"`
+	Removed  bool    `json:"removed,omitempty"` // 墓碑条目：该链路已被删除
+	AgeMs    int64   `json:"age_ms,omitempty"`  // 墓碑条目：删除后经过的毫秒数
 }
 
 func NewNode(name, ip string, port int) *Node {
@@ -94,9 +174,19 @@ func NewNode(name, ip string, port int) *Node {
 
 func NewTopology() *Topology {
 	return &Topology{
-		nodes: make(map[string]*NodeInfo),
-		edges: make(map[string]map[string]float64),
+		nodes:      make(map[string]*NodeInfo),
+		edges:      make(map[string]map[string]float64),
+		links:      make(map[string]*LinkMeta),
+		tombstones: make(map[string]linkTombstone),
+	}
+}
+
+// GRPCAddress 返回节点的 gRPC 地址，RPCAddr 为空时使用 IP:Port
+func (info *NodeInfo) GRPCAddress() string {
+	if info.RPCAddr != "" {
+		return info.RPCAddr
 	}
+	return fmt.Sprintf("%s:%d", info.IP, info.Port)
 }
 
 // 启动节点
@@ -118,12 +208,7 @@ func (n *Node) Start(bindAddr string, joinAddrs []string) error {
 	config.MemberlistConfig.BindPort = bindPort
 	config.EventCh = n.eventCh
 	// 设置节点标签（metadata)
-	config.Tags = map[string]string{
-		"node_id": n.ID,
-		"ip":      n.IP,
-		"port":    fmt.Sprintf("%d", n.Port),
-		"role":    "spf-node",
-	}
+	config.Tags = n.tags()
 
 	// 创建实例
 	s, err := serf.Create(config)
@@ -146,6 +231,176 @@ func (n *Node) Start(bindAddr string, joinAddrs []string) error {
 	return nil
 }
 
+// tags 构造节点的 Serf 标签
+func (n *Node) tags() map[string]string {
+	return n.buildTags(n.GetServices(), n.GetGroups(), n.GetTopics())
+}
+
+// buildTags 用给定的服务、多播组和主题构造节点的 Serf 标签
+func (n *Node) buildTags(services, groups, topics []string) map[string]string {
+	tags := map[string]string{
+		"node_id": n.ID,
+		"ip":      n.IP,
+		"port":    fmt.Sprintf("%d", n.Port),
+		"role":    "spf-node",
+	}
+	if len(services) > 0 {
+		tags[TagServices] = strings.Join(services, ",")
+	}
+	if len(groups) > 0 {
+		tags[TagGroups] = strings.Join(groups, ",")
+	}
+	if len(topics) > 0 {
+		tags[TagTopics] = strings.Join(topics, ",")
+	}
+	if len(n.PublicKey) > 0 {
+		tags[TagPublicKey] = base64.StdEncoding.EncodeToString(n.PublicKey)
+	}
+	return tags
+}
+
+// SetServices 设置本节点提供的服务，已加入集群时通过 Serf 标签通知其他节点
+// Serf 标签更新成功后才修改本地拓扑
+func (n *Node) SetServices(services []string) error {
+	if err := validateNames("service", services); err != nil {
+		return err
+	}
+
+	n.tagMtx.Lock()
+	defer n.tagMtx.Unlock()
+
+	// 启动前自身尚未加入拓扑，只记录下来，启动时写入 Serf 标签
+	if n.serf == nil {
+		n.Services = append([]string(nil), services...)
+		return nil
+	}
+
+	if err := n.setTags(n.buildTags(services, n.GetGroups(), n.GetTopics())); err != nil {
+		return err
+	}
+	n.topology.SetNodeServices(n.ID, services)
+	return nil
+}
+
+// GetServices 获取本节点提供的服务
+func (n *Node) GetServices() []string {
+	if n.serf == nil {
+		return append([]string(nil), n.Services...)
+	}
+	return n.topology.GetNodeServices(n.ID)
+}
+
+// SetGroups 设置本节点加入的多播组，已加入集群时通过 Serf 标签通知其他节点
+// Serf 标签更新成功后才修改本地拓扑
+func (n *Node) SetGroups(groups []string) error {
+	if err := validateNames("group", groups); err != nil {
+		return err
+	}
+
+	n.tagMtx.Lock()
+	defer n.tagMtx.Unlock()
+
+	// 启动前自身尚未加入拓扑，只记录下来，启动时写入 Serf 标签
+	if n.serf == nil {
+		n.Groups = append([]string(nil), groups...)
+		return nil
+	}
+
+	if err := n.setTags(n.buildTags(n.GetServices(), groups, n.GetTopics())); err != nil {
+		return err
+	}
+	n.topology.SetNodeGroups(n.ID, groups)
+	return nil
+}
+
+// GetGroups 获取本节点加入的多播组
+func (n *Node) GetGroups() []string {
+	if n.serf == nil {
+		return append([]string(nil), n.Groups...)
+	}
+	return n.topology.GetNodeGroups(n.ID)
+}
+
+// SetTopics 设置本节点订阅的主题，已加入集群时通过 Serf 标签通知其他节点
+// Serf 标签更新成功后才修改本地拓扑
+func (n *Node) SetTopics(topics []string) error {
+	if err := validateNames("topic", topics); err != nil {
+		return err
+	}
+
+	n.tagMtx.Lock()
+	defer n.tagMtx.Unlock()
+
+	// 启动前自身尚未加入拓扑，只记录下来，启动时写入 Serf 标签
+	if n.serf == nil {
+		n.Topics = append([]string(nil), topics...)
+		return nil
+	}
+
+	if err := n.setTags(n.buildTags(n.GetServices(), n.GetGroups(), topics)); err != nil {
+		return err
+	}
+	n.topology.SetNodeTopics(n.ID, topics)
+	return nil
+}
+
+// setTags 更新本节点的 Serf 标签
+func (n *Node) setTags(tags map[string]string) error {
+	if err := n.serf.SetTags(tags); err != nil {
+		return fmt.Errorf("failed to update serf tags (services, groups and topics share the 512-byte tag limit): %w", err)
+	}
+	return nil
+}
+
+// validateNames 检查服务名、多播组名或主题名：非空、不含逗号和首尾空白、不超过 MaxNameLength 字节
+func validateNames(kind string, names []string) error {
+	for _, name := range names {
+		if name == "" || strings.Contains(name, ",") || strings.TrimSpace(name) != name {
+			return fmt.Errorf("invalid %s name %q", kind, name)
+		}
+		if len(name) > MaxNameLength {
+			return fmt.Errorf("%s name %q exceeds %d bytes", kind, name, MaxNameLength)
+		}
+	}
+	return nil
+}
+
+// GetTopics 获取本节点订阅的主题
+func (n *Node) GetTopics() []string {
+	if n.serf == nil {
+		return append([]string(nil), n.Topics...)
+	}
+	return n.topology.GetNodeTopics(n.ID)
+}
+
+// SetPublicKey 设置本节点的端到端加密公钥，需在启动前调用，启动时写入 Serf 标签
+func (n *Node) SetPublicKey(key []byte) {
+	n.PublicKey = append([]byte(nil), key...)
+}
+
+// parsePublicKey 解析 Serf 标签中的公钥，格式不正确时返回 nil
+func parsePublicKey(tag string) []byte {
+	if tag == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(tag)
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// parseServices 解析 Serf 标签中的名称列表（服务名、多播组名、主题名）
+func parseServices(tag string) []string {
+	var services []string
+	for _, name := range strings.Split(tag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			services = append(services, name)
+		}
+	}
+	return services
+}
+
 // GetTopology 获取节点的拓扑
 func (n *Node) GetTopology() *Topology {
 	return n.topology
@@ -173,6 +428,7 @@ func (t *Topology) RemoveNode(nodeName string) {
 	if ok {
 		for neighbor := range neighbors {
 			delete(t.edges[neighbor], nodeName)
+			delete(t.links, makeEdgeID(nodeName, neighbor))
 		}
 	}
 	// 删除此节点所有的指向的边
@@ -205,6 +461,164 @@ func (t *Topology) RemoveLink(from, to string) {
 	delete(t.edges[to], from)
 }
 
+// NextLinkVersion 为 origin 节点发起的链路变更分配新版本，版本号大于本地见过的所有版本号
+func (t *Topology) NextLinkVersion(origin string) LinkVersion {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.clock++
+	return LinkVersion{Sequence: t.clock, Origin: origin}
+}
+
+// observe 推进 Lamport 时钟（调用方持有写锁）
+func (t *Topology) observe(version LinkVersion) {
+	if version.Sequence > t.clock {
+		t.clock = version.Sequence
+	}
+}
+
+// ApplyLinkUpdate 应用带版本号的链路更新，只有版本不早于本地记录时才生效
+// 返回拓扑是否发生变化
+func (t *Topology) ApplyLinkUpdate(from, to string, cost float64, version LinkVersion, pinned bool) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.observe(version)
+
+	edgeID := makeEdgeID(from, to)
+	if tomb, ok := t.tombstones[edgeID]; ok && !tomb.version.Less(version) {
+		return false
+	}
+
+	meta, hasMeta := t.links[edgeID]
+	if hasMeta && version.Less(meta.LinkVersion) {
+		return false
+	}
+
+	oldCost, exists := t.edges[from][to]
+	if exists && hasMeta && version == meta.LinkVersion && oldCost == cost && meta.Pinned == pinned {
+		return false
+	}
+
+	if t.edges[from] == nil {
+		t.edges[from] = make(map[string]float64)
+	}
+	if t.edges[to] == nil {
+		t.edges[to] = make(map[string]float64)
+	}
+	t.edges[from][to] = cost
+	t.edges[to][from] = cost
+	t.links[edgeID] = &LinkMeta{LinkVersion: version, Pinned: pinned}
+	delete(t.tombstones, edgeID)
+
+	log.Printf("Topology: Updated link %s-%s cost=%f seq=%d origin=%s pinned=%v", from, to, cost, version.Sequence, version.Origin, pinned)
+	return true
+}
+
+// ApplyLinkRemoval 应用带版本号的链路删除，并记录删除时间为 removedAt 的墓碑
+// 返回拓扑是否发生变化
+func (t *Topology) ApplyLinkRemoval(from, to string, version LinkVersion, removedAt time.Time) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.observe(version)
+
+	edgeID := makeEdgeID(from, to)
+	if meta, ok := t.links[edgeID]; ok && version.Less(meta.LinkVersion) {
+		return false
+	}
+	if tomb, ok := t.tombstones[edgeID]; ok && !tomb.version.Less(version) {
+		return false
+	}
+
+	_, existed := t.edges[from][to]
+	delete(t.edges[from], to)
+	delete(t.edges[to], from)
+	delete(t.links, edgeID)
+	t.tombstones[edgeID] = linkTombstone{from: from, to: to, version: version, removedAt: removedAt}
+
+	log.Printf("Topology: Removed link %s-%s seq=%d origin=%s", from, to, version.Sequence, version.Origin)
+	return existed
+}
+
+// GetLinkMeta 获取链路元数据（无版本号的链路返回 false）
+func (t *Topology) GetLinkMeta(from, to string) (LinkMeta, bool) {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	meta, ok := t.links[makeEdgeID(from, to)]
+	if !ok {
+		return LinkMeta{}, false
+	}
+	return *meta, true
+}
+
+// IsLinkRemoved 检查链路是否已被带版本号的删除操作移除
+func (t *Topology) IsLinkRemoved(from, to string) bool {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	_, removed := t.tombstones[makeEdgeID(from, to)]
+	return removed
+}
+
+// GetLinks 获取所有链路（无向图每条边只返回一次），包含已删除链路的墓碑
+func (t *Topology) GetLinks() []TopologyLinkEntry {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	var links []TopologyLinkEntry
+	visited := make(map[string]bool)
+	for from, neighbors := range t.edges {
+		for to, cost := range neighbors {
+			edgeID := makeEdgeID(from, to)
+			if visited[edgeID] {
+				continue
+			}
+			visited[edgeID] = true
+
+			entry := TopologyLinkEntry{From: from, To: to, Cost: cost}
+			if meta, ok := t.links[edgeID]; ok {
+				entry.Sequence = meta.Sequence
+				entry.Origin = meta.Origin
+				entry.Pinned = meta.Pinned
+			}
+			links = append(links, entry)
+		}
+	}
+
+	now := time.Now()
+	for _, tomb := range t.tombstones {
+		links = append(links, TopologyLinkEntry{
+			From:     tomb.from,
+			To:       tomb.to,
+			Sequence: tomb.version.Sequence,
+			Origin:   tomb.version.Origin,
+			Removed:  true,
+			AgeMs:    now.Sub(tomb.removedAt).Milliseconds(),
+		})
+	}
+
+	return links
+}
+
+// ExpireTombstones 清理删除时间早于 ttl 之前的墓碑，返回清理的数量
+// 墓碑过期后，版本号更小的旧链路信息可以再次被学习到，ttl 应覆盖若干个全量同步周期
+func (t *Topology) ExpireTombstones(ttl time.Duration) int {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	expired := 0
+	deadline := time.Now().Add(-ttl)
+	for edgeID, tomb := range t.tombstones {
+		if tomb.removedAt.Before(deadline) {
+			delete(t.tombstones, edgeID)
+			expired++
+		}
+	}
+	return expired
+}
+
 func (t *Topology) GetCost(from, to string) (float64, bool) {
 	t.mtx.RLock()
 	defer t.mtx.RUnlock()
@@ -232,6 +646,126 @@ func (t *Topology) GetNode(nodeID string) *NodeInfo {
 	return t.nodes[nodeID]
 }
 
+// SetNodeServices 设置节点提供的服务
+func (t *Topology) SetNodeServices(nodeID string, services []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if node, ok := t.nodes[nodeID]; ok {
+		node.Services = append([]string(nil), services...)
+	}
+}
+
+// GetNodeServices 获取节点提供的服务
+func (t *Topology) GetNodeServices(nodeID string) []string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	node, ok := t.nodes[nodeID]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), node.Services...)
+}
+
+// GetServiceProviders 获取提供指定服务的所有节点 ID
+func (t *Topology) GetServiceProviders(service string) []string {
+	return t.nodesWith(service, func(node *NodeInfo) []string { return node.Services })
+}
+
+// SetNodeGroups 设置节点加入的多播组
+func (t *Topology) SetNodeGroups(nodeID string, groups []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if node, ok := t.nodes[nodeID]; ok {
+		node.Groups = append([]string(nil), groups...)
+	}
+}
+
+// GetNodeGroups 获取节点加入的多播组
+func (t *Topology) GetNodeGroups(nodeID string) []string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	node, ok := t.nodes[nodeID]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), node.Groups...)
+}
+
+// GetGroupMembers 获取加入指定多播组的所有节点 ID
+func (t *Topology) GetGroupMembers(group string) []string {
+	return t.nodesWith(group, func(node *NodeInfo) []string { return node.Groups })
+}
+
+// SetNodeTopics 设置节点订阅的主题
+func (t *Topology) SetNodeTopics(nodeID string, topics []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if node, ok := t.nodes[nodeID]; ok {
+		node.Topics = append([]string(nil), topics...)
+	}
+}
+
+// GetNodeTopics 获取节点订阅的主题
+func (t *Topology) GetNodeTopics(nodeID string) []string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	node, ok := t.nodes[nodeID]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), node.Topics...)
+}
+
+// SetNodePublicKey 设置节点的端到端加密公钥
+func (t *Topology) SetNodePublicKey(nodeID string, key []byte) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if node, ok := t.nodes[nodeID]; ok {
+		node.PublicKey = append([]byte(nil), key...)
+	}
+}
+
+// GetNodePublicKey 获取节点的端到端加密公钥，节点未知或未启用加密时返回 nil
+func (t *Topology) GetNodePublicKey(nodeID string) []byte {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	node, ok := t.nodes[nodeID]
+	if !ok || len(node.PublicKey) == 0 {
+		return nil
+	}
+	return append([]byte(nil), node.PublicKey...)
+}
+
+// GetTopicSubscribers 获取订阅指定主题的所有节点 ID
+func (t *Topology) GetTopicSubscribers(topic string) []string {
+	return t.nodesWith(topic, func(node *NodeInfo) []string { return node.Topics })
+}
+
+// nodesWith 获取名称列表（由 names 取出）中包含 name 的所有节点 ID
+func (t *Topology) nodesWith(name string, names func(*NodeInfo) []string) []string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	var ids []string
+	for id, node := range t.nodes {
+		for _, n := range names(node) {
+			if n == name {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
 func (t *Topology) GetAllNodes() []*NodeInfo {
 	t.mtx.RLock()
 	defer t.mtx.RUnlock()
@@ -0,0 +1,463 @@
+package route
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 发件箱 fsync 策略
+const (
+	FsyncAlways   = "always"   // 每条记录写入后立即 fsync
+	FsyncInterval = "interval" // 按固定间隔 fsync
+	FsyncNever    = "never"    // 交给操作系统决定
+)
+
+// 预写日志记录类型
+const (
+	outboxRecordPut byte = 1 // 接收一个数据包（数据为序列化后的 Packet）
+	outboxRecordAck byte = 2 // 数据包处理完成（数据为 packet_id）
+)
+
+const (
+	outboxSegmentExt    = ".wal"
+	outboxHeaderSize    = 9 // length(4) + crc32(4) + kind(1)
+	outboxMaxRecordSize = 64 << 20
+)
+
+// Outbox 持久化发件箱
+// 以分段的预写日志记录已接收但尚未处理完成的数据包，节点重启后可重放
+type Outbox struct {
+	dir           string
+	maxBytes      int64
+	segmentBytes  int64
+	fsync         string
+	fsyncInterval time.Duration
+
+	mtx      sync.Mutex
+	segments []*outboxSegment // 按创建顺序排列，最后一个为当前写入段
+	current  *os.File
+	pending  map[string]*outboxEntry
+	nextSeq  uint64
+	dirty    bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// outboxSegment 一个日志段文件
+type outboxSegment struct {
+	id   uint64
+	path string
+	size int64
+	live int // 段内尚未确认的数据包数量
+}
+
+// outboxEntry 一个待处理的数据包
+type outboxEntry struct {
+	packet  *pb.Packet
+	segment *outboxSegment
+	seq     uint64
+}
+
+// OpenOutbox 打开（或创建）指定目录下的发件箱，并加载未确认的数据包
+func OpenOutbox(dir string, maxBytes, segmentBytes int64, fsync string, fsyncInterval time.Duration) (*Outbox, error) {
+	switch fsync {
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("invalid fsync policy: %s", fsync)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	ob := &Outbox{
+		dir:           dir,
+		maxBytes:      maxBytes,
+		segmentBytes:  segmentBytes,
+		fsync:         fsync,
+		fsyncInterval: fsyncInterval,
+		pending:       make(map[string]*outboxEntry),
+		stopCh:        make(chan struct{}),
+	}
+
+	if err := ob.load(); err != nil {
+		return nil, err
+	}
+
+	// 删除已全部确认的旧段，并在新段上继续写入
+	ob.compact()
+	if err := ob.rotate(); err != nil {
+		return nil, err
+	}
+
+	if ob.fsync == FsyncInterval && ob.fsyncInterval > 0 {
+		ob.wg.Add(1)
+		go ob.syncLoop()
+	}
+
+	log.Printf("Outbox opened at %s: %d pending packets in %d segments",
+		dir, len(ob.pending), len(ob.segments))
+	return ob, nil
+}
+
+// load 按顺序读取所有日志段，重建待处理数据包集合
+func (ob *Outbox) load() error {
+	entries, err := os.ReadDir(ob.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+
+	var ids []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, outboxSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, outboxSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		seg := &outboxSegment{id: id, path: ob.segmentPath(id)}
+		if err := ob.loadSegment(seg); err != nil {
+			return err
+		}
+		ob.segments = append(ob.segments, seg)
+	}
+	return nil
+}
+
+// loadSegment 读取一个日志段，遇到损坏或截断的记录时丢弃其后的内容
+func (ob *Outbox) loadSegment(seg *outboxSegment) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox segment %s: %w", seg.path, err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		kind, data, n, err := readOutboxRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Outbox: segment %s truncated at offset %d: %v", seg.path, offset, err)
+			if terr := os.Truncate(seg.path, offset); terr != nil {
+				return fmt.Errorf("failed to truncate outbox segment: %w", terr)
+			}
+			break
+		}
+		offset += n
+
+		switch kind {
+		case outboxRecordPut:
+			packet := &pb.Packet{}
+			if err := proto.Unmarshal(data, packet); err != nil {
+				log.Printf("Outbox: skipping undecodable packet in %s: %v", seg.path, err)
+				continue
+			}
+			if old, exists := ob.pending[packet.PacketId]; exists {
+				old.segment.live--
+			}
+			ob.nextSeq++
+			ob.pending[packet.PacketId] = &outboxEntry{packet: packet, segment: seg, seq: ob.nextSeq}
+			seg.live++
+		case outboxRecordAck:
+			if entry, exists := ob.pending[string(data)]; exists {
+				entry.segment.live--
+				delete(ob.pending, string(data))
+			}
+		}
+	}
+
+	seg.size = offset
+	return nil
+}
+
+// readOutboxRecord 读取一条记录，返回类型、数据和占用的字节数
+func readOutboxRecord(r io.Reader) (byte, []byte, int64, error) {
+	var header [outboxHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return 0, nil, 0, io.EOF
+		}
+		return 0, nil, 0, fmt.Errorf("short header: %w", err)
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	kind := header[8]
+	if length > outboxMaxRecordSize {
+		return 0, nil, 0, fmt.Errorf("record too large: %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, 0, fmt.Errorf("short record: %w", err)
+	}
+
+	if outboxChecksum(kind, data) != checksum {
+		return 0, nil, 0, errors.New("checksum mismatch")
+	}
+	return kind, data, int64(outboxHeaderSize + len(data)), nil
+}
+
+func outboxChecksum(kind byte, data []byte) uint32 {
+	crc := crc32.Update(0, crc32.IEEETable, []byte{kind})
+	return crc32.Update(crc, crc32.IEEETable, data)
+}
+
+// Append 持久化一个数据包
+func (ob *Outbox) Append(packet *pb.Packet) error {
+	data, err := proto.Marshal(packet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal packet: %w", err)
+	}
+
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+
+	if err := ob.writeRecord(outboxRecordPut, data); err != nil {
+		return err
+	}
+
+	seg := ob.segments[len(ob.segments)-1]
+	if old, exists := ob.pending[packet.PacketId]; exists {
+		old.segment.live--
+	}
+	ob.nextSeq++
+	ob.pending[packet.PacketId] = &outboxEntry{packet: packet, segment: seg, seq: ob.nextSeq}
+	seg.live++
+
+	return ob.maybeRotate()
+}
+
+// Ack 标记数据包已处理完成（投递成功或已确定失败），重启后不再重放
+func (ob *Outbox) Ack(packetID string) error {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+
+	entry, exists := ob.pending[packetID]
+	if !exists {
+		return nil
+	}
+
+	if err := ob.writeRecord(outboxRecordAck, []byte(packetID)); err != nil {
+		return err
+	}
+
+	entry.segment.live--
+	delete(ob.pending, packetID)
+
+	ob.compact()
+	return ob.maybeRotate()
+}
+
+// Pending 返回所有未确认的数据包（按写入顺序）
+func (ob *Outbox) Pending() []*pb.Packet {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+
+	entries := make([]*outboxEntry, 0, len(ob.pending))
+	for _, entry := range ob.pending {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+	packets := make([]*pb.Packet, 0, len(entries))
+	for _, entry := range entries {
+		packets = append(packets, proto.Clone(entry.packet).(*pb.Packet))
+	}
+	return packets
+}
+
+// Size 返回当前所有日志段的总字节数
+func (ob *Outbox) Size() int64 {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	return ob.totalSize()
+}
+
+// Close 同步并关闭发件箱
+func (ob *Outbox) Close() error {
+	close(ob.stopCh)
+	ob.wg.Wait()
+
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+
+	if ob.current == nil {
+		return nil
+	}
+	if err := ob.current.Sync(); err != nil {
+		log.Printf("Outbox: failed to sync on close: %v", err)
+	}
+	err := ob.current.Close()
+	ob.current = nil
+	return err
+}
+
+// writeRecord 向当前段追加一条记录（调用方持有锁）
+func (ob *Outbox) writeRecord(kind byte, data []byte) error {
+	if ob.current == nil {
+		return errors.New("outbox is closed")
+	}
+
+	buf := make([]byte, outboxHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], outboxChecksum(kind, data))
+	buf[8] = kind
+	copy(buf[outboxHeaderSize:], data)
+
+	if _, err := ob.current.Write(buf); err != nil {
+		return fmt.Errorf("failed to write outbox record: %w", err)
+	}
+	ob.segments[len(ob.segments)-1].size += int64(len(buf))
+
+	if ob.fsync == FsyncAlways {
+		if err := ob.current.Sync(); err != nil {
+			return fmt.Errorf("failed to sync outbox: %w", err)
+		}
+	} else {
+		ob.dirty = true
+	}
+	return nil
+}
+
+// maybeRotate 当前段写满时切换到新段，并执行容量限制（调用方持有锁）
+func (ob *Outbox) maybeRotate() error {
+	if ob.segments[len(ob.segments)-1].size < ob.segmentBytes {
+		return nil
+	}
+	if err := ob.rotate(); err != nil {
+		return err
+	}
+	ob.enforceRetention()
+	return nil
+}
+
+// rotate 关闭当前段并创建新段（调用方持有锁）
+func (ob *Outbox) rotate() error {
+	if ob.current != nil {
+		if err := ob.current.Sync(); err != nil {
+			log.Printf("Outbox: failed to sync segment: %v", err)
+		}
+		ob.current.Close()
+		ob.current = nil
+	}
+
+	var id uint64 = 1
+	if len(ob.segments) > 0 {
+		id = ob.segments[len(ob.segments)-1].id + 1
+	}
+
+	seg := &outboxSegment{id: id, path: ob.segmentPath(id)}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox segment: %w", err)
+	}
+
+	ob.current = f
+	ob.segments = append(ob.segments, seg)
+	ob.compact()
+	return nil
+}
+
+// compact 从最旧的段开始删除已全部确认的段（调用方持有锁）
+// 只删除前缀，保证后续段中的确认记录不会早于其对应的写入记录被删除
+func (ob *Outbox) compact() {
+	for len(ob.segments) > 1 && ob.segments[0].live == 0 {
+		ob.removeOldestSegment()
+	}
+}
+
+// enforceRetention 总大小超过上限时删除最旧的段，其中未确认的数据包将被丢弃（调用方持有锁）
+func (ob *Outbox) enforceRetention() {
+	if ob.maxBytes <= 0 {
+		return
+	}
+
+	for len(ob.segments) > 1 && ob.totalSize() > ob.maxBytes {
+		seg := ob.segments[0]
+		dropped := 0
+		for id, entry := range ob.pending {
+			if entry.segment == seg {
+				delete(ob.pending, id)
+				dropped++
+			}
+		}
+		if dropped > 0 {
+			log.Printf("Outbox: size limit %d bytes exceeded, dropped %d pending packets from %s",
+				ob.maxBytes, dropped, seg.path)
+		}
+		ob.removeOldestSegment()
+	}
+}
+
+// removeOldestSegment 删除最旧的段文件（调用方持有锁）
+func (ob *Outbox) removeOldestSegment() {
+	seg := ob.segments[0]
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Outbox: failed to remove segment %s: %v", seg.path, err)
+	}
+	ob.segments = ob.segments[1:]
+}
+
+func (ob *Outbox) totalSize() int64 {
+	var total int64
+	for _, seg := range ob.segments {
+		total += seg.size
+	}
+	return total
+}
+
+func (ob *Outbox) segmentPath(id uint64) string {
+	return filepath.Join(ob.dir, fmt.Sprintf("%016d%s", id, outboxSegmentExt))
+}
+
+// syncLoop 按间隔 fsync 当前段
+func (ob *Outbox) syncLoop() {
+	defer ob.wg.Done()
+
+	ticker := time.NewTicker(ob.fsyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ob.stopCh:
+			return
+		case <-ticker.C:
+			ob.mtx.Lock()
+			if ob.dirty && ob.current != nil {
+				if err := ob.current.Sync(); err != nil {
+					log.Printf("Outbox: failed to sync: %v", err)
+				}
+				ob.dirty = false
+			}
+			ob.mtx.Unlock()
+		}
+	}
+}
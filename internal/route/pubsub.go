@@ -0,0 +1,47 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoSubscribers 主题没有可达的订阅者
+var ErrNoSubscribers = errors.New("no reachable topic subscribers")
+
+// TopicSubscribers 返回订阅指定主题且当前可达的节点（含本节点，按节点 ID 排序）
+func (fm *ForwardManager) TopicSubscribers(topic string) []string {
+	var subscribers []string
+	for _, id := range fm.topology.GetTopicSubscribers(topic) {
+		if id != fm.nodeID {
+			if _, err := fm.routeManager.GetRoute(id); err != nil {
+				continue
+			}
+		}
+		subscribers = append(subscribers, id)
+	}
+	sort.Strings(subscribers)
+	return subscribers
+}
+
+// Publish 向订阅指定主题的所有可达节点发送数据包
+// 分发方式与多播相同（沿最短路径树在分支处复制），本节点订阅了该主题时也会在本地投递；
+// 每个订阅者的投递结果计入按订阅者细分的统计
+func (fm *ForwardManager) Publish(ctx context.Context, topic string, payload []byte) (*MulticastResult, error) {
+	packet := fm.newMulticastPacket(topicDestination(topic), payload)
+	packet.Topic = topic
+
+	result, err := fm.sendMulticast(ctx, packet, fm.TopicSubscribers(topic))
+	if err == nil && len(result.Targets) == 0 {
+		return result, fmt.Errorf("%w: %s", ErrNoSubscribers, topic)
+	}
+
+	fm.recordPublish(topic, result, len(payload))
+	return result, err
+}
+
+// topicDestination 按主题发布的数据包的 destination 字段，用于日志和按目标统计
+func topicDestination(topic string) string {
+	return "topic:" + topic
+}
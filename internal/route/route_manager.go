@@ -9,7 +9,7 @@ import (
 	pb "spfnet/proto"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 )
 
 // RouteManager 路由管理器
@@ -18,6 +18,8 @@ type RouteManager struct {
 	topology   *Topology
 	routeTable *RouteTable
 	spfCalc    *SPFCalculator
+	events     *EventBus
+	tls        *NodeTLS // 为 nil 时使用明文连接
 	mtx        sync.RWMutex
 }
 
@@ -31,23 +33,54 @@ func NewRouteManager(nodeID string, topology *Topology) *RouteManager {
 	}
 }
 
+// SetEventBus 设置路由变化事件的发布目标
+func (rm *RouteManager) SetEventBus(bus *EventBus) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	rm.events = bus
+}
+
+// SetTLS 设置连接其他节点时使用的 TLS 配置
+func (rm *RouteManager) SetTLS(t *NodeTLS) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	rm.tls = t
+}
+
+// clientCredentials 返回连接指定节点时使用的传输凭据
+func (rm *RouteManager) clientCredentials(nodeID string) credentials.TransportCredentials {
+	rm.mtx.RLock()
+	defer rm.mtx.RUnlock()
+	return rm.tls.ClientCredentials(nodeID)
+}
+
 // RecomputeRoutes 重新计算路由表
 func (rm *RouteManager) RecomputeRoutes() error {
 	rm.mtx.Lock()
-	defer rm.mtx.Unlock()
 
 	log.Printf("[%s] Recomputing routes...", rm.nodeID)
 
 	// 使用 SPF 算法计算路由
 	newRouteTable, err := rm.spfCalc.ComputeRoutes(rm.nodeID, rm.topology)
 	if err != nil {
+		rm.mtx.Unlock()
 		return fmt.Errorf("failed to compute routes: %w", err)
 	}
 
 	// 更新路由表
+	oldRouteTable := rm.routeTable
 	rm.routeTable = newRouteTable
+	events := rm.events
 
 	log.Printf("[%s] Route table updated:\n%s", rm.nodeID, rm.routeTable.String())
+	rm.mtx.Unlock()
+
+	// 发布路由变化事件
+	if events != nil {
+		for _, event := range diffRoutes(oldRouteTable.GetAllRoutes(), newRouteTable.GetAllRoutes()) {
+			events.Publish(event)
+		}
+	}
 	return nil
 }
 
@@ -79,7 +112,7 @@ func (rm *RouteManager) GetNextHop(destination string) (string, error) {
 func (rm *RouteManager) GetGRPCClient(nodeInfo *NodeInfo) (pb.NodeServiceClient, *grpc.ClientConn, error) {
 	addr := fmt.Sprintf("%s:%d", nodeInfo.IP, nodeInfo.Port)
 	
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(rm.clientCredentials(nodeInfo.ID)))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to dial %s: %w", addr, err)
 	}
@@ -107,7 +140,7 @@ func (rm *RouteManager) SendPacket(ctx context.Context, packet *pb.Packet) error
 
 	// 连接到下一跳节点
 	addr := fmt.Sprintf("%s:%d", nextHopNode.IP, nextHopNode.Port)
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(rm.clientCredentials(nextHopNode.ID)))
 	if err != nil {
 		return fmt.Errorf("failed to connect to %s: %w", addr, err)
 	}
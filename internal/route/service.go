@@ -0,0 +1,85 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// ErrServiceUnavailable 没有可达的服务提供者
+var ErrServiceUnavailable = errors.New("service unavailable")
+
+// ServiceProviders 返回提供指定服务且当前可达的节点，按路由成本从低到高排序（不含本节点）
+func (fm *ForwardManager) ServiceProviders(service string) []string {
+	type candidate struct {
+		id   string
+		cost float64
+	}
+
+	var candidates []candidate
+	for _, id := range fm.topology.GetServiceProviders(service) {
+		if id == fm.nodeID {
+			continue
+		}
+		route, err := fm.routeManager.GetRoute(id)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, cost: route.Cost})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].cost != candidates[j].cost {
+			return candidates[i].cost < candidates[j].cost
+		}
+		return candidates[i].id < candidates[j].id
+	})
+
+	providers := make([]string, len(candidates))
+	for i, c := range candidates {
+		providers[i] = c.id
+	}
+	return providers
+}
+
+// SendToService 将数据包发送给最近的服务提供者
+// 提供者不可达时依次改投下一个较近的提供者，返回最终接收数据包的节点 ID
+func (fm *ForwardManager) SendToService(ctx context.Context, service string, payload []byte) (string, error) {
+	return fm.SendToServiceWithOptions(ctx, service, payload, SendOptions{})
+}
+
+// SendToServiceWithOptions 使用指定选项将数据包发送给最近的服务提供者（opts.Service 被替换为 service）
+func (fm *ForwardManager) SendToServiceWithOptions(ctx context.Context, service string, payload []byte, opts SendOptions) (string, error) {
+	opts.Service = service
+	providers := fm.ServiceProviders(service)
+	if len(providers) == 0 {
+		return "", fmt.Errorf("%w: no reachable provider for %s", ErrServiceUnavailable, service)
+	}
+
+	var lastErr error
+	for _, provider := range providers {
+		err := fm.SendPacketWithOptions(ctx, provider, payload, opts)
+		if err == nil {
+			return provider, nil
+		}
+
+		// 只有目标不可达时才改投，其他差错（策略禁止、载荷过大等）对所有提供者都一样
+		if !errors.Is(err, ErrDestinationUnreachable) || ctx.Err() != nil {
+			return provider, err
+		}
+
+		log.Printf("[%s] Service %s provider %s unreachable, failing over: %v",
+			fm.nodeID, service, provider, err)
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("%w: all %d providers of %s failed: %v",
+		ErrServiceUnavailable, len(providers), service, lastErr)
+}
+
+// providesService 检查本节点是否提供指定服务
+func (fm *ForwardManager) providesService(service string) bool {
+	return containsString(fm.topology.GetNodeServices(fm.nodeID), service)
+}
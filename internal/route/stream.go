@@ -0,0 +1,453 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// 字节流默认参数
+const (
+	DefaultStreamMSS        = 16 * 1024       // 单个分段的最大载荷字节数
+	DefaultStreamWindow     = 256 * 1024      // 接收窗口（同时也是发送缓冲区）大小
+	DefaultStreamRTO        = 1 * time.Second // 初始重传超时
+	DefaultStreamMaxRetries = 8               // 连续重传次数上限，超过后连接失败
+	DefaultStreamBacklog    = 128             // 每个监听端口等待 Accept 的连接数上限
+
+	maxStreamRTO      = 30 * time.Second
+	streamTick        = 50 * time.Millisecond
+	streamSendTimeout = 5 * time.Second
+
+	ephemeralPortMin = 49152
+	ephemeralPortMax = 65535
+)
+
+var (
+	// ErrConnectionRefused 目标端口没有监听者或等待队列已满
+	ErrConnectionRefused = errors.New("connection refused")
+	// ErrConnectionReset 连接被对端重置
+	ErrConnectionReset = errors.New("connection reset by peer")
+	// ErrStreamTimeout 重传次数耗尽仍未收到确认
+	ErrStreamTimeout = errors.New("stream retransmission timeout")
+	// ErrPortInUse 端口已被监听
+	ErrPortInUse = errors.New("port already in use")
+)
+
+// StreamAddr 字节流端点地址，由节点 ID 和端口组成，实现 net.Addr
+type StreamAddr struct {
+	Node string
+	Port uint32
+}
+
+// Network 返回网络名
+func (a StreamAddr) Network() string { return "spfnet" }
+
+// String 返回 "节点:端口" 形式的地址
+func (a StreamAddr) String() string {
+	return net.JoinHostPort(a.Node, strconv.FormatUint(uint64(a.Port), 10))
+}
+
+// streamKey 在本节点上唯一标识一条连接
+type streamKey struct {
+	peer       string
+	localPort  uint32
+	remotePort uint32
+}
+
+// StreamManager 在覆盖网络之上提供可靠、有序、带流量控制的字节流
+// 分段作为 STREAM 类型的数据包逐跳转发；丢失、乱序和重复由两端的序号和确认处理，
+// 中间节点和持久化发件箱不参与
+type StreamManager struct {
+	nodeID string
+	fm     *ForwardManager
+
+	mtx       sync.Mutex
+	listeners map[uint32]*StreamListener
+	conns     map[streamKey]*StreamConn
+	nextPort  uint32
+	closed    bool
+
+	segmentSeq atomic.Uint64
+
+	mss        int
+	window     int
+	rto        time.Duration
+	maxRetries int
+	backlog    int
+}
+
+// NewStreamManager 创建字节流管理器并注册为转发管理器的分段处理器
+func NewStreamManager(nodeID string, fm *ForwardManager) *StreamManager {
+	sm := &StreamManager{
+		nodeID:     nodeID,
+		fm:         fm,
+		listeners:  make(map[uint32]*StreamListener),
+		conns:      make(map[streamKey]*StreamConn),
+		nextPort:   ephemeralPortMin + uint32(rand.Intn(ephemeralPortMax-ephemeralPortMin+1)),
+		mss:        DefaultStreamMSS,
+		window:     DefaultStreamWindow,
+		rto:        DefaultStreamRTO,
+		maxRetries: DefaultStreamMaxRetries,
+		backlog:    DefaultStreamBacklog,
+	}
+	fm.SetStreamHandler(sm.handlePacket)
+	return sm
+}
+
+// SetConfig 设置字节流参数，值为 0 的参数保持默认值
+func (sm *StreamManager) SetConfig(cfg StreamConfig) {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+
+	if cfg.MSS > 0 {
+		sm.mss = cfg.MSS
+	}
+	if cfg.WindowSize > 0 {
+		sm.window = cfg.WindowSize
+	}
+	if cfg.RTOMs > 0 {
+		sm.rto = time.Duration(cfg.RTOMs) * time.Millisecond
+	}
+	if cfg.MaxRetries > 0 {
+		sm.maxRetries = cfg.MaxRetries
+	}
+	if cfg.AcceptBacklog > 0 {
+		sm.backlog = cfg.AcceptBacklog
+	}
+}
+
+// segmentSize 返回分段载荷上限，不超过本节点的最大载荷限制
+func (sm *StreamManager) segmentSize() int {
+	sm.fm.policyMtx.RLock()
+	limit := sm.fm.maxPayloadSize
+	sm.fm.policyMtx.RUnlock()
+
+	sm.mtx.Lock()
+	mss := sm.mss
+	sm.mtx.Unlock()
+
+	// 加密后载荷会变长，为其预留空间
+	if limit > 0 && sm.fm.getEncryption() != nil {
+		limit -= EncryptionOverhead
+	}
+	if limit > 0 && mss > limit {
+		return limit
+	}
+	return mss
+}
+
+// Listen 在指定端口监听传入连接
+func (sm *StreamManager) Listen(port uint32) (*StreamListener, error) {
+	if port == 0 || port > ephemeralPortMax {
+		return nil, fmt.Errorf("invalid port %d", port)
+	}
+
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+
+	if sm.closed {
+		return nil, net.ErrClosed
+	}
+	if _, exists := sm.listeners[port]; exists {
+		return nil, fmt.Errorf("%w: %d", ErrPortInUse, port)
+	}
+
+	l := &StreamListener{
+		sm:    sm,
+		port:  port,
+		conns: make(chan *StreamConn, sm.backlog),
+		done:  make(chan struct{}),
+	}
+	sm.listeners[port] = l
+
+	log.Printf("[%s] ✓ Stream listener on port %d", sm.nodeID, port)
+	return l, nil
+}
+
+// Dial 与目的节点指定端口建立连接，直到对端确认或 ctx 结束
+func (sm *StreamManager) Dial(ctx context.Context, destination string, port uint32) (*StreamConn, error) {
+	if port == 0 || port > ephemeralPortMax {
+		return nil, fmt.Errorf("invalid port %d", port)
+	}
+	if destination != sm.nodeID {
+		if _, err := sm.fm.routeManager.GetRoute(destination); err != nil {
+			return nil, fmt.Errorf("%w: no route to %s: %v", ErrDestinationUnreachable, destination, err)
+		}
+	}
+
+	sm.mtx.Lock()
+	if sm.closed {
+		sm.mtx.Unlock()
+		return nil, net.ErrClosed
+	}
+	localPort, err := sm.allocatePortLocked(destination, port)
+	if err != nil {
+		sm.mtx.Unlock()
+		return nil, err
+	}
+	key := streamKey{peer: destination, localPort: localPort, remotePort: port}
+	conn := sm.newConnLocked(key, rand.Uint64(), true)
+	sm.conns[key] = conn
+	sm.mtx.Unlock()
+
+	go conn.run()
+
+	if err := conn.waitEstablished(ctx); err != nil {
+		conn.abort("dial canceled")
+		return nil, err
+	}
+
+	log.Printf("[%s] ✓ Stream connected %s -> %s", sm.nodeID, conn.LocalAddr(), conn.RemoteAddr())
+	return conn, nil
+}
+
+// allocatePortLocked 分配一个未被占用的临时端口
+func (sm *StreamManager) allocatePortLocked(peer string, remotePort uint32) (uint32, error) {
+	for i := 0; i <= ephemeralPortMax-ephemeralPortMin; i++ {
+		port := sm.nextPort
+		sm.nextPort++
+		if sm.nextPort > ephemeralPortMax {
+			sm.nextPort = ephemeralPortMin
+		}
+
+		if _, listening := sm.listeners[port]; listening {
+			continue
+		}
+		if _, used := sm.conns[streamKey{peer: peer, localPort: port, remotePort: remotePort}]; used {
+			continue
+		}
+		return port, nil
+	}
+	return 0, errors.New("no ephemeral port available")
+}
+
+// newConnLocked 创建连接（调用方持有 sm.mtx）
+func (sm *StreamManager) newConnLocked(key streamKey, connID uint64, dialer bool) *StreamConn {
+	c := &StreamConn{
+		sm:         sm,
+		key:        key,
+		connID:     connID,
+		dialer:     dialer,
+		window:     sm.window,
+		baseRTO:    sm.rto,
+		rto:        sm.rto,
+		maxRetries: sm.maxRetries,
+		ooo:        make(map[uint64][]byte),
+		changed:    make(chan struct{}),
+		kick:       make(chan struct{}, 1),
+		closed:     make(chan struct{}),
+		sem:        make(chan struct{}, 16),
+	}
+	if dialer {
+		c.state = streamSynSent
+	} else {
+		c.state = streamEstablished
+		c.synAckPending = true
+	}
+	return c
+}
+
+// handlePacket 处理到达本节点的字节流分段
+func (sm *StreamManager) handlePacket(packet *pb.Packet) {
+	seg := packet.Stream
+	if seg == nil {
+		return
+	}
+
+	key := streamKey{peer: packet.Source, localPort: seg.DstPort, remotePort: seg.SrcPort}
+
+	sm.mtx.Lock()
+	conn := sm.conns[key]
+
+	if seg.Type == pb.StreamSegmentType_STREAM_SEGMENT_TYPE_SYN {
+		// 重复的 SYN：重发 SYN_ACK
+		if conn != nil && conn.connID == seg.ConnId {
+			sm.mtx.Unlock()
+			conn.handleSegment(seg, packet.Payload)
+			return
+		}
+
+		l := sm.listeners[seg.DstPort]
+		if l == nil || sm.closed {
+			sm.mtx.Unlock()
+			sm.reject(packet.Source, seg, "no listener")
+			return
+		}
+
+		// 同一四元组上的旧连接已被对端放弃
+		if conn != nil {
+			delete(sm.conns, key)
+			go conn.fail(ErrConnectionReset)
+		}
+
+		conn = sm.newConnLocked(key, seg.ConnId, false)
+		conn.sndWnd = seg.Window
+		if !l.enqueue(conn) {
+			sm.mtx.Unlock()
+			log.Printf("[%s] ✗ Stream port %d accept backlog full, refusing %s", sm.nodeID, seg.DstPort, packet.Source)
+			sm.reject(packet.Source, seg, "accept backlog full")
+			return
+		}
+		sm.conns[key] = conn
+		sm.mtx.Unlock()
+
+		go conn.run()
+		return
+	}
+	sm.mtx.Unlock()
+
+	if conn == nil || conn.connID != seg.ConnId {
+		if seg.Type != pb.StreamSegmentType_STREAM_SEGMENT_TYPE_RST {
+			sm.reject(packet.Source, seg, "unknown connection")
+		}
+		return
+	}
+	conn.handleSegment(seg, packet.Payload)
+}
+
+// reject 对无法处理的分段回复 RST
+func (sm *StreamManager) reject(peer string, seg *pb.StreamSegment, reason string) {
+	rst := &pb.StreamSegment{
+		SrcPort: seg.DstPort,
+		DstPort: seg.SrcPort,
+		ConnId:  seg.ConnId,
+		Type:    pb.StreamSegmentType_STREAM_SEGMENT_TYPE_RST,
+		Reason:  reason,
+	}
+	go sm.send(peer, rst, nil)
+}
+
+// send 将分段封装为数据包发往对端，目的节点为本节点时直接在本地处理
+func (sm *StreamManager) send(peer string, seg *pb.StreamSegment, data []byte) error {
+	packet := &pb.Packet{
+		Source:       sm.nodeID,
+		Destination:  peer,
+		PacketId:     fmt.Sprintf("stm-%s-%d-%d", sm.nodeID, time.Now().UnixNano(), sm.segmentSeq.Add(1)),
+		Payload:      data,
+		VisitedNodes: []string{sm.nodeID},
+		Type:         pb.PacketType_PACKET_TYPE_STREAM,
+		Ttl:          sm.fm.getDefaultTTL(),
+		Stream:       seg,
+	}
+
+	if peer == sm.nodeID {
+		sm.handlePacket(packet)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamSendTimeout)
+	defer cancel()
+
+	sm.fm.recordSent(packet)
+	if err := sm.fm.seal(packet); err != nil {
+		return err
+	}
+	return sm.fm.forwardPacket(ctx, packet)
+}
+
+// remove 从连接表中移除已关闭的连接
+func (sm *StreamManager) remove(c *StreamConn) {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+	if sm.conns[c.key] == c {
+		delete(sm.conns, c.key)
+	}
+}
+
+// removeListener 从监听表中移除监听者
+func (sm *StreamManager) removeListener(l *StreamListener) {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+	if sm.listeners[l.port] == l {
+		delete(sm.listeners, l.port)
+	}
+}
+
+// Close 关闭所有监听者并重置所有连接
+func (sm *StreamManager) Close() {
+	sm.mtx.Lock()
+	sm.closed = true
+	listeners := make([]*StreamListener, 0, len(sm.listeners))
+	for _, l := range sm.listeners {
+		listeners = append(listeners, l)
+	}
+	conns := make([]*StreamConn, 0, len(sm.conns))
+	for _, c := range sm.conns {
+		conns = append(conns, c)
+	}
+	sm.mtx.Unlock()
+
+	for _, l := range listeners {
+		l.Close()
+	}
+	for _, c := range conns {
+		c.abort("node shutting down")
+	}
+}
+
+// StreamListener 监听某个端口的传入连接，实现 net.Listener
+type StreamListener struct {
+	sm    *StreamManager
+	port  uint32
+	conns chan *StreamConn
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Accept 等待并返回下一个传入连接
+func (l *StreamListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close 停止监听，尚未被 Accept 的连接会被重置
+func (l *StreamListener) Close() error {
+	l.once.Do(func() {
+		l.sm.removeListener(l)
+		close(l.done)
+		for {
+			select {
+			case c := <-l.conns:
+				c.abort("listener closed")
+			default:
+				log.Printf("[%s] Stream listener on port %d closed", l.sm.nodeID, l.port)
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// Addr 返回监听地址
+func (l *StreamListener) Addr() net.Addr {
+	return StreamAddr{Node: l.sm.nodeID, Port: l.port}
+}
+
+// enqueue 将新连接放入等待队列（调用方持有 sm.mtx），监听已关闭或队列已满时返回 false
+func (l *StreamListener) enqueue(c *StreamConn) bool {
+	select {
+	case <-l.done:
+		return false
+	default:
+	}
+
+	select {
+	case l.conns <- c:
+		return true
+	default:
+		return false
+	}
+}
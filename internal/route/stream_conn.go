@@ -0,0 +1,645 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// streamState 连接状态
+type streamState int
+
+const (
+	streamSynSent     streamState = iota // 已发出 SYN，等待 SYN_ACK
+	streamEstablished                    // 已建立，可双向传输
+	streamClosed                         // 已关闭或失败
+)
+
+// outSegment 待发送的分段
+type outSegment struct {
+	seg  *pb.StreamSegment
+	data []byte
+}
+
+// StreamConn 覆盖网络上的一条字节流连接，实现 net.Conn
+// 发送方按对端通告的窗口发送分段，超时后从最早未确认的字节开始重传（回退 N）；
+// 接收方缓存乱序分段，按序交付并对每个数据分段回复累计确认
+type StreamConn struct {
+	sm     *StreamManager
+	key    streamKey
+	connID uint64
+	dialer bool
+
+	mtx     sync.Mutex
+	state   streamState
+	err     error         // 连接失败原因
+	changed chan struct{} // 状态变化时关闭并替换，用于唤醒阻塞的读写
+	kick    chan struct{} // 通知发送循环立即检查待发分段
+	closed  chan struct{} // 进入 streamClosed 时关闭
+	sem     chan struct{} // 限制并发发送的分段数
+
+	// 发送方向
+	sndBuf        []byte // 未确认和未发送的数据，首字节序号为 sndUna
+	sndUna        uint64 // 最早未确认的序号
+	sndNxt        uint64 // 下一个要发送的序号
+	sndWnd        uint32 // 对端通告的接收窗口
+	finQueued     bool   // 本端已关闭写方向
+	finSent       bool
+	finAcked      bool
+	synAckPending bool
+	rstPending    bool
+	rstReason     string
+	lastSyn       time.Time
+	lastProgress  time.Time // 最近一次确认推进或开始计时的时间
+	lastProbe     time.Time
+	retries       int
+	baseRTO       time.Duration
+	rto           time.Duration
+	maxRetries    int
+
+	// 接收方向
+	window     int               // 本端接收窗口大小
+	rcvNxt     uint64            // 期望收到的下一个序号
+	rcvBuf     []byte            // 已按序到达、尚未被读取的数据
+	ooo        map[uint64][]byte // 乱序到达的分段
+	oooBytes   int
+	peerFin    bool
+	peerFinSeq uint64
+	rcvFin     bool // 对端数据已全部按序到达
+	ackPending bool
+	advWnd     uint32 // 最近一次通告的窗口
+	timeWait   time.Time
+
+	userClosed    bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// Read 读取按序到达的数据，对端关闭写方向且数据读完后返回 io.EOF
+func (c *StreamConn) Read(b []byte) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for {
+		if c.userClosed {
+			return 0, net.ErrClosed
+		}
+		if len(c.rcvBuf) > 0 {
+			n := copy(b, c.rcvBuf)
+			c.rcvBuf = c.rcvBuf[n:]
+			c.maybeUpdateWindowLocked()
+			return n, nil
+		}
+		if c.rcvFin {
+			return 0, io.EOF
+		}
+		if c.err != nil {
+			return 0, c.err
+		}
+		if c.state == streamClosed {
+			return 0, net.ErrClosed
+		}
+		if err := c.waitLocked(c.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write 将数据放入发送缓冲区，缓冲区满时阻塞直到对端确认
+func (c *StreamConn) Write(b []byte) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	written := 0
+	for written < len(b) {
+		switch {
+		case c.userClosed:
+			return written, net.ErrClosed
+		case c.err != nil:
+			return written, c.err
+		case c.finQueued:
+			return written, io.ErrClosedPipe
+		case c.state == streamClosed:
+			return written, net.ErrClosed
+		}
+
+		if space := c.window - len(c.sndBuf); space > 0 {
+			n := min(space, len(b)-written)
+			c.sndBuf = append(c.sndBuf, b[written:written+n]...)
+			written += n
+			c.kickLocked()
+			continue
+		}
+
+		if err := c.waitLocked(c.writeDeadline); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// CloseWrite 关闭写方向，已缓冲的数据发送完毕后通知对端 EOF
+func (c *StreamConn) CloseWrite() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.userClosed {
+		return net.ErrClosed
+	}
+	if c.err != nil {
+		return c.err
+	}
+	c.finQueued = true
+	c.kickLocked()
+	return nil
+}
+
+// Close 关闭连接，已缓冲的数据在后台继续发送
+func (c *StreamConn) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.userClosed {
+		return nil
+	}
+	c.userClosed = true
+	c.rcvBuf = nil
+
+	switch c.state {
+	case streamClosed:
+	case streamSynSent:
+		c.rstPending = true
+		c.rstReason = "connection closed"
+		c.failLocked(net.ErrClosed)
+	default:
+		c.finQueued = true
+		c.notifyLocked()
+		c.kickLocked()
+	}
+	return nil
+}
+
+// LocalAddr 返回本端地址
+func (c *StreamConn) LocalAddr() net.Addr {
+	return StreamAddr{Node: c.sm.nodeID, Port: c.key.localPort}
+}
+
+// RemoteAddr 返回对端地址
+func (c *StreamConn) RemoteAddr() net.Addr {
+	return StreamAddr{Node: c.key.peer, Port: c.key.remotePort}
+}
+
+// SetDeadline 同时设置读写截止时间
+func (c *StreamConn) SetDeadline(t time.Time) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.readDeadline = t
+	c.writeDeadline = t
+	c.notifyLocked()
+	return nil
+}
+
+// SetReadDeadline 设置读截止时间
+func (c *StreamConn) SetReadDeadline(t time.Time) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.readDeadline = t
+	c.notifyLocked()
+	return nil
+}
+
+// SetWriteDeadline 设置写截止时间
+func (c *StreamConn) SetWriteDeadline(t time.Time) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.writeDeadline = t
+	c.notifyLocked()
+	return nil
+}
+
+// waitLocked 释放锁等待状态变化或截止时间到达（调用方持有 c.mtx）
+func (c *StreamConn) waitLocked(deadline time.Time) error {
+	ch := c.changed
+	if deadline.IsZero() {
+		c.mtx.Unlock()
+		<-ch
+		c.mtx.Lock()
+		return nil
+	}
+
+	d := time.Until(deadline)
+	if d <= 0 {
+		return os.ErrDeadlineExceeded
+	}
+
+	c.mtx.Unlock()
+	defer c.mtx.Lock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ch:
+		return nil
+	case <-timer.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+// waitEstablished 等待握手完成
+func (c *StreamConn) waitEstablished(ctx context.Context) error {
+	for {
+		c.mtx.Lock()
+		state, err, ch := c.state, c.err, c.changed
+		c.mtx.Unlock()
+
+		switch {
+		case err != nil:
+			return err
+		case state == streamEstablished:
+			return nil
+		case state == streamClosed:
+			return net.ErrClosed
+		}
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// notifyLocked 唤醒所有等待状态变化的读写方
+func (c *StreamConn) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// kickLocked 通知发送循环
+func (c *StreamConn) kickLocked() {
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+// finishLocked 进入关闭状态
+func (c *StreamConn) finishLocked() {
+	if c.state == streamClosed {
+		return
+	}
+	c.state = streamClosed
+	close(c.closed)
+	c.notifyLocked()
+}
+
+// failLocked 以错误结束连接
+func (c *StreamConn) failLocked(err error) {
+	if c.state == streamClosed {
+		return
+	}
+	c.err = err
+	c.finishLocked()
+}
+
+// fail 以错误结束连接
+func (c *StreamConn) fail(err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.failLocked(err)
+}
+
+// abort 向对端发送 RST 并立即结束连接
+func (c *StreamConn) abort(reason string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.state == streamClosed {
+		return
+	}
+	c.rstPending = true
+	c.rstReason = reason
+	c.failLocked(net.ErrClosed)
+}
+
+// recvWindowLocked 返回当前可通告的接收窗口
+func (c *StreamConn) recvWindowLocked() uint32 {
+	free := c.window - len(c.rcvBuf) - c.oooBytes
+	if free < 0 {
+		return 0
+	}
+	return uint32(free)
+}
+
+// maybeUpdateWindowLocked 读取腾出足够空间后主动通告新窗口，避免发送方等待零窗口探测
+func (c *StreamConn) maybeUpdateWindowLocked() {
+	wnd := c.recvWindowLocked()
+	if wnd > c.advWnd && int(wnd-c.advWnd) >= c.window/4 {
+		c.ackPending = true
+		c.kickLocked()
+	}
+}
+
+// handleSegment 处理对端发来的分段
+func (c *StreamConn) handleSegment(seg *pb.StreamSegment, data []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.state == streamClosed {
+		return
+	}
+
+	switch seg.Type {
+	case pb.StreamSegmentType_STREAM_SEGMENT_TYPE_RST:
+		if c.state == streamSynSent {
+			log.Printf("[%s] ✗ Stream connection to %s refused: %s", c.sm.nodeID, c.RemoteAddr(), seg.Reason)
+			c.failLocked(fmt.Errorf("%w: %s: %s", ErrConnectionRefused, c.RemoteAddr(), seg.Reason))
+			return
+		}
+		log.Printf("[%s] ✗ Stream %s reset by peer: %s", c.sm.nodeID, c.RemoteAddr(), seg.Reason)
+		c.failLocked(fmt.Errorf("%w: %s", ErrConnectionReset, seg.Reason))
+		return
+	case pb.StreamSegmentType_STREAM_SEGMENT_TYPE_SYN:
+		if !c.dialer {
+			c.synAckPending = true
+			c.kickLocked()
+		}
+		return
+	}
+
+	// 握手完成；对端的数据分段可能先于 SYN_ACK 到达，同样视为握手完成
+	if c.state == streamSynSent {
+		c.state = streamEstablished
+		c.sndWnd = seg.Window
+		c.retries = 0
+		c.rto = c.baseRTO
+		c.notifyLocked()
+	}
+
+	c.processAckLocked(seg)
+
+	switch seg.Type {
+	case pb.StreamSegmentType_STREAM_SEGMENT_TYPE_DATA:
+		if len(data) > 0 {
+			c.receiveLocked(seg.Seq, data)
+		}
+		// 空数据分段是零窗口探测，同样回复确认
+		c.ackPending = true
+	case pb.StreamSegmentType_STREAM_SEGMENT_TYPE_FIN:
+		if !c.peerFin {
+			c.peerFin = true
+			c.peerFinSeq = seg.Seq
+		}
+		c.ackPending = true
+	}
+
+	if c.peerFin && !c.rcvFin && c.rcvNxt == c.peerFinSeq {
+		c.rcvFin = true
+		c.rcvNxt++
+	}
+
+	c.kickLocked()
+	c.notifyLocked()
+}
+
+// processAckLocked 处理分段携带的累计确认和窗口通告
+func (c *StreamConn) processAckLocked(seg *pb.StreamSegment) {
+	if seg.Ack < c.sndUna {
+		return
+	}
+	c.sndWnd = seg.Window
+	if seg.Ack == c.sndUna {
+		return
+	}
+
+	dataEnd := c.sndUna + uint64(len(c.sndBuf))
+	acked := min(seg.Ack, dataEnd) - c.sndUna
+	c.sndBuf = c.sndBuf[acked:]
+	c.sndUna += acked
+	if c.finQueued && seg.Ack > dataEnd {
+		c.finAcked = true
+	}
+	if c.sndNxt < c.sndUna {
+		c.sndNxt = c.sndUna
+	}
+
+	c.retries = 0
+	c.rto = c.baseRTO
+	c.lastProgress = time.Now()
+}
+
+// receiveLocked 接收数据分段：按序数据进入读缓冲区，乱序数据暂存到窗口内
+func (c *StreamConn) receiveLocked(seq uint64, data []byte) {
+	end := seq + uint64(len(data))
+	if end <= c.rcvNxt {
+		return
+	}
+	if end > c.rcvNxt+uint64(c.window) {
+		return
+	}
+
+	if seq > c.rcvNxt {
+		if _, exists := c.ooo[seq]; !exists {
+			c.ooo[seq] = data
+			c.oooBytes += len(data)
+		}
+		return
+	}
+
+	c.appendLocked(data[c.rcvNxt-seq:])
+
+	// 合并已经连续的乱序分段
+	for progressed := true; progressed; {
+		progressed = false
+		for s, d := range c.ooo {
+			e := s + uint64(len(d))
+			if s > c.rcvNxt {
+				continue
+			}
+			delete(c.ooo, s)
+			c.oooBytes -= len(d)
+			if e > c.rcvNxt {
+				c.appendLocked(d[c.rcvNxt-s:])
+				progressed = true
+			}
+		}
+	}
+}
+
+// appendLocked 追加按序到达的数据，本端已关闭时丢弃
+func (c *StreamConn) appendLocked(data []byte) {
+	if !c.userClosed {
+		c.rcvBuf = append(c.rcvBuf, data...)
+	}
+	c.rcvNxt += uint64(len(data))
+}
+
+// run 发送循环：发送新数据、重传超时分段、回复确认，连接结束后从管理器移除
+func (c *StreamConn) run() {
+	defer c.sm.remove(c)
+
+	ticker := time.NewTicker(streamTick)
+	defer ticker.Stop()
+
+	for {
+		segs, done := c.collect(time.Now(), c.sm.segmentSize())
+		c.transmit(segs)
+		if done {
+			return
+		}
+
+		select {
+		case <-c.kick:
+		case <-ticker.C:
+		case <-c.closed:
+		}
+	}
+}
+
+// collect 根据当前状态生成待发送的分段，连接结束时 done 为 true
+func (c *StreamConn) collect(now time.Time, mss int) (segs []outSegment, done bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	add := func(typ pb.StreamSegmentType, seq uint64, data []byte) {
+		c.advWnd = c.recvWindowLocked()
+		segs = append(segs, outSegment{
+			seg: &pb.StreamSegment{
+				SrcPort: c.key.localPort,
+				DstPort: c.key.remotePort,
+				ConnId:  c.connID,
+				Type:    typ,
+				Seq:     seq,
+				Ack:     c.rcvNxt,
+				Window:  c.advWnd,
+			},
+			data: data,
+		})
+	}
+
+	if c.state == streamClosed {
+		if c.rstPending {
+			c.rstPending = false
+			add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_RST, c.sndNxt, nil)
+			segs[len(segs)-1].seg.Reason = c.rstReason
+		}
+		return segs, true
+	}
+
+	if c.state == streamSynSent {
+		if !c.lastSyn.IsZero() && now.Sub(c.lastSyn) < c.rto {
+			return nil, false
+		}
+		if !c.lastSyn.IsZero() {
+			if c.retries >= c.maxRetries {
+				c.failLocked(fmt.Errorf("%w: no answer from %s", ErrStreamTimeout, c.RemoteAddr()))
+				return nil, true
+			}
+			c.retries++
+			c.backoffLocked()
+		}
+		c.lastSyn = now
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_SYN, 0, nil)
+		return segs, false
+	}
+
+	if c.synAckPending {
+		c.synAckPending = false
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_SYN_ACK, 0, nil)
+	}
+
+	// 重传超时：从最早未确认的字节开始重发
+	outstanding := c.sndNxt > c.sndUna || (c.finSent && !c.finAcked)
+	if outstanding && now.Sub(c.lastProgress) >= c.rto {
+		if c.retries >= c.maxRetries {
+			log.Printf("[%s] ✗ Stream %s timed out after %d retransmissions", c.sm.nodeID, c.RemoteAddr(), c.retries)
+			c.failLocked(fmt.Errorf("%w: %s", ErrStreamTimeout, c.RemoteAddr()))
+			return segs, true
+		}
+		c.retries++
+		c.backoffLocked()
+		c.sndNxt = c.sndUna
+		c.finSent = false
+		c.lastProgress = now
+	}
+
+	// 在对端窗口内发送新数据
+	dataEnd := c.sndUna + uint64(len(c.sndBuf))
+	for c.sndNxt < dataEnd {
+		inflight := c.sndNxt - c.sndUna
+		if inflight >= uint64(c.sndWnd) {
+			break
+		}
+		n := min(dataEnd-c.sndNxt, uint64(mss), uint64(c.sndWnd)-inflight)
+		off := c.sndNxt - c.sndUna
+		data := append([]byte(nil), c.sndBuf[off:off+n]...)
+		if c.sndNxt == c.sndUna {
+			c.lastProgress = now
+		}
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_DATA, c.sndNxt, data)
+		c.sndNxt += n
+	}
+
+	// 对端窗口为零：定期发送空数据分段探测窗口
+	if c.sndWnd == 0 && c.sndNxt == c.sndUna && c.sndNxt < dataEnd && now.Sub(c.lastProbe) >= c.rto {
+		c.lastProbe = now
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_DATA, c.sndNxt, nil)
+	}
+
+	if c.finQueued && !c.finSent && c.sndNxt == dataEnd {
+		if c.sndNxt == c.sndUna {
+			c.lastProgress = now
+		}
+		c.finSent = true
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_FIN, dataEnd, nil)
+	}
+
+	if c.ackPending && len(segs) == 0 {
+		add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_ACK, c.sndNxt, nil)
+	}
+	c.ackPending = false
+
+	// 双方都已关闭写方向：保留一段时间以重新确认对端重传的 FIN
+	if c.finAcked {
+		switch {
+		case c.rcvFin && c.timeWait.IsZero():
+			c.timeWait = now.Add(2 * c.baseRTO)
+		case c.rcvFin && now.After(c.timeWait):
+			c.finishLocked()
+			return segs, true
+		case !c.rcvFin && c.userClosed:
+			// 本端已不再读取，对端后续数据没有意义
+			add(pb.StreamSegmentType_STREAM_SEGMENT_TYPE_RST, c.sndNxt, nil)
+			segs[len(segs)-1].seg.Reason = "connection closed"
+			c.finishLocked()
+			return segs, true
+		}
+	}
+
+	return segs, false
+}
+
+// backoffLocked 重传超时加倍
+func (c *StreamConn) backoffLocked() {
+	c.rto = min(2*c.rto, maxStreamRTO)
+}
+
+// transmit 并发发送分段，发送失败的数据由重传恢复
+func (c *StreamConn) transmit(segs []outSegment) {
+	for _, s := range segs {
+		c.sem <- struct{}{}
+		go func(s outSegment) {
+			defer func() { <-c.sem }()
+			if err := c.sm.send(c.key.peer, s.seg, s.data); err != nil {
+				log.Printf("[%s] ✗ Stream segment %s to %s failed: %v",
+					c.sm.nodeID, s.seg.Type, c.RemoteAddr(), err)
+			}
+		}(s)
+	}
+}
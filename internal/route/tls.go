@@ -0,0 +1,174 @@
+package route
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// 服务端对客户端证书的要求（配置文件中使用）
+const (
+	ClientAuthRequire       = "require"         // 客户端必须提供由 CA 签发的证书（双向 TLS）
+	ClientAuthVerifyIfGiven = "verify_if_given" // 客户端可以不提供证书，提供时必须有效
+	ClientAuthNone          = "none"            // 不要求客户端证书
+)
+
+// NodeTLS 节点 gRPC 服务和客户端使用的 TLS 配置
+// 为 nil 时所有连接使用明文，方法均可在 nil 上调用
+type NodeTLS struct {
+	roots      *x509.CertPool
+	cert       *tls.Certificate // 本节点证书，仅作客户端且服务端不要求证书时可以为空
+	clientAuth tls.ClientAuthType
+}
+
+// LoadNodeTLS 读取 CA 证书和本节点的证书、私钥
+func LoadNodeTLS(cfg TLSConfig) (*NodeTLS, error) {
+	if cfg.CAFile == "" {
+		return nil, errors.New("tls.ca_file is required")
+	}
+	caPEM, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+	}
+
+	t := &NodeTLS{roots: roots}
+	switch strings.ToLower(cfg.ClientAuth) {
+	case "", ClientAuthRequire:
+		t.clientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthVerifyIfGiven:
+		t.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthNone:
+		t.clientAuth = tls.NoClientCert
+	default:
+		return nil, fmt.Errorf("unknown tls.client_auth %q (expected require, verify_if_given or none)", cfg.ClientAuth)
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate: %w", err)
+		}
+		t.cert = &cert
+	}
+	return t, nil
+}
+
+// ServerOptions 返回 gRPC 服务端的 TLS 选项，未启用 TLS 时返回 nil
+func (t *NodeTLS) ServerOptions() []grpc.ServerOption {
+	if t == nil {
+		return nil
+	}
+	cfg := &tls.Config{
+		ClientCAs:  t.roots,
+		ClientAuth: t.clientAuth,
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.cert != nil {
+		cfg.Certificates = []tls.Certificate{*t.cert}
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(cfg))}
+}
+
+// ClientCredentials 返回连接指定节点时使用的传输凭据，未启用 TLS 时使用明文
+// nodeID 不为空时要求服务端证书的主题（CN）或 SAN 与之相同，为空时只校验证书链
+func (t *NodeTLS) ClientCredentials(nodeID string) credentials.TransportCredentials {
+	if t == nil {
+		return insecure.NewCredentials()
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// 证书以节点 ID 而非主机名标识，跳过默认的主机名校验，由 VerifyConnection 校验证书链和节点身份
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			leaf, err := t.verifyChain(cs.PeerCertificates, x509.ExtKeyUsageServerAuth)
+			if err != nil {
+				return err
+			}
+			if nodeID != "" && !certMatchesNode(leaf, nodeID) {
+				return fmt.Errorf("certificate subject %q does not match node %s", leaf.Subject.CommonName, nodeID)
+			}
+			return nil
+		},
+	}
+	if t.cert != nil {
+		cfg.Certificates = []tls.Certificate{*t.cert}
+	}
+	return credentials.NewTLS(cfg)
+}
+
+// verifyChain 校验对端证书链是否由配置的 CA 签发，返回对端证书
+func (t *NodeTLS) verifyChain(certs []*x509.Certificate, usage x509.ExtKeyUsage) (*x509.Certificate, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("peer presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         t.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// certMatchesNode 检查证书是否属于指定节点（主题 CN 或 DNS SAN 与节点 ID 相同）
+func certMatchesNode(cert *x509.Certificate, nodeID string) bool {
+	return cert.Subject.CommonName == nodeID || slices.Contains(cert.DNSNames, nodeID)
+}
+
+// peerCertificate 返回 gRPC 请求方经过校验的客户端证书，明文连接或对方未提供证书时返回 nil
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return info.State.VerifiedChains[0][0]
+}
+
+// verifyPreviousHop 检查转发数据包的请求方是否为数据包记录的上一跳节点
+// 经过其他节点的数据包必须由上一跳节点的证书发来；尚未经过任何节点的数据包由源节点直接发来，证书必须属于源节点。
+// 没有客户端证书时拒绝，没有证书的客户端只能通过控制服务注入数据包。
+// 未启用 TLS 或 client_auth = none 时服务端不索取客户端证书，不校验上一跳身份
+func (t *NodeTLS) verifyPreviousHop(ctx context.Context, packet *pb.Packet) error {
+	if t == nil || t.clientAuth == tls.NoClientCert {
+		return nil
+	}
+
+	role, expected := "source", packet.Source
+	if n := len(packet.VisitedNodes); n > 0 {
+		role, expected = "previous hop", packet.VisitedNodes[n-1]
+	}
+	cert := peerCertificate(ctx)
+	if cert == nil {
+		return fmt.Errorf("packet from %s %s forwarded without a client certificate", role, expected)
+	}
+	if !certMatchesNode(cert, expected) {
+		return fmt.Errorf("peer certificate subject %q does not match %s %s", cert.Subject.CommonName, role, expected)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package route
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	pb "spfnet/proto"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// withPeerCertificate 返回带有指定节点客户端证书的请求上下文
+func withPeerCertificate(nodeID string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: nodeID}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+// 启用客户端证书校验时，所有转发请求都必须带证书：经过其他节点的数据包带上一跳的证书，尚未经过任何节点的数据包带源节点的证书
+func TestVerifyPreviousHopRequiresCertificate(t *testing.T) {
+	forwarded := &pb.Packet{Source: "a", Destination: "c", VisitedNodes: []string{"a", "b"}}
+	injected := &pb.Packet{Source: "a", Destination: "c"}
+	noCert := context.Background()
+	verifyIfGiven := &NodeTLS{clientAuth: tls.VerifyClientCertIfGiven}
+
+	cases := []struct {
+		name    string
+		tls     *NodeTLS
+		ctx     context.Context
+		packet  *pb.Packet
+		wantErr bool
+	}{
+		{"tls disabled", nil, noCert, forwarded, false},
+		{"client_auth none", &NodeTLS{clientAuth: tls.NoClientCert}, noCert, forwarded, false},
+		{"client_auth none, injected", &NodeTLS{clientAuth: tls.NoClientCert}, noCert, injected, false},
+		{"require without cert", &NodeTLS{clientAuth: tls.RequireAndVerifyClientCert}, noCert, forwarded, true},
+		{"verify_if_given without cert", verifyIfGiven, noCert, forwarded, true},
+		{"injected without cert", verifyIfGiven, noCert, injected, true},
+		{"previous hop cert", verifyIfGiven, withPeerCertificate("b"), forwarded, false},
+		{"source cert for forwarded packet", verifyIfGiven, withPeerCertificate("a"), forwarded, true},
+		{"injected with source cert", verifyIfGiven, withPeerCertificate("a"), injected, false},
+		{"injected with other cert", verifyIfGiven, withPeerCertificate("x"), injected, true},
+	}
+	for _, tc := range cases {
+		err := tc.tls.verifyPreviousHop(tc.ctx, tc.packet)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
@@ -1,15 +1,22 @@
 package route
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	pb "spfnet/proto"
+
 	"github.com/hashicorp/serf/serf"
 )
 
+// tombstoneSyncIntervals 链路墓碑保留的全量同步周期数
+// 在此期间每个节点至少有几次全量同步机会把删除传播给错过删除事件的节点
+const tombstoneSyncIntervals = 3
+
 // TopologySync 负责处理拓扑同步和事件传播
 type TopologySync struct {
 	node     *Node
@@ -18,10 +25,17 @@ type TopologySync struct {
 	// 路由计算回调
 	onTopologyChange func()
 
+	// 成员和链路变化事件的发布目标
+	events *EventBus
+
 	// 全量同步配置
 	syncInterval time.Duration // 全量同步间隔
 	syncEnabled  bool          // 是否启用周期性同步
 
+	// 本节点链路的探测配置
+	probeInterval time.Duration // 自动探测链路的重新探测间隔，0 表示不重新探测
+	tls           *NodeTLS      // 探测邻居节点时使用的 TLS 配置，为 nil 时使用明文连接
+
 	mtx sync.RWMutex
 }
 
@@ -42,6 +56,13 @@ func (ts *TopologySync) SetTopologyChangeCallback(callback func()) {
 	ts.onTopologyChange = callback
 }
 
+// SetEventBus 设置成员和链路变化事件的发布目标
+func (ts *TopologySync) SetEventBus(bus *EventBus) {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+	ts.events = bus
+}
+
 // SetSyncInterval 设置全量同步间隔
 func (ts *TopologySync) SetSyncInterval(interval time.Duration) {
 	ts.mtx.Lock()
@@ -80,6 +101,11 @@ func (ts *TopologySync) Start() {
 	ts.node.wg.Add(2)
 	go ts.eventLoop()
 	go ts.periodicSyncLoop()
+
+	if interval := ts.getProbeInterval(); interval > 0 {
+		ts.node.wg.Add(1)
+		go ts.linkProbeLoop(interval)
+	}
 }
 
 // eventLoop 处理 Serf 事件
@@ -115,7 +141,7 @@ func (ts *TopologySync) handleMemberEvent(event serf.MemberEvent) {
 		case serf.EventMemberJoin:
 			ts.handleNodeJoin(member)
 		case serf.EventMemberLeave, serf.EventMemberFailed:
-			ts.handleNodeLeave(member)
+			ts.handleNodeLeave(member, event.EventType() == serf.EventMemberFailed)
 		case serf.EventMemberUpdate:
 			ts.handleNodeUpdate(member)
 		}
@@ -130,29 +156,65 @@ func (ts *TopologySync) handleNodeJoin(member serf.Member) {
 	}
 
 	nodeInfo := &NodeInfo{
-		ID:     member.Name,
-		IP:     member.Tags["ip"],
-		Port:   port,
-		Status: NodeStatusAlive,
+		ID:        member.Name,
+		IP:        member.Tags["ip"],
+		Port:      port,
+		Status:    NodeStatusAlive,
+		Services:  parseServices(member.Tags[TagServices]),
+		Groups:    parseServices(member.Tags[TagGroups]),
+		Topics:    parseServices(member.Tags[TagTopics]),
+		PublicKey: parsePublicKey(member.Tags[TagPublicKey]),
 	}
 
 	ts.topology.AddNode(nodeInfo)
 	log.Printf("Node joined: %s (%s:%d)", member.Name, member.Tags["ip"], port)
 
+	ts.publish(&Event{
+		Type:    pb.EventType_EVENT_TYPE_MEMBER_JOIN,
+		Node:    member.Name,
+		Address: nodeInfo.GRPCAddress(),
+	})
 	ts.triggerTopologyChange()
 }
 
-// handleNodeLeave 处理节点离开事件
-func (ts *TopologySync) handleNodeLeave(member serf.Member) {
+// handleNodeLeave 处理节点离开事件（failed 表示节点失联而非主动离开）
+func (ts *TopologySync) handleNodeLeave(member serf.Member, failed bool) {
+	address := ""
+	if nodeInfo := ts.topology.GetNode(member.Name); nodeInfo != nil {
+		address = nodeInfo.GRPCAddress()
+	}
+
 	ts.topology.RemoveNode(member.Name)
 	log.Printf("Node left: %s", member.Name)
 
+	eventType := pb.EventType_EVENT_TYPE_MEMBER_LEAVE
+	if failed {
+		eventType = pb.EventType_EVENT_TYPE_MEMBER_FAILED
+	}
+	ts.publish(&Event{
+		Type:    eventType,
+		Node:    member.Name,
+		Address: address,
+	})
 	ts.triggerTopologyChange()
 }
 
-// handleNodeUpdate 处理节点更新事件
+// handleNodeUpdate 处理节点更新事件（标签变化，例如提供的服务、加入的多播组、订阅的主题变化）
 func (ts *TopologySync) handleNodeUpdate(member serf.Member) {
-	log.Printf("Node updated: %s", member.Name)
+	services := parseServices(member.Tags[TagServices])
+	groups := parseServices(member.Tags[TagGroups])
+	topics := parseServices(member.Tags[TagTopics])
+	ts.topology.SetNodeServices(member.Name, services)
+	ts.topology.SetNodeGroups(member.Name, groups)
+	ts.topology.SetNodeTopics(member.Name, topics)
+
+	// 公钥来自未认证的 Serf 标签，变化时记录警告以便发现冒充
+	publicKey := parsePublicKey(member.Tags[TagPublicKey])
+	if old := ts.topology.GetNodePublicKey(member.Name); len(old) > 0 && !bytes.Equal(old, publicKey) {
+		log.Printf("[%s] Warning: public key of %s changed", ts.node.ID, member.Name)
+	}
+	ts.topology.SetNodePublicKey(member.Name, publicKey)
+	log.Printf("Node updated: %s (services: %v, groups: %v, topics: %v)", member.Name, services, groups, topics)
 }
 
 // handleUserEvent 处理用户自定义事件（链路更新）
@@ -178,13 +240,39 @@ func (ts *TopologySync) handleUserEvent(event serf.UserEvent) {
 
 // handleLinkUpdate 处理链路更新
 func (ts *TopologySync) handleLinkUpdate(event LinkUpdateEvent) {
+	// 带版本号的事件：只应用比本地更新的变更
+	if event.Sequence > 0 {
+		version := LinkVersion{Sequence: event.Sequence, Origin: event.Origin}
+		changed := false
+		switch event.Op {
+		case "add", "update":
+			changed = ts.applyLinkUpdate(event.From, event.To, event.Cost, version, event.Pinned)
+		case "remove":
+			changed = ts.applyLinkRemoval(event.From, event.To, version, time.Now())
+		}
+		if changed {
+			log.Printf("Link %s: %s-%s cost=%.2f seq=%d origin=%s", event.Op, event.From, event.To, event.Cost, event.Sequence, event.Origin)
+			ts.triggerTopologyChange()
+		}
+		return
+	}
+
+	oldCost, existed := ts.topology.GetCost(event.From, event.To)
 	switch event.Op {
 	case "add", "update":
 		ts.topology.UpdateLink(event.From, event.To, event.Cost)
 		log.Printf("Link updated: %s-%s cost=%.2f", event.From, event.To, event.Cost)
+		if !existed {
+			ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_ADD, event.From, event.To, 0, event.Cost, false)
+		} else if oldCost != event.Cost {
+			ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_UPDATE, event.From, event.To, oldCost, event.Cost, false)
+		}
 	case "remove":
 		ts.topology.RemoveLink(event.From, event.To)
 		log.Printf("Link removed: %s-%s", event.From, event.To)
+		if existed {
+			ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_REMOVE, event.From, event.To, oldCost, 0, false)
+		}
 	}
 
 	ts.triggerTopologyChange()
@@ -206,6 +294,11 @@ func (ts *TopologySync) periodicSyncLoop() {
 		case <-ts.node.ctx.Done():
 			return
 		case <-ticker.C:
+			// 先清理过期的墓碑，避免把过期墓碑再次广播出去
+			if expired := ts.topology.ExpireTombstones(tombstoneSyncIntervals * interval); expired > 0 {
+				log.Printf("[%s] Expired %d link tombstones", ts.node.ID, expired)
+			}
+
 			if err := ts.broadcastFullTopology(); err != nil {
 				log.Printf("[%s] Failed to broadcast topology: %v", ts.node.ID, err)
 			}
@@ -240,26 +333,8 @@ func (ts *TopologySync) broadcastFullTopology() error {
 		return nil
 	}
 
-	// 获取所有链路
-	adj := ts.topology.GetAdjacencyList()
-
-	var links []TopologyLinkEntry
-	visited := make(map[string]bool)
-
-	for from, neighbors := range adj {
-		for to, cost := range neighbors {
-			// 无向图，避免重复（只记录 from < to 的边）
-			edgeID := makeEdgeID(from, to)
-			if !visited[edgeID] {
-				links = append(links, TopologyLinkEntry{
-					From: from,
-					To:   to,
-					Cost: cost,
-				})
-				visited[edgeID] = true
-			}
-		}
-	}
+	// 获取所有链路（包含已删除链路的墓碑）
+	links := ts.topology.GetLinks()
 
 	// 构造全量同步事件
 	syncEvent := TopologySyncEvent{
@@ -295,6 +370,32 @@ func (ts *TopologySync) handleTopologySync(event TopologySyncEvent) {
 
 	// 合并接收到的链路信息
 	for _, link := range event.Links {
+		// 带版本号的链路：按版本号合并
+		if link.Sequence > 0 {
+			version := LinkVersion{Sequence: link.Sequence, Origin: link.Origin}
+			var changed bool
+			if link.Removed {
+				removedAt := time.Now().Add(-time.Duration(link.AgeMs) * time.Millisecond)
+				changed = ts.applyLinkRemoval(link.From, link.To, version, removedAt)
+			} else {
+				changed = ts.applyLinkUpdate(link.From, link.To, link.Cost, version, link.Pinned)
+			}
+			if changed {
+				log.Printf("[%s] Merged link from %s: %s-%s cost=%.2f seq=%d removed=%v",
+					ts.node.ID, event.NodeID, link.From, link.To, link.Cost, link.Sequence, link.Removed)
+				topologyChanged = true
+			}
+			continue
+		}
+
+		// 无版本号的链路不能覆盖带版本号的本地链路或已删除的链路
+		if _, versioned := ts.topology.GetLinkMeta(link.From, link.To); versioned {
+			continue
+		}
+		if ts.topology.IsLinkRemoved(link.From, link.To) {
+			continue
+		}
+
 		// 检查本地是否已有该链路
 		existingCost, exists := ts.topology.GetCost(link.From, link.To)
 
@@ -303,6 +404,7 @@ func (ts *TopologySync) handleTopologySync(event TopologySyncEvent) {
 			ts.topology.UpdateLink(link.From, link.To, link.Cost)
 			log.Printf("[%s] Learned new link from %s: %s-%s cost=%.2f",
 				ts.node.ID, event.NodeID, link.From, link.To, link.Cost)
+			ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_ADD, link.From, link.To, 0, link.Cost, false)
 			topologyChanged = true
 		} else if existingCost != link.Cost {
 			// 链路存在但成本不同，使用较小的成本（或其他策略）
@@ -310,6 +412,7 @@ func (ts *TopologySync) handleTopologySync(event TopologySyncEvent) {
 				ts.topology.UpdateLink(link.From, link.To, link.Cost)
 				log.Printf("[%s] Updated link cost from %s: %s-%s cost=%.2f->%.2f",
 					ts.node.ID, event.NodeID, link.From, link.To, existingCost, link.Cost)
+				ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_UPDATE, link.From, link.To, existingCost, link.Cost, false)
 				topologyChanged = true
 			}
 		}
@@ -321,6 +424,56 @@ func (ts *TopologySync) handleTopologySync(event TopologySyncEvent) {
 	}
 }
 
+// applyLinkUpdate 应用带版本号的链路更新，拓扑变化时发布链路事件
+func (ts *TopologySync) applyLinkUpdate(from, to string, cost float64, version LinkVersion, pinned bool) bool {
+	oldCost, existed := ts.topology.GetCost(from, to)
+	oldMeta, _ := ts.topology.GetLinkMeta(from, to)
+
+	if !ts.topology.ApplyLinkUpdate(from, to, cost, version, pinned) {
+		return false
+	}
+
+	if !existed {
+		ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_ADD, from, to, 0, cost, pinned)
+	} else if oldCost != cost || oldMeta.Pinned != pinned {
+		ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_UPDATE, from, to, oldCost, cost, pinned)
+	}
+	return true
+}
+
+// applyLinkRemoval 应用带版本号的链路删除，链路存在时发布链路事件
+func (ts *TopologySync) applyLinkRemoval(from, to string, version LinkVersion, removedAt time.Time) bool {
+	oldCost, _ := ts.topology.GetCost(from, to)
+
+	if !ts.topology.ApplyLinkRemoval(from, to, version, removedAt) {
+		return false
+	}
+
+	ts.publishLinkEvent(pb.EventType_EVENT_TYPE_LINK_REMOVE, from, to, oldCost, 0, false)
+	return true
+}
+
+// publishLinkEvent 发布链路事件
+func (ts *TopologySync) publishLinkEvent(eventType pb.EventType, from, to string, oldCost, cost float64, pinned bool) {
+	ts.publish(&Event{
+		Type:    eventType,
+		From:    from,
+		To:      to,
+		OldCost: oldCost,
+		Cost:    cost,
+		Pinned:  pinned,
+	})
+}
+
+// publish 发布事件（未设置事件总线时忽略）
+func (ts *TopologySync) publish(event *Event) {
+	ts.mtx.RLock()
+	events := ts.events
+	ts.mtx.RUnlock()
+
+	events.Publish(event)
+}
+
 // triggerTopologyChange 触发拓扑变化回调
 func (ts *TopologySync) triggerTopologyChange() {
 	ts.mtx.RLock()
@@ -339,18 +492,28 @@ func (ts *TopologySync) RegisterNode(nodeInfo *NodeInfo) error {
 	return nil
 }
 
-// RegisterLink 注册一条链路
+// RegisterLink 注册一条链路（成本为自动探测结果）
 func (ts *TopologySync) RegisterLink(from, to string, cost float64) error {
-	ts.topology.UpdateLink(from, to, cost)
-	log.Printf("Registered link: %s-%s cost=%.2f", from, to, cost)
+	return ts.UpdateLinkCost(from, to, cost, false)
+}
+
+// UpdateLinkCost 以本节点发起的新版本添加或更新一条链路并广播到集群
+// pinned 为 true 表示手动固定的成本，否则表示自动探测的成本
+func (ts *TopologySync) UpdateLinkCost(from, to string, cost float64, pinned bool) error {
+	version := ts.topology.NextLinkVersion(ts.node.ID)
+	ts.applyLinkUpdate(from, to, cost, version, pinned)
+	log.Printf("Registered link: %s-%s cost=%.2f pinned=%v", from, to, cost, pinned)
 
 	// 广播链路更新事件到集群
 	if ts.node.serf != nil {
 		event := LinkUpdateEvent{
-			From: from,
-			To:   to,
-			Cost: cost,
-			Op:   "update",
+			From:     from,
+			To:       to,
+			Cost:     cost,
+			Sequence: version.Sequence,
+			Origin:   version.Origin,
+			Pinned:   pinned,
+			Op:       "update",
 		}
 
 		payload, err := json.Marshal(event)
@@ -376,16 +539,20 @@ func (ts *TopologySync) UnregisterNode(nodeID string) error {
 }
 
 // UnregisterLink 注销一条链路
+// 删除操作带有版本号，其他节点的全量同步不会再把该链路加回来
 func (ts *TopologySync) UnregisterLink(from, to string) error {
-	ts.topology.RemoveLink(from, to)
+	version := ts.topology.NextLinkVersion(ts.node.ID)
+	ts.applyLinkRemoval(from, to, version, time.Now())
 	log.Printf("Unregistered link: %s-%s", from, to)
 
 	// 广播链路删除事件
 	if ts.node.serf != nil {
 		event := LinkUpdateEvent{
-			From: from,
-			To:   to,
-			Op:   "remove",
+			From:     from,
+			To:       to,
+			Sequence: version.Sequence,
+			Origin:   version.Origin,
+			Op:       "remove",
 		}
 
 		payload, err := json.Marshal(event)
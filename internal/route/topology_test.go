@@ -0,0 +1,99 @@
+package route
+
+import (
+	"testing"
+	"time"
+)
+
+// 墓碑在 ttl 内阻止旧版本的链路被加回来，过期后被清理
+func TestLinkTombstoneExpires(t *testing.T) {
+	topology := NewTopology()
+	topology.ApplyLinkUpdate("a", "b", 1, LinkVersion{Sequence: 10, Origin: "a"}, false)
+	topology.ApplyLinkRemoval("a", "b", LinkVersion{Sequence: 20, Origin: "a"}, time.Now())
+
+	if topology.ApplyLinkUpdate("a", "b", 1, LinkVersion{Sequence: 15, Origin: "b"}, false) {
+		t.Fatal("stale update resurrected a removed link")
+	}
+	if n := topology.ExpireTombstones(time.Minute); n != 0 {
+		t.Fatalf("expired %d fresh tombstones, want 0", n)
+	}
+	if !topology.IsLinkRemoved("a", "b") {
+		t.Fatal("fresh tombstone was dropped")
+	}
+
+	topology.ApplyLinkRemoval("c", "d", LinkVersion{Sequence: 5, Origin: "c"}, time.Now().Add(-2*time.Minute))
+	if n := topology.ExpireTombstones(time.Minute); n != 1 {
+		t.Fatalf("expired %d tombstones, want 1", n)
+	}
+	if topology.IsLinkRemoved("c", "d") {
+		t.Fatal("expired tombstone still present")
+	}
+	for _, link := range topology.GetLinks() {
+		if link.From == "c" || link.To == "c" {
+			t.Fatalf("expired tombstone still synced: %+v", link)
+		}
+	}
+}
+
+// 全量同步携带墓碑的经过时长，接收方按原删除时间计算过期，墓碑不会在节点间来回续期
+func TestLinkTombstoneAgeIsSynced(t *testing.T) {
+	topology := NewTopology()
+	topology.ApplyLinkRemoval("a", "b", LinkVersion{Sequence: 7, Origin: "a"}, time.Now().Add(-90*time.Second))
+
+	links := topology.GetLinks()
+	if len(links) != 1 || !links[0].Removed {
+		t.Fatalf("links = %+v, want one tombstone", links)
+	}
+	if links[0].AgeMs < (90 * time.Second).Milliseconds() {
+		t.Fatalf("tombstone age = %dms, want >= 90s", links[0].AgeMs)
+	}
+
+	peer := NewTopology()
+	peer.ApplyLinkRemoval(links[0].From, links[0].To, LinkVersion{Sequence: links[0].Sequence, Origin: links[0].Origin},
+		time.Now().Add(-time.Duration(links[0].AgeMs)*time.Millisecond))
+	if n := peer.ExpireTombstones(time.Minute); n != 1 {
+		t.Fatalf("peer expired %d tombstones, want 1", n)
+	}
+}
+
+// 本节点的新版本号大于见过的所有版本号，不依赖各节点的时钟
+func TestNextLinkVersionFollowsObservedVersions(t *testing.T) {
+	topology := NewTopology()
+	first := topology.NextLinkVersion("a")
+	if first.Sequence != 1 || first.Origin != "a" {
+		t.Fatalf("first version = %+v, want {1 a}", first)
+	}
+
+	topology.ApplyLinkUpdate("b", "c", 1, LinkVersion{Sequence: 42, Origin: "b"}, false)
+	if v := topology.NextLinkVersion("a"); v.Sequence != 43 {
+		t.Fatalf("version after observing 42 = %d, want 43", v.Sequence)
+	}
+
+	// 被拒绝的旧删除同样推进时钟
+	topology.ApplyLinkRemoval("x", "y", LinkVersion{Sequence: 100, Origin: "x"}, time.Now())
+	if v := topology.NextLinkVersion("a"); v.Sequence != 101 {
+		t.Fatalf("version after observing 100 = %d, want 101", v.Sequence)
+	}
+}
+
+// 版本号相同的并发更新按发起节点决出胜者，与到达顺序无关
+func TestConcurrentLinkUpdatesConverge(t *testing.T) {
+	fromA := LinkVersion{Sequence: 5, Origin: "a"}
+	fromB := LinkVersion{Sequence: 5, Origin: "b"}
+
+	t1 := NewTopology()
+	t1.ApplyLinkUpdate("a", "b", 3, fromA, true)
+	t1.ApplyLinkUpdate("a", "b", 7, fromB, false)
+
+	t2 := NewTopology()
+	t2.ApplyLinkUpdate("a", "b", 7, fromB, false)
+	t2.ApplyLinkUpdate("a", "b", 3, fromA, true)
+
+	for i, topology := range []*Topology{t1, t2} {
+		cost, _ := topology.GetCost("a", "b")
+		meta, _ := topology.GetLinkMeta("a", "b")
+		if cost != 7 || meta.LinkVersion != fromB || meta.Pinned {
+			t.Fatalf("topology %d: cost=%.0f meta=%+v, want cost 7 from b", i+1, cost, meta)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// TunnelPort 出口节点接收隧道连接的覆盖网络端口
+const TunnelPort = 1
+
+// 隧道握手参数
+const (
+	tunnelHeaderLimit  = 512
+	tunnelDialTimeout  = 10 * time.Second
+	tunnelReplyOK      = "OK"
+	tunnelReplyFailure = "ERR "
+)
+
+var (
+	// ErrTunnelExists 同名隧道已存在
+	ErrTunnelExists = errors.New("tunnel already exists")
+	// ErrTunnelNotFound 隧道不存在
+	ErrTunnelNotFound = errors.New("tunnel not found")
+)
+
+// TunnelSpec 端口转发隧道定义：本节点监听 Listen，连接经覆盖网络到达 Node 后由其连接 Target
+type TunnelSpec struct {
+	Name     string `toml:"name"`      // 隧道名称，为空时使用监听地址
+	Listen   string `toml:"listen"`    // 本节点监听的 TCP 地址，如 "127.0.0.1:8080"
+	Node     string `toml:"node"`      // 出口节点 ID
+	Target   string `toml:"target"`    // 出口节点连接的 TCP 地址，如 "127.0.0.1:9000"
+	MaxConns int    `toml:"max_conns"` // 同时活跃的连接数上限，0 表示不限制
+}
+
+// TunnelStats 隧道定义及其统计
+type TunnelStats struct {
+	TunnelSpec
+	ActiveConns   int64  // 当前活跃的连接数
+	TotalConns    uint64 // 累计接受的连接数
+	RejectedConns uint64 // 因超过连接数上限被拒绝的连接数
+	FailedConns   uint64 // 未能建立到出口节点或目标地址的连接数
+	BytesSent     uint64 // 从本地客户端发往目标的字节数
+	BytesReceived uint64 // 从目标返回给本地客户端的字节数
+}
+
+// ParseTunnelSpec 解析 SSH 风格的隧道定义 "[监听地址:]端口:节点:目标主机:目标端口"
+// 省略监听地址时监听 127.0.0.1
+func ParseTunnelSpec(spec string) (TunnelSpec, error) {
+	listen, node, target, err := parseForwardSpec(spec)
+	if err != nil {
+		return TunnelSpec{}, err
+	}
+	return TunnelSpec{Listen: listen, Node: node, Target: target}, nil
+}
+
+// parseForwardSpec 解析 "[监听地址:]端口:节点:目标主机:目标端口" 形式的转发定义
+func parseForwardSpec(spec string) (listen, node, target string, err error) {
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 4:
+		parts = append([]string{"127.0.0.1"}, parts...)
+	case 5:
+	default:
+		return "", "", "", fmt.Errorf("invalid forward %q, expected [bind:]port:node:host:hostport", spec)
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return "", "", "", fmt.Errorf("invalid forward %q, expected [bind:]port:node:host:hostport", spec)
+		}
+	}
+
+	return net.JoinHostPort(parts[0], parts[1]), parts[2], net.JoinHostPort(parts[3], parts[4]), nil
+}
+
+// tunnel 一条运行中的隧道
+type tunnel struct {
+	spec     TunnelSpec
+	listener net.Listener
+
+	mtx   sync.Mutex
+	conns map[net.Conn]struct{}
+
+	active   atomic.Int64
+	total    atomic.Uint64
+	rejected atomic.Uint64
+	failed   atomic.Uint64
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+// stats 返回隧道统计快照
+func (t *tunnel) stats() TunnelStats {
+	return TunnelStats{
+		TunnelSpec:    t.spec,
+		ActiveConns:   t.active.Load(),
+		TotalConns:    t.total.Load(),
+		RejectedConns: t.rejected.Load(),
+		FailedConns:   t.failed.Load(),
+		BytesSent:     t.sent.Load(),
+		BytesReceived: t.received.Load(),
+	}
+}
+
+// TunnelManager 管理本节点发起的端口转发隧道，并作为出口节点处理其他节点的隧道连接
+// 隧道连接是 TunnelPort 上的覆盖网络字节流，入口节点先发送一行目标地址，
+// 出口节点连接目标后回复 "OK" 或 "ERR 原因"，之后双向透明转发
+type TunnelManager struct {
+	nodeID  string
+	streams *StreamManager
+
+	mtx            sync.Mutex
+	tunnels        map[string]*tunnel
+	allowedTargets []string
+	exit           *StreamListener
+	closed         bool
+}
+
+// NewTunnelManager 创建隧道管理器
+func NewTunnelManager(nodeID string, streams *StreamManager) *TunnelManager {
+	return &TunnelManager{
+		nodeID:  nodeID,
+		streams: streams,
+		tunnels: make(map[string]*tunnel),
+	}
+}
+
+// SetAllowedTargets 设置本节点作为出口时允许连接的目标地址（"主机:端口"，"*" 表示任意地址）
+// 为空时拒绝所有隧道连接
+func (tm *TunnelManager) SetAllowedTargets(targets []string) {
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+	tm.allowedTargets = append([]string(nil), targets...)
+}
+
+// Start 开始接收其他节点的隧道连接
+func (tm *TunnelManager) Start() error {
+	exit, err := tm.streams.Listen(TunnelPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen for tunnels: %w", err)
+	}
+
+	tm.mtx.Lock()
+	tm.exit = exit
+	tm.mtx.Unlock()
+
+	go tm.serveExit(exit)
+	return nil
+}
+
+// Add 添加并启动隧道
+func (tm *TunnelManager) Add(spec TunnelSpec) (TunnelStats, error) {
+	if spec.Listen == "" || spec.Node == "" || spec.Target == "" {
+		return TunnelStats{}, fmt.Errorf("listen, node and target are required")
+	}
+	if _, _, err := net.SplitHostPort(spec.Target); err != nil {
+		return TunnelStats{}, fmt.Errorf("invalid target %q: %w", spec.Target, err)
+	}
+	if spec.MaxConns < 0 {
+		return TunnelStats{}, fmt.Errorf("invalid max_conns %d", spec.MaxConns)
+	}
+	if spec.Name == "" {
+		spec.Name = spec.Listen
+	}
+
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+
+	if tm.closed {
+		return TunnelStats{}, net.ErrClosed
+	}
+	if _, exists := tm.tunnels[spec.Name]; exists {
+		return TunnelStats{}, fmt.Errorf("%w: %s", ErrTunnelExists, spec.Name)
+	}
+
+	listener, err := net.Listen("tcp", spec.Listen)
+	if err != nil {
+		return TunnelStats{}, fmt.Errorf("failed to listen on %s: %w", spec.Listen, err)
+	}
+
+	t := &tunnel{
+		spec:     spec,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	tm.tunnels[spec.Name] = t
+	go tm.serveTunnel(t)
+
+	log.Printf("[%s] ✓ Tunnel %s: %s -> %s -> %s (max_conns=%d)",
+		tm.nodeID, spec.Name, listener.Addr(), spec.Node, spec.Target, spec.MaxConns)
+	return t.stats(), nil
+}
+
+// Remove 删除隧道并断开其所有连接
+func (tm *TunnelManager) Remove(name string) error {
+	tm.mtx.Lock()
+	t, exists := tm.tunnels[name]
+	delete(tm.tunnels, name)
+	tm.mtx.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, name)
+	}
+
+	tm.stopTunnel(t)
+	log.Printf("[%s] Tunnel %s removed", tm.nodeID, name)
+	return nil
+}
+
+// List 返回所有隧道及其统计，按名称排序
+func (tm *TunnelManager) List() []TunnelStats {
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+
+	stats := make([]TunnelStats, 0, len(tm.tunnels))
+	for _, t := range tm.tunnels {
+		stats = append(stats, t.stats())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Close 关闭所有隧道和出口监听
+func (tm *TunnelManager) Close() {
+	tm.mtx.Lock()
+	tm.closed = true
+	tunnels := tm.tunnels
+	tm.tunnels = make(map[string]*tunnel)
+	exit := tm.exit
+	tm.mtx.Unlock()
+
+	for _, t := range tunnels {
+		tm.stopTunnel(t)
+	}
+	if exit != nil {
+		exit.Close()
+	}
+}
+
+// stopTunnel 停止监听并断开隧道的所有连接
+func (tm *TunnelManager) stopTunnel(t *tunnel) {
+	t.listener.Close()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for conn := range t.conns {
+		conn.Close()
+	}
+}
+
+// serveTunnel 接受本地 TCP 连接并经覆盖网络转发
+func (tm *TunnelManager) serveTunnel(t *tunnel) {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("[%s] ✗ Tunnel %s accept failed: %v", tm.nodeID, t.spec.Name, err)
+			}
+			return
+		}
+
+		if t.spec.MaxConns > 0 && t.active.Load() >= int64(t.spec.MaxConns) {
+			t.rejected.Add(1)
+			log.Printf("[%s] ✗ Tunnel %s rejected %s: %d connections active",
+				tm.nodeID, t.spec.Name, conn.RemoteAddr(), t.spec.MaxConns)
+			conn.Close()
+			continue
+		}
+
+		t.active.Add(1)
+		t.total.Add(1)
+		t.mtx.Lock()
+		t.conns[conn] = struct{}{}
+		t.mtx.Unlock()
+		go tm.handleTunnelConn(t, conn)
+	}
+}
+
+// handleTunnelConn 为本地连接建立到出口节点的字节流并双向转发
+func (tm *TunnelManager) handleTunnelConn(t *tunnel, local net.Conn) {
+	defer t.active.Add(-1)
+	defer func() {
+		t.mtx.Lock()
+		delete(t.conns, local)
+		t.mtx.Unlock()
+		local.Close()
+	}()
+
+	remote, err := tm.openTunnel(t.spec)
+	if err != nil {
+		t.failed.Add(1)
+		log.Printf("[%s] ✗ Tunnel %s to %s via %s failed: %v",
+			tm.nodeID, t.spec.Name, t.spec.Target, t.spec.Node, err)
+		return
+	}
+
+	log.Printf("[%s] ✓ Tunnel %s: %s connected to %s via %s",
+		tm.nodeID, t.spec.Name, local.RemoteAddr(), t.spec.Target, t.spec.Node)
+	pipe(local, remote, &t.sent, &t.received)
+}
+
+// openTunnel 连接出口节点并完成隧道握手
+func (tm *TunnelManager) openTunnel(spec TunnelSpec) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tunnelDialTimeout)
+	defer cancel()
+
+	conn, err := tm.streams.Dial(ctx, spec.Node, TunnelPort)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(tunnelDialTimeout))
+	if _, err := io.WriteString(conn, spec.Target+"\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reply, err := readTunnelLine(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("tunnel handshake failed: %w", err)
+	}
+	if reply != tunnelReplyOK {
+		conn.Close()
+		return nil, fmt.Errorf("exit node refused: %s", strings.TrimPrefix(reply, tunnelReplyFailure))
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// serveExit 作为出口节点接受其他节点的隧道连接
+func (tm *TunnelManager) serveExit(exit *StreamListener) {
+	for {
+		conn, err := exit.Accept()
+		if err != nil {
+			return
+		}
+		go tm.handleExitConn(conn)
+	}
+}
+
+// handleExitConn 读取目标地址，连接目标并双向转发
+func (tm *TunnelManager) handleExitConn(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(tunnelDialTimeout))
+	target, err := readTunnelLine(conn)
+	if err != nil {
+		log.Printf("[%s] ✗ Tunnel handshake from %s failed: %v", tm.nodeID, conn.RemoteAddr(), err)
+		return
+	}
+
+	if !tm.targetAllowed(target) {
+		log.Printf("[%s] ✗ Tunnel from %s to %s rejected: target not allowed", tm.nodeID, conn.RemoteAddr(), target)
+		io.WriteString(conn, tunnelReplyFailure+"target not allowed\n")
+		return
+	}
+
+	dialer := net.Dialer{Timeout: tunnelDialTimeout}
+	upstream, err := dialer.Dial("tcp", target)
+	if err != nil {
+		log.Printf("[%s] ✗ Tunnel from %s to %s failed: %v", tm.nodeID, conn.RemoteAddr(), target, err)
+		io.WriteString(conn, tunnelReplyFailure+err.Error()+"\n")
+		return
+	}
+	defer upstream.Close()
+
+	if _, err := io.WriteString(conn, tunnelReplyOK+"\n"); err != nil {
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	log.Printf("[%s] ✓ Tunnel from %s connected to %s", tm.nodeID, conn.RemoteAddr(), target)
+	var sent, received atomic.Uint64
+	pipe(conn, upstream, &sent, &received)
+	log.Printf("[%s] Tunnel from %s to %s closed (sent=%d, received=%d)",
+		tm.nodeID, conn.RemoteAddr(), target, sent.Load(), received.Load())
+}
+
+// targetAllowed 检查本节点作为出口时是否允许连接目标地址
+func (tm *TunnelManager) targetAllowed(target string) bool {
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+	for _, allowed := range tm.allowedTargets {
+		if allowed == "*" || allowed == target {
+			return true
+		}
+	}
+	return false
+}
+
+// readTunnelLine 逐字节读取一行（不读取换行之后的数据，避免吞掉随后的业务数据）
+func readTunnelLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for len(line) < tunnelHeaderLimit {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		if buf[0] == '\n' {
+			return string(line), nil
+		}
+		line = append(line, buf[0])
+	}
+	return "", fmt.Errorf("tunnel header exceeds %d bytes", tunnelHeaderLimit)
+}
+
+// closeWriter 支持半关闭的连接
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Uint64
+}
+
+func (cw countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n.Add(uint64(n))
+	return n, err
+}
+
+// pipe 在 a 和 b 之间双向复制数据直到两个方向都结束后关闭两端，一个方向结束时半关闭对端的写方向
+// aToB 和 bToA 分别累计两个方向的字节数
+func pipe(a, b net.Conn, aToB, bToA *atomic.Uint64) {
+	var wg sync.WaitGroup
+	copyHalf := func(dst, src net.Conn, counter *atomic.Uint64) {
+		defer wg.Done()
+		if _, err := io.Copy(countingWriter{w: dst, n: counter}, src); err != nil {
+			// 一个方向异常结束时整条连接都不再可用
+			a.Close()
+			b.Close()
+			return
+		}
+		if cw, ok := dst.(closeWriter); ok {
+			cw.CloseWrite()
+		} else {
+			dst.Close()
+		}
+	}
+
+	wg.Add(2)
+	go copyHalf(b, a, aToB)
+	go copyHalf(a, b, bToA)
+	wg.Wait()
+
+	a.Close()
+	b.Close()
+}
+
+// tunnelToProto 将隧道统计转换为 protobuf 消息
+func tunnelToProto(s TunnelStats) *pb.TunnelInfo {
+	return &pb.TunnelInfo{
+		Name:          s.Name,
+		Listen:        s.Listen,
+		Node:          s.Node,
+		Target:        s.Target,
+		MaxConns:      int32(s.MaxConns),
+		ActiveConns:   s.ActiveConns,
+		TotalConns:    s.TotalConns,
+		RejectedConns: s.RejectedConns,
+		FailedConns:   s.FailedConns,
+		BytesSent:     s.BytesSent,
+		BytesReceived: s.BytesReceived,
+	}
+}
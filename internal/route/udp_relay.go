@@ -0,0 +1,371 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "spfnet/proto"
+)
+
+// UDP 中继参数
+const (
+	DefaultMaxDatagramSize = 8192  // 默认允许中继的最大数据报字节数
+	maxUDPDatagramSize     = 65507 // UDP 数据报载荷上限
+
+	udpRelayQueueSize   = 1024
+	udpRelayWorkers     = 8
+	udpRelaySendTimeout = 2 * time.Second
+)
+
+var (
+	// ErrUDPRelayExists 同名 UDP 中继已存在
+	ErrUDPRelayExists = errors.New("udp relay already exists")
+	// ErrUDPRelayNotFound UDP 中继不存在
+	ErrUDPRelayNotFound = errors.New("udp relay not found")
+)
+
+// UDPRelaySpec UDP 中继定义：本节点监听 Listen 收到的每个数据报经覆盖网络送到 Node，由其发往 Target
+type UDPRelaySpec struct {
+	Name   string `toml:"name"`   // 中继名称，为空时使用监听地址
+	Listen string `toml:"listen"` // 本节点监听的 UDP 地址，如 "127.0.0.1:8125"
+	Node   string `toml:"node"`   // 目的节点 ID
+	Target string `toml:"target"` // 目的节点发出数据报的 UDP 地址，如 "127.0.0.1:8125"
+}
+
+// UDPRelayStats UDP 中继定义及其统计
+type UDPRelayStats struct {
+	UDPRelaySpec
+	Datagrams       uint64 // 已送出的数据报数
+	Bytes           uint64 // 已送出的字节数
+	DroppedOversize uint64 // 超过最大数据报长度被丢弃的数据报数
+	DroppedQueue    uint64 // 发送队列已满被丢弃的数据报数
+	Failed          uint64 // 未能送达目的节点的数据报数
+}
+
+// ParseUDPRelaySpec 解析 "[监听地址:]端口:节点:目标主机:目标端口" 形式的 UDP 中继定义
+// 省略监听地址时监听 127.0.0.1
+func ParseUDPRelaySpec(spec string) (UDPRelaySpec, error) {
+	listen, node, target, err := parseForwardSpec(spec)
+	if err != nil {
+		return UDPRelaySpec{}, err
+	}
+	return UDPRelaySpec{Listen: listen, Node: node, Target: target}, nil
+}
+
+// udpRelay 一条运行中的 UDP 中继
+type udpRelay struct {
+	spec  UDPRelaySpec
+	conn  *net.UDPConn
+	queue chan []byte
+	done  chan struct{}
+
+	datagrams       atomic.Uint64
+	bytes           atomic.Uint64
+	droppedOversize atomic.Uint64
+	droppedQueue    atomic.Uint64
+	failed          atomic.Uint64
+}
+
+// stats 返回中继统计快照
+func (r *udpRelay) stats() UDPRelayStats {
+	return UDPRelayStats{
+		UDPRelaySpec:    r.spec,
+		Datagrams:       r.datagrams.Load(),
+		Bytes:           r.bytes.Load(),
+		DroppedOversize: r.droppedOversize.Load(),
+		DroppedQueue:    r.droppedQueue.Load(),
+		Failed:          r.failed.Load(),
+	}
+}
+
+// UDPRelayManager 管理本节点的 UDP 中继入口，并作为目的节点发出其他节点中继来的数据报
+// 数据报语义不可靠：每个数据报封装为一个 DATAGRAM 数据包同步转发一次，
+// 不经过存储转发队列和持久化发件箱，失败或队列满时直接丢弃
+type UDPRelayManager struct {
+	nodeID string
+	fm     *ForwardManager
+
+	mtx            sync.Mutex
+	relays         map[string]*udpRelay
+	allowedTargets []string
+	maxSize        int
+	egress         *net.UDPConn
+	resolved       map[string]*net.UDPAddr
+	closed         bool
+
+	packetSeq atomic.Uint64
+}
+
+// NewUDPRelayManager 创建 UDP 中继管理器并注册为转发管理器的数据报处理器
+func NewUDPRelayManager(nodeID string, fm *ForwardManager) *UDPRelayManager {
+	um := &UDPRelayManager{
+		nodeID:   nodeID,
+		fm:       fm,
+		relays:   make(map[string]*udpRelay),
+		maxSize:  DefaultMaxDatagramSize,
+		resolved: make(map[string]*net.UDPAddr),
+	}
+	fm.SetDatagramHandler(um.handlePacket)
+	return um
+}
+
+// SetMaxDatagramSize 设置允许中继的最大数据报字节数（入口和出口都检查）
+func (um *UDPRelayManager) SetMaxDatagramSize(size int) error {
+	if size <= 0 || size > maxUDPDatagramSize {
+		return fmt.Errorf("invalid max datagram size %d, must be 1-%d", size, maxUDPDatagramSize)
+	}
+
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+	um.maxSize = size
+	return nil
+}
+
+// SetAllowedTargets 设置本节点作为目的节点时允许发往的 UDP 地址（"主机:端口"，"*" 表示任意地址）
+// 为空时丢弃所有中继来的数据报
+func (um *UDPRelayManager) SetAllowedTargets(targets []string) {
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+	um.allowedTargets = append([]string(nil), targets...)
+}
+
+// Add 添加并启动 UDP 中继
+func (um *UDPRelayManager) Add(spec UDPRelaySpec) (UDPRelayStats, error) {
+	if spec.Listen == "" || spec.Node == "" || spec.Target == "" {
+		return UDPRelayStats{}, fmt.Errorf("listen, node and target are required")
+	}
+	if _, _, err := net.SplitHostPort(spec.Target); err != nil {
+		return UDPRelayStats{}, fmt.Errorf("invalid target %q: %w", spec.Target, err)
+	}
+	if spec.Name == "" {
+		spec.Name = spec.Listen
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", spec.Listen)
+	if err != nil {
+		return UDPRelayStats{}, fmt.Errorf("invalid listen address %q: %w", spec.Listen, err)
+	}
+
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+
+	if um.closed {
+		return UDPRelayStats{}, net.ErrClosed
+	}
+	if _, exists := um.relays[spec.Name]; exists {
+		return UDPRelayStats{}, fmt.Errorf("%w: %s", ErrUDPRelayExists, spec.Name)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return UDPRelayStats{}, fmt.Errorf("failed to listen on %s: %w", spec.Listen, err)
+	}
+
+	r := &udpRelay{
+		spec:  spec,
+		conn:  conn,
+		queue: make(chan []byte, udpRelayQueueSize),
+		done:  make(chan struct{}),
+	}
+	um.relays[spec.Name] = r
+
+	go um.readLoop(r)
+	for i := 0; i < udpRelayWorkers; i++ {
+		go um.sendLoop(r)
+	}
+
+	log.Printf("[%s] ✓ UDP relay %s: %s -> %s -> %s (max_datagram_size=%d)",
+		um.nodeID, spec.Name, conn.LocalAddr(), spec.Node, spec.Target, um.maxSize)
+	return r.stats(), nil
+}
+
+// Remove 停止并删除 UDP 中继
+func (um *UDPRelayManager) Remove(name string) error {
+	um.mtx.Lock()
+	r, exists := um.relays[name]
+	delete(um.relays, name)
+	um.mtx.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUDPRelayNotFound, name)
+	}
+
+	r.conn.Close()
+	log.Printf("[%s] UDP relay %s removed", um.nodeID, name)
+	return nil
+}
+
+// List 返回所有 UDP 中继及其统计，按名称排序
+func (um *UDPRelayManager) List() []UDPRelayStats {
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+
+	stats := make([]UDPRelayStats, 0, len(um.relays))
+	for _, r := range um.relays {
+		stats = append(stats, r.stats())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Close 停止所有 UDP 中继
+func (um *UDPRelayManager) Close() {
+	um.mtx.Lock()
+	um.closed = true
+	relays := um.relays
+	um.relays = make(map[string]*udpRelay)
+	egress := um.egress
+	um.egress = nil
+	um.mtx.Unlock()
+
+	for _, r := range relays {
+		r.conn.Close()
+	}
+	if egress != nil {
+		egress.Close()
+	}
+}
+
+// getMaxSize 返回最大数据报字节数
+func (um *UDPRelayManager) getMaxSize() int {
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+	return um.maxSize
+}
+
+// readLoop 读取本地 UDP 数据报放入发送队列，超长或队列已满时丢弃
+func (um *UDPRelayManager) readLoop(r *udpRelay) {
+	defer close(r.done)
+
+	// 多读一个字节用于识别超长数据报
+	buf := make([]byte, maxUDPDatagramSize+1)
+	for {
+		n, _, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("[%s] ✗ UDP relay %s read failed: %v", um.nodeID, r.spec.Name, err)
+			}
+			return
+		}
+
+		if n > um.getMaxSize() {
+			r.droppedOversize.Add(1)
+			continue
+		}
+
+		datagram := append([]byte(nil), buf[:n]...)
+		select {
+		case r.queue <- datagram:
+		default:
+			r.droppedQueue.Add(1)
+		}
+	}
+}
+
+// sendLoop 将队列中的数据报封装为数据包发往目的节点，每个数据报只发送一次
+func (um *UDPRelayManager) sendLoop(r *udpRelay) {
+	for {
+		select {
+		case datagram := <-r.queue:
+			if err := um.send(r.spec, datagram); err != nil {
+				r.failed.Add(1)
+				continue
+			}
+			r.datagrams.Add(1)
+			r.bytes.Add(uint64(len(datagram)))
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// send 将数据报封装为 DATAGRAM 数据包发往目的节点，目的节点为本节点时直接发出
+func (um *UDPRelayManager) send(spec UDPRelaySpec, datagram []byte) error {
+	packet := &pb.Packet{
+		Source:         um.nodeID,
+		Destination:    spec.Node,
+		PacketId:       fmt.Sprintf("udp-%s-%d-%d", um.nodeID, time.Now().UnixNano(), um.packetSeq.Add(1)),
+		Payload:        datagram,
+		VisitedNodes:   []string{um.nodeID},
+		Type:           pb.PacketType_PACKET_TYPE_DATAGRAM,
+		Ttl:            um.fm.getDefaultTTL(),
+		DatagramTarget: spec.Target,
+	}
+
+	if spec.Node == um.nodeID {
+		return um.emit(packet)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), udpRelaySendTimeout)
+	defer cancel()
+
+	um.fm.recordSent(packet)
+	if err := um.fm.seal(packet); err != nil {
+		return err
+	}
+	return um.fm.forwardPacket(ctx, packet)
+}
+
+// handlePacket 处理到达本节点的数据报
+func (um *UDPRelayManager) handlePacket(packet *pb.Packet) {
+	if err := um.emit(packet); err != nil {
+		log.Printf("[%s] ✗ Datagram %s from %s dropped: %v", um.nodeID, packet.PacketId, packet.Source, err)
+		return
+	}
+	um.fm.recordDelivered(packet)
+}
+
+// emit 将数据报从本节点发往其目标 UDP 地址
+func (um *UDPRelayManager) emit(packet *pb.Packet) error {
+	um.mtx.Lock()
+	defer um.mtx.Unlock()
+
+	if um.closed {
+		return net.ErrClosed
+	}
+	if len(packet.Payload) > um.maxSize {
+		return fmt.Errorf("datagram size %d exceeds limit %d", len(packet.Payload), um.maxSize)
+	}
+	if !um.targetAllowedLocked(packet.DatagramTarget) {
+		return fmt.Errorf("target %s not allowed", packet.DatagramTarget)
+	}
+
+	addr, ok := um.resolved[packet.DatagramTarget]
+	if !ok {
+		var err error
+		if addr, err = net.ResolveUDPAddr("udp", packet.DatagramTarget); err != nil {
+			return err
+		}
+		um.resolved[packet.DatagramTarget] = addr
+	}
+
+	if um.egress == nil {
+		egress, err := net.ListenUDP("udp", nil)
+		if err != nil {
+			return err
+		}
+		um.egress = egress
+	}
+
+	_, err := um.egress.WriteToUDP(packet.Payload, addr)
+	return err
+}
+
+// targetAllowedLocked 检查本节点作为目的节点时是否允许发往目标地址（调用方持有 um.mtx）
+func (um *UDPRelayManager) targetAllowedLocked(target string) bool {
+	for _, allowed := range um.allowedTargets {
+		if allowed == "*" || allowed == target {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,328 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 字节流分段类型
+type StreamSegmentType int32
+
+const (
+	StreamSegmentType_STREAM_SEGMENT_TYPE_UNSPECIFIED StreamSegmentType = 0
+	// 发起连接
+	StreamSegmentType_STREAM_SEGMENT_TYPE_SYN StreamSegmentType = 1
+	// 接受连接
+	StreamSegmentType_STREAM_SEGMENT_TYPE_SYN_ACK StreamSegmentType = 2
+	// 数据（payload 为空时作为零窗口探测）
+	StreamSegmentType_STREAM_SEGMENT_TYPE_DATA StreamSegmentType = 3
+	// 纯确认 / 窗口更新
+	StreamSegmentType_STREAM_SEGMENT_TYPE_ACK StreamSegmentType = 4
+	// 发送方向结束（seq 为字节流总长度，占用一个序号）
+	StreamSegmentType_STREAM_SEGMENT_TYPE_FIN StreamSegmentType = 5
+	// 拒绝或中止连接
+	StreamSegmentType_STREAM_SEGMENT_TYPE_RST StreamSegmentType = 6
+)
+
+// Enum value maps for StreamSegmentType.
+var (
+	StreamSegmentType_name = map[int32]string{
+		0: "STREAM_SEGMENT_TYPE_UNSPECIFIED",
+		1: "STREAM_SEGMENT_TYPE_SYN",
+		2: "STREAM_SEGMENT_TYPE_SYN_ACK",
+		3: "STREAM_SEGMENT_TYPE_DATA",
+		4: "STREAM_SEGMENT_TYPE_ACK",
+		5: "STREAM_SEGMENT_TYPE_FIN",
+		6: "STREAM_SEGMENT_TYPE_RST",
+	}
+	StreamSegmentType_value = map[string]int32{
+		"STREAM_SEGMENT_TYPE_UNSPECIFIED": 0,
+		"STREAM_SEGMENT_TYPE_SYN":         1,
+		"STREAM_SEGMENT_TYPE_SYN_ACK":     2,
+		"STREAM_SEGMENT_TYPE_DATA":        3,
+		"STREAM_SEGMENT_TYPE_ACK":         4,
+		"STREAM_SEGMENT_TYPE_FIN":         5,
+		"STREAM_SEGMENT_TYPE_RST":         6,
+	}
+)
+
+func (x StreamSegmentType) Enum() *StreamSegmentType {
+	p := new(StreamSegmentType)
+	*p = x
+	return p
+}
+
+func (x StreamSegmentType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StreamSegmentType) Descriptor() protoreflect.EnumDescriptor {
+	return file_node_proto_enumTypes[0].Descriptor()
+}
+
+func (StreamSegmentType) Type() protoreflect.EnumType {
+	return &file_node_proto_enumTypes[0]
+}
+
+func (x StreamSegmentType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StreamSegmentType.Descriptor instead.
+func (StreamSegmentType) EnumDescriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{0}
+}
+
+// 数据包类型
+type PacketType int32
+
+const (
+	// 业务数据包
+	PacketType_PACKET_TYPE_DATA PacketType = 0
+	// 控制报文（类似 ICMP 差错报文，由转发失败的节点发回源节点）
+	PacketType_PACKET_TYPE_CONTROL PacketType = 1
+	// 多播数据包（发往 multicast_targets 中的所有节点）
+	PacketType_PACKET_TYPE_MULTICAST PacketType = 2
+	// 字节流分段（由目的节点的流管理器处理，不投递给业务回调）
+	PacketType_PACKET_TYPE_STREAM PacketType = 3
+	// UDP 中继数据报（由目的节点发往 datagram_target，不可靠，不重传）
+	PacketType_PACKET_TYPE_DATAGRAM PacketType = 4
+)
+
+// Enum value maps for PacketType.
+var (
+	PacketType_name = map[int32]string{
+		0: "PACKET_TYPE_DATA",
+		1: "PACKET_TYPE_CONTROL",
+		2: "PACKET_TYPE_MULTICAST",
+		3: "PACKET_TYPE_STREAM",
+		4: "PACKET_TYPE_DATAGRAM",
+	}
+	PacketType_value = map[string]int32{
+		"PACKET_TYPE_DATA":      0,
+		"PACKET_TYPE_CONTROL":   1,
+		"PACKET_TYPE_MULTICAST": 2,
+		"PACKET_TYPE_STREAM":    3,
+		"PACKET_TYPE_DATAGRAM":  4,
+	}
+)
+
+func (x PacketType) Enum() *PacketType {
+	p := new(PacketType)
+	*p = x
+	return p
+}
+
+func (x PacketType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PacketType) Descriptor() protoreflect.EnumDescriptor {
+	return file_node_proto_enumTypes[1].Descriptor()
+}
+
+func (PacketType) Type() protoreflect.EnumType {
+	return &file_node_proto_enumTypes[1]
+}
+
+func (x PacketType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PacketType.Descriptor instead.
+func (PacketType) EnumDescriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{1}
+}
+
+// 载荷压缩算法
+type Compression int32
+
+const (
+	// 未压缩
+	Compression_COMPRESSION_NONE Compression = 0
+	// gzip（RFC 1952）
+	Compression_COMPRESSION_GZIP Compression = 1
+	// 原始 DEFLATE（RFC 1951），头部开销比 gzip 小
+	Compression_COMPRESSION_DEFLATE Compression = 2
+)
+
+// Enum value maps for Compression.
+var (
+	Compression_name = map[int32]string{
+		0: "COMPRESSION_NONE",
+		1: "COMPRESSION_GZIP",
+		2: "COMPRESSION_DEFLATE",
+	}
+	Compression_value = map[string]int32{
+		"COMPRESSION_NONE":    0,
+		"COMPRESSION_GZIP":    1,
+		"COMPRESSION_DEFLATE": 2,
+	}
+)
+
+func (x Compression) Enum() *Compression {
+	p := new(Compression)
+	*p = x
+	return p
+}
+
+func (x Compression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Compression) Descriptor() protoreflect.EnumDescriptor {
+	return file_node_proto_enumTypes[2].Descriptor()
+}
+
+func (Compression) Type() protoreflect.EnumType {
+	return &file_node_proto_enumTypes[2]
+}
+
+func (x Compression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Compression.Descriptor instead.
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{2}
+}
+
+// 控制报文类型
+type ControlCode int32
+
+const (
+	ControlCode_CONTROL_CODE_UNSPECIFIED ControlCode = 0
+	// 目标不可达（无路由或下一跳不可用）
+	ControlCode_CONTROL_CODE_DEST_UNREACHABLE ControlCode = 1
+	// TTL 耗尽
+	ControlCode_CONTROL_CODE_TTL_EXCEEDED ControlCode = 2
+	// 载荷超过转发节点允许的最大长度
+	ControlCode_CONTROL_CODE_FRAGMENTATION_NEEDED ControlCode = 3
+	// 被转发节点的管理策略禁止
+	ControlCode_CONTROL_CODE_ADMIN_PROHIBITED ControlCode = 4
+)
+
+// Enum value maps for ControlCode.
+var (
+	ControlCode_name = map[int32]string{
+		0: "CONTROL_CODE_UNSPECIFIED",
+		1: "CONTROL_CODE_DEST_UNREACHABLE",
+		2: "CONTROL_CODE_TTL_EXCEEDED",
+		3: "CONTROL_CODE_FRAGMENTATION_NEEDED",
+		4: "CONTROL_CODE_ADMIN_PROHIBITED",
+	}
+	ControlCode_value = map[string]int32{
+		"CONTROL_CODE_UNSPECIFIED":          0,
+		"CONTROL_CODE_DEST_UNREACHABLE":     1,
+		"CONTROL_CODE_TTL_EXCEEDED":         2,
+		"CONTROL_CODE_FRAGMENTATION_NEEDED": 3,
+		"CONTROL_CODE_ADMIN_PROHIBITED":     4,
+	}
+)
+
+func (x ControlCode) Enum() *ControlCode {
+	p := new(ControlCode)
+	*p = x
+	return p
+}
+
+func (x ControlCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ControlCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_node_proto_enumTypes[3].Descriptor()
+}
+
+func (ControlCode) Type() protoreflect.EnumType {
+	return &file_node_proto_enumTypes[3]
+}
+
+func (x ControlCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ControlCode.Descriptor instead.
+func (ControlCode) EnumDescriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{3}
+}
+
+// 事件类型
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	// 节点加入集群
+	EventType_EVENT_TYPE_MEMBER_JOIN EventType = 1
+	// 节点主动离开集群
+	EventType_EVENT_TYPE_MEMBER_LEAVE EventType = 2
+	// 节点失联
+	EventType_EVENT_TYPE_MEMBER_FAILED EventType = 3
+	// 新增链路
+	EventType_EVENT_TYPE_LINK_ADD EventType = 4
+	// 链路成本或模式变化
+	EventType_EVENT_TYPE_LINK_UPDATE EventType = 5
+	// 删除链路
+	EventType_EVENT_TYPE_LINK_REMOVE EventType = 6
+	// 新增到某目标的路由（目标变为可达）
+	EventType_EVENT_TYPE_ROUTE_ADD EventType = 7
+	// 到某目标的路由变化（下一跳、成本或路径）
+	EventType_EVENT_TYPE_ROUTE_CHANGE EventType = 8
+	// 删除到某目标的路由（目标变为不可达）
+	EventType_EVENT_TYPE_ROUTE_REMOVE EventType = 9
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_MEMBER_JOIN",
+		2: "EVENT_TYPE_MEMBER_LEAVE",
+		3: "EVENT_TYPE_MEMBER_FAILED",
+		4: "EVENT_TYPE_LINK_ADD",
+		5: "EVENT_TYPE_LINK_UPDATE",
+		6: "EVENT_TYPE_LINK_REMOVE",
+		7: "EVENT_TYPE_ROUTE_ADD",
+		8: "EVENT_TYPE_ROUTE_CHANGE",
+		9: "EVENT_TYPE_ROUTE_REMOVE",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED":   0,
+		"EVENT_TYPE_MEMBER_JOIN":   1,
+		"EVENT_TYPE_MEMBER_LEAVE":  2,
+		"EVENT_TYPE_MEMBER_FAILED": 3,
+		"EVENT_TYPE_LINK_ADD":      4,
+		"EVENT_TYPE_LINK_UPDATE":   5,
+		"EVENT_TYPE_LINK_REMOVE":   6,
+		"EVENT_TYPE_ROUTE_ADD":     7,
+		"EVENT_TYPE_ROUTE_CHANGE":  8,
+		"EVENT_TYPE_ROUTE_REMOVE":  9,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_node_proto_enumTypes[4].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_node_proto_enumTypes[4]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{4}
+}
+
 // 一个逻辑数据包
 type Packet struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -35,7 +357,46 @@ type Packet struct {
 	// 载荷（真正要传输的业务数据）
 	Payload []byte `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
 	// 可选：记录已走过的路径（便于调试/展示）
-	VisitedNodes  []string `protobuf:"bytes,6,rep,name=visited_nodes,json=visitedNodes,proto3" json:"visited_nodes,omitempty"`
+	VisitedNodes []string `protobuf:"bytes,6,rep,name=visited_nodes,json=visitedNodes,proto3" json:"visited_nodes,omitempty"`
+	// 数据包类型（业务数据 / 控制报文）
+	Type PacketType `protobuf:"varint,7,opt,name=type,proto3,enum=spfnet.PacketType" json:"type,omitempty"`
+	// 剩余跳数，每经过一个中间节点减 1，为 0 时丢弃（0 表示未设置，由首个转发节点填充默认值）
+	Ttl uint32 `protobuf:"varint,8,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// 控制报文内容（仅 type = PACKET_TYPE_CONTROL 时有效）
+	Control *ControlMessage `protobuf:"bytes,9,opt,name=control,proto3" json:"control,omitempty"`
+	// 路径追踪模式：每一跳在 trace_hops 中记录自身信息，目的节点不向业务层投递
+	Trace bool `protobuf:"varint,10,opt,name=trace,proto3" json:"trace,omitempty"`
+	// 路径追踪记录（按经过顺序，第一个为源节点）
+	TraceHops []*TraceHop `protobuf:"bytes,11,rep,name=trace_hops,json=traceHops,proto3" json:"trace_hops,omitempty"`
+	// 有序流标识（为空表示不要求有序），目的节点按 (source, flow_id) 重排
+	FlowId string `protobuf:"bytes,12,opt,name=flow_id,json=flowId,proto3" json:"flow_id,omitempty"`
+	// 有序流内的序号，从 1 开始递增
+	Sequence uint64 `protobuf:"varint,13,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// 目标服务名（按服务名发送时由源节点选择最近的提供者作为 destination）
+	Service string `protobuf:"bytes,14,opt,name=service,proto3" json:"service,omitempty"`
+	// 多播目标（仅 type = PACKET_TYPE_MULTICAST 时有效）
+	// 每个副本只携带其所在分支上的目标，节点在分支处复制数据包
+	MulticastTargets []*MulticastTarget `protobuf:"bytes,15,rep,name=multicast_targets,json=multicastTargets,proto3" json:"multicast_targets,omitempty"`
+	// 多播组名（广播时为 "*"）
+	Group string `protobuf:"bytes,16,opt,name=group,proto3" json:"group,omitempty"`
+	// 发布的主题（按主题发布时有效，目标为订阅该主题的所有节点）
+	Topic string `protobuf:"bytes,17,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 字节流分段头（仅 type = PACKET_TYPE_STREAM 时有效，分段数据放在 payload 中）
+	Stream *StreamSegment `protobuf:"bytes,18,opt,name=stream,proto3" json:"stream,omitempty"`
+	// UDP 数据报在目的节点发出的地址（仅 type = PACKET_TYPE_DATAGRAM 时有效，数据报内容放在 payload 中）
+	DatagramTarget string `protobuf:"bytes,19,opt,name=datagram_target,json=datagramTarget,proto3" json:"datagram_target,omitempty"`
+	// 请求 ID（发送方等待应答时设置，接收方应答时填入 reply_to）
+	RequestId string `protobuf:"bytes,20,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// 所应答的请求 ID（仅应答数据包设置）
+	ReplyTo string `protobuf:"bytes,21,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`
+	// 应用自定义的元数据（如 content-type、trace-id），随数据包原样送达目的节点
+	Headers map[string]string `protobuf:"bytes,22,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 载荷的压缩算法（源节点压缩，目的节点投递前解压）
+	Compression Compression `protobuf:"varint,23,opt,name=compression,proto3,enum=spfnet.Compression" json:"compression,omitempty"`
+	// 载荷已端到端加密：payload 为 nonce + 密文（明文为 SealedPayload，headers 一并封装），只有目的节点能解密
+	Encrypted bool `protobuf:"varint,24,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	// 有序流的纪元（发送端创建该流时的 Unix 纳秒时间），目的节点收到更大的纪元时重置该流的序号
+	FlowEpoch     uint64 `protobuf:"varint,25,opt,name=flow_epoch,json=flowEpoch,proto3" json:"flow_epoch,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -47,14 +408,3355 @@ func (x *Packet) Reset() {
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Packet) String() string {
+func (x *Packet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Packet) ProtoMessage() {}
+
+func (x *Packet) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
+func (*Packet) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Packet) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Packet) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Packet) GetNextHop() string {
+	if x != nil {
+		return x.NextHop
+	}
+	return ""
+}
+
+func (x *Packet) GetPacketId() string {
+	if x != nil {
+		return x.PacketId
+	}
+	return ""
+}
+
+func (x *Packet) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Packet) GetVisitedNodes() []string {
+	if x != nil {
+		return x.VisitedNodes
+	}
+	return nil
+}
+
+func (x *Packet) GetType() PacketType {
+	if x != nil {
+		return x.Type
+	}
+	return PacketType_PACKET_TYPE_DATA
+}
+
+func (x *Packet) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Packet) GetControl() *ControlMessage {
+	if x != nil {
+		return x.Control
+	}
+	return nil
+}
+
+func (x *Packet) GetTrace() bool {
+	if x != nil {
+		return x.Trace
+	}
+	return false
+}
+
+func (x *Packet) GetTraceHops() []*TraceHop {
+	if x != nil {
+		return x.TraceHops
+	}
+	return nil
+}
+
+func (x *Packet) GetFlowId() string {
+	if x != nil {
+		return x.FlowId
+	}
+	return ""
+}
+
+func (x *Packet) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Packet) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Packet) GetMulticastTargets() []*MulticastTarget {
+	if x != nil {
+		return x.MulticastTargets
+	}
+	return nil
+}
+
+func (x *Packet) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Packet) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Packet) GetStream() *StreamSegment {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *Packet) GetDatagramTarget() string {
+	if x != nil {
+		return x.DatagramTarget
+	}
+	return ""
+}
+
+func (x *Packet) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Packet) GetReplyTo() string {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return ""
+}
+
+func (x *Packet) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Packet) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_COMPRESSION_NONE
+}
+
+func (x *Packet) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *Packet) GetFlowEpoch() uint64 {
+	if x != nil {
+		return x.FlowEpoch
+	}
+	return 0
+}
+
+// 端到端加密的明文内容（序列化后加密放入 Packet.payload）
+type SealedPayload struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 原始载荷
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 应用自定义的元数据
+	Headers       map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SealedPayload) Reset() {
+	*x = SealedPayload{}
+	mi := &file_node_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealedPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealedPayload) ProtoMessage() {}
+
+func (x *SealedPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealedPayload.ProtoReflect.Descriptor instead.
+func (*SealedPayload) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SealedPayload) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SealedPayload) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// 字节流分段头（类似 TCP 头部，连接由 (源节点, src_port, 目标节点, dst_port) 标识）
+type StreamSegment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 发送方端口
+	SrcPort uint32 `protobuf:"varint,1,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	// 接收方端口
+	DstPort uint32 `protobuf:"varint,2,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	// 连接标识（由发起方随机生成，用于区分复用同一组端口的新旧连接）
+	ConnId uint64 `protobuf:"varint,3,opt,name=conn_id,json=connId,proto3" json:"conn_id,omitempty"`
+	// 分段类型
+	Type StreamSegmentType `protobuf:"varint,4,opt,name=type,proto3,enum=spfnet.StreamSegmentType" json:"type,omitempty"`
+	// 数据在发送方字节流中的偏移（DATA / FIN）
+	Seq uint64 `protobuf:"varint,5,opt,name=seq,proto3" json:"seq,omitempty"`
+	// 累计确认：已按序收到的对端字节数
+	Ack uint64 `protobuf:"varint,6,opt,name=ack,proto3" json:"ack,omitempty"`
+	// 发送方当前剩余的接收窗口（字节）
+	Window uint32 `protobuf:"varint,7,opt,name=window,proto3" json:"window,omitempty"`
+	// 拒绝或中止的原因（RST）
+	Reason        string `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSegment) Reset() {
+	*x = StreamSegment{}
+	mi := &file_node_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSegment) ProtoMessage() {}
+
+func (x *StreamSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSegment.ProtoReflect.Descriptor instead.
+func (*StreamSegment) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamSegment) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetConnId() uint64 {
+	if x != nil {
+		return x.ConnId
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetType() StreamSegmentType {
+	if x != nil {
+		return x.Type
+	}
+	return StreamSegmentType_STREAM_SEGMENT_TYPE_UNSPECIFIED
+}
+
+func (x *StreamSegment) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetAck() uint64 {
+	if x != nil {
+		return x.Ack
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetWindow() uint32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+func (x *StreamSegment) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// 多播目标及源节点计算出的到达该目标的最短路径
+type MulticastTarget struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目标节点 ID
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 从源节点到目标的完整路径（沿路节点据此选择下一跳，构成以源节点为根的最短路径树）
+	Path          []string `protobuf:"bytes,2,rep,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MulticastTarget) Reset() {
+	*x = MulticastTarget{}
+	mi := &file_node_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MulticastTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MulticastTarget) ProtoMessage() {}
+
+func (x *MulticastTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MulticastTarget.ProtoReflect.Descriptor instead.
+func (*MulticastTarget) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MulticastTarget) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *MulticastTarget) GetPath() []string {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+// 路径追踪中某一跳的记录
+type TraceHop struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 节点 ID
+	NodeId string `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// 收到数据包的时间（Unix 纳秒）
+	RecvUnixNano int64 `protobuf:"varint,2,opt,name=recv_unix_nano,json=recvUnixNano,proto3" json:"recv_unix_nano,omitempty"`
+	// 转发到下一跳的时间（Unix 纳秒，目的节点为 0）
+	ForwardUnixNano int64 `protobuf:"varint,3,opt,name=forward_unix_nano,json=forwardUnixNano,proto3" json:"forward_unix_nano,omitempty"`
+	// 选择的下一跳节点 ID（目的节点为空）
+	NextHop string `protobuf:"bytes,4,opt,name=next_hop,json=nextHop,proto3" json:"next_hop,omitempty"`
+	// 本节点从转发到收到下游响应的耗时（纳秒，目的节点为 0）
+	// 只使用本节点时钟测量，不受节点间时钟偏差影响
+	DownstreamRttNanos int64 `protobuf:"varint,5,opt,name=downstream_rtt_nanos,json=downstreamRttNanos,proto3" json:"downstream_rtt_nanos,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TraceHop) Reset() {
+	*x = TraceHop{}
+	mi := &file_node_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceHop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceHop) ProtoMessage() {}
+
+func (x *TraceHop) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceHop.ProtoReflect.Descriptor instead.
+func (*TraceHop) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TraceHop) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *TraceHop) GetRecvUnixNano() int64 {
+	if x != nil {
+		return x.RecvUnixNano
+	}
+	return 0
+}
+
+func (x *TraceHop) GetForwardUnixNano() int64 {
+	if x != nil {
+		return x.ForwardUnixNano
+	}
+	return 0
+}
+
+func (x *TraceHop) GetNextHop() string {
+	if x != nil {
+		return x.NextHop
+	}
+	return ""
+}
+
+func (x *TraceHop) GetDownstreamRttNanos() int64 {
+	if x != nil {
+		return x.DownstreamRttNanos
+	}
+	return 0
+}
+
+// 控制报文（描述原始数据包无法投递的原因）
+type ControlMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 差错类型
+	Code ControlCode `protobuf:"varint,1,opt,name=code,proto3,enum=spfnet.ControlCode" json:"code,omitempty"`
+	// 产生该差错的节点 ID
+	Reporter string `protobuf:"bytes,2,opt,name=reporter,proto3" json:"reporter,omitempty"`
+	// 原始数据包 ID
+	OriginalPacketId string `protobuf:"bytes,3,opt,name=original_packet_id,json=originalPacketId,proto3" json:"original_packet_id,omitempty"`
+	// 原始数据包的目标节点 ID
+	OriginalDestination string `protobuf:"bytes,4,opt,name=original_destination,json=originalDestination,proto3" json:"original_destination,omitempty"`
+	// 可读的错误原因
+	Reason string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// 报告节点允许的最大载荷长度（仅 FRAGMENTATION_NEEDED 时有效）
+	MaxPayloadSize uint32 `protobuf:"varint,6,opt,name=max_payload_size,json=maxPayloadSize,proto3" json:"max_payload_size,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ControlMessage) Reset() {
+	*x = ControlMessage{}
+	mi := &file_node_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlMessage) ProtoMessage() {}
+
+func (x *ControlMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlMessage.ProtoReflect.Descriptor instead.
+func (*ControlMessage) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ControlMessage) GetCode() ControlCode {
+	if x != nil {
+		return x.Code
+	}
+	return ControlCode_CONTROL_CODE_UNSPECIFIED
+}
+
+func (x *ControlMessage) GetReporter() string {
+	if x != nil {
+		return x.Reporter
+	}
+	return ""
+}
+
+func (x *ControlMessage) GetOriginalPacketId() string {
+	if x != nil {
+		return x.OriginalPacketId
+	}
+	return ""
+}
+
+func (x *ControlMessage) GetOriginalDestination() string {
+	if x != nil {
+		return x.OriginalDestination
+	}
+	return ""
+}
+
+func (x *ControlMessage) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ControlMessage) GetMaxPayloadSize() uint32 {
+	if x != nil {
+		return x.MaxPayloadSize
+	}
+	return 0
+}
+
+type ForwardResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 本次转发是否成功投递到下一跳
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 出错时的信息（例如目标不可达）
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 出错时由失败节点生成的控制报文，沿同步调用链原样返回
+	Control *ControlMessage `protobuf:"bytes,3,opt,name=control,proto3" json:"control,omitempty"`
+	// 路径追踪记录（仅 trace 模式）
+	TraceHops []*TraceHop `protobuf:"bytes,4,rep,name=trace_hops,json=traceHops,proto3" json:"trace_hops,omitempty"`
+	// 多播时本节点及下游未能投递的目标节点 ID
+	FailedDestinations []string `protobuf:"bytes,5,rep,name=failed_destinations,json=failedDestinations,proto3" json:"failed_destinations,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ForwardResponse) Reset() {
+	*x = ForwardResponse{}
+	mi := &file_node_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardResponse) ProtoMessage() {}
+
+func (x *ForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardResponse.ProtoReflect.Descriptor instead.
+func (*ForwardResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ForwardResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ForwardResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ForwardResponse) GetControl() *ControlMessage {
+	if x != nil {
+		return x.Control
+	}
+	return nil
+}
+
+func (x *ForwardResponse) GetTraceHops() []*TraceHop {
+	if x != nil {
+		return x.TraceHops
+	}
+	return nil
+}
+
+func (x *ForwardResponse) GetFailedDestinations() []string {
+	if x != nil {
+		return x.FailedDestinations
+	}
+	return nil
+}
+
+// 链路质量探测请求
+type ProbeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 发起探测的节点 ID（可选，用于调试）
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// 目标节点 ID（要探测到哪个邻居）
+	Target string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// 自我调试模式（随机生成开销）
+	SelfDebug     bool `protobuf:"varint,3,opt,name=self_debug,json=selfDebug,proto3" json:"self_debug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProbeRequest) Reset() {
+	*x = ProbeRequest{}
+	mi := &file_node_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProbeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeRequest) ProtoMessage() {}
+
+func (x *ProbeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeRequest.ProtoReflect.Descriptor instead.
+func (*ProbeRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProbeRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ProbeRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ProbeRequest) GetSelfDebug() bool {
+	if x != nil {
+		return x.SelfDebug
+	}
+	return false
+}
+
+// 链路质量探测响应
+type ProbeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 探测是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 单位毫秒的 RTT（往返时间）
+	RttMs int64 `protobuf:"varint,2,opt,name=rtt_ms,json=rttMs,proto3" json:"rtt_ms,omitempty"`
+	// 根据 rtt 计算出的 cost（你自己定义算法）
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 出错信息
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProbeResponse) Reset() {
+	*x = ProbeResponse{}
+	mi := &file_node_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProbeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeResponse) ProtoMessage() {}
+
+func (x *ProbeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeResponse.ProtoReflect.Descriptor instead.
+func (*ProbeResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProbeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ProbeResponse) GetRttMs() int64 {
+	if x != nil {
+		return x.RttMs
+	}
+	return 0
+}
+
+func (x *ProbeResponse) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *ProbeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 健康检查
+type PingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Msg           string                 `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_node_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PingRequest) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+type PingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Msg           string                 `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_node_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingResponse) ProtoMessage() {}
+
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PingResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+// 添加链路请求
+type AddLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目标邻居节点 ID
+	Neighbor string `protobuf:"bytes,1,opt,name=neighbor,proto3" json:"neighbor,omitempty"`
+	// 邻居节点的 gRPC 地址（例如 "localhost:50052"）
+	NeighborAddress string `protobuf:"bytes,2,opt,name=neighbor_address,json=neighborAddress,proto3" json:"neighbor_address,omitempty"`
+	// 链路成本（可选，如果不提供则自动探测）
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 是否自动探测成本（默认 true）
+	AutoProbe     bool `protobuf:"varint,4,opt,name=auto_probe,json=autoProbe,proto3" json:"auto_probe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddLinkRequest) Reset() {
+	*x = AddLinkRequest{}
+	mi := &file_node_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddLinkRequest) ProtoMessage() {}
+
+func (x *AddLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddLinkRequest.ProtoReflect.Descriptor instead.
+func (*AddLinkRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddLinkRequest) GetNeighbor() string {
+	if x != nil {
+		return x.Neighbor
+	}
+	return ""
+}
+
+func (x *AddLinkRequest) GetNeighborAddress() string {
+	if x != nil {
+		return x.NeighborAddress
+	}
+	return ""
+}
+
+func (x *AddLinkRequest) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *AddLinkRequest) GetAutoProbe() bool {
+	if x != nil {
+		return x.AutoProbe
+	}
+	return false
+}
+
+// 添加链路响应
+type AddLinkResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 实际使用的链路成本
+	Cost          float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddLinkResponse) Reset() {
+	*x = AddLinkResponse{}
+	mi := &file_node_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddLinkResponse) ProtoMessage() {}
+
+func (x *AddLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddLinkResponse.ProtoReflect.Descriptor instead.
+func (*AddLinkResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AddLinkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AddLinkResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *AddLinkResponse) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+// 发送数据包请求
+type SendPacketRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 源节点的 gRPC 地址（例如 "localhost:5001"）
+	SourceAddress string `protobuf:"bytes,1,opt,name=source_address,json=sourceAddress,proto3" json:"source_address,omitempty"`
+	// 要发送的数据包
+	Packet        *Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendPacketRequest) Reset() {
+	*x = SendPacketRequest{}
+	mi := &file_node_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendPacketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendPacketRequest) ProtoMessage() {}
+
+func (x *SendPacketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendPacketRequest.ProtoReflect.Descriptor instead.
+func (*SendPacketRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SendPacketRequest) GetSourceAddress() string {
+	if x != nil {
+		return x.SourceAddress
+	}
+	return ""
+}
+
+func (x *SendPacketRequest) GetPacket() *Packet {
+	if x != nil {
+		return x.Packet
+	}
+	return nil
+}
+
+// 发送数据包响应
+type SendPacketResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 数据包 ID（用于追踪）
+	PacketId      string `protobuf:"bytes,3,opt,name=packet_id,json=packetId,proto3" json:"packet_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendPacketResponse) Reset() {
+	*x = SendPacketResponse{}
+	mi := &file_node_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendPacketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendPacketResponse) ProtoMessage() {}
+
+func (x *SendPacketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendPacketResponse.ProtoReflect.Descriptor instead.
+func (*SendPacketResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SendPacketResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SendPacketResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SendPacketResponse) GetPacketId() string {
+	if x != nil {
+		return x.PacketId
+	}
+	return ""
+}
+
+// 启用或禁用同步请求
+type EnableSyncRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 是否启用同步
+	Enabled       bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableSyncRequest) Reset() {
+	*x = EnableSyncRequest{}
+	mi := &file_node_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableSyncRequest) ProtoMessage() {}
+
+func (x *EnableSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableSyncRequest.ProtoReflect.Descriptor instead.
+func (*EnableSyncRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EnableSyncRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// 启用或禁用同步响应
+type EnableSyncResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 当前同步状态
+	Enabled       bool `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableSyncResponse) Reset() {
+	*x = EnableSyncResponse{}
+	mi := &file_node_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableSyncResponse) ProtoMessage() {}
+
+func (x *EnableSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableSyncResponse.ProtoReflect.Descriptor instead.
+func (*EnableSyncResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EnableSyncResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *EnableSyncResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *EnableSyncResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// 路径追踪请求
+type TracerouteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目标节点 ID
+	Destination   string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TracerouteRequest) Reset() {
+	*x = TracerouteRequest{}
+	mi := &file_node_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TracerouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TracerouteRequest) ProtoMessage() {}
+
+func (x *TracerouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TracerouteRequest.ProtoReflect.Descriptor instead.
+func (*TracerouteRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TracerouteRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+// 路径追踪响应
+type TracerouteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 追踪包是否到达目标节点
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 实际经过的每一跳（失败时为到达失败节点为止的部分路径）
+	Hops []*TraceHop `protobuf:"bytes,3,rep,name=hops,proto3" json:"hops,omitempty"`
+	// 本节点路由表计算出的路径
+	ExpectedPath []string `protobuf:"bytes,4,rep,name=expected_path,json=expectedPath,proto3" json:"expected_path,omitempty"`
+	// 实际路径是否与计算路径不一致
+	Diverged bool `protobuf:"varint,5,opt,name=diverged,proto3" json:"diverged,omitempty"`
+	// 总往返时间（纳秒）
+	RttNanos      int64 `protobuf:"varint,6,opt,name=rtt_nanos,json=rttNanos,proto3" json:"rtt_nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TracerouteResponse) Reset() {
+	*x = TracerouteResponse{}
+	mi := &file_node_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TracerouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TracerouteResponse) ProtoMessage() {}
+
+func (x *TracerouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TracerouteResponse.ProtoReflect.Descriptor instead.
+func (*TracerouteResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TracerouteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TracerouteResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TracerouteResponse) GetHops() []*TraceHop {
+	if x != nil {
+		return x.Hops
+	}
+	return nil
+}
+
+func (x *TracerouteResponse) GetExpectedPath() []string {
+	if x != nil {
+		return x.ExpectedPath
+	}
+	return nil
+}
+
+func (x *TracerouteResponse) GetDiverged() bool {
+	if x != nil {
+		return x.Diverged
+	}
+	return false
+}
+
+func (x *TracerouteResponse) GetRttNanos() int64 {
+	if x != nil {
+		return x.RttNanos
+	}
+	return 0
+}
+
+// 删除链路请求
+type RemoveLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 邻居节点 ID
+	Neighbor      string `protobuf:"bytes,1,opt,name=neighbor,proto3" json:"neighbor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLinkRequest) Reset() {
+	*x = RemoveLinkRequest{}
+	mi := &file_node_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLinkRequest) ProtoMessage() {}
+
+func (x *RemoveLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveLinkRequest.ProtoReflect.Descriptor instead.
+func (*RemoveLinkRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RemoveLinkRequest) GetNeighbor() string {
+	if x != nil {
+		return x.Neighbor
+	}
+	return ""
+}
+
+// 删除链路响应
+type RemoveLinkResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLinkResponse) Reset() {
+	*x = RemoveLinkResponse{}
+	mi := &file_node_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLinkResponse) ProtoMessage() {}
+
+func (x *RemoveLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveLinkResponse.ProtoReflect.Descriptor instead.
+func (*RemoveLinkResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveLinkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RemoveLinkResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 修改链路成本请求
+type SetLinkCostRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 邻居节点 ID
+	Neighbor string `protobuf:"bytes,1,opt,name=neighbor,proto3" json:"neighbor,omitempty"`
+	// 手动指定的链路成本（auto_probe 为 false 时必须大于 0）
+	Cost float64 `protobuf:"fixed64,2,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 是否重新自动探测成本；为 false 时使用 cost 并将链路标记为手动固定
+	AutoProbe     bool `protobuf:"varint,3,opt,name=auto_probe,json=autoProbe,proto3" json:"auto_probe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLinkCostRequest) Reset() {
+	*x = SetLinkCostRequest{}
+	mi := &file_node_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLinkCostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLinkCostRequest) ProtoMessage() {}
+
+func (x *SetLinkCostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLinkCostRequest.ProtoReflect.Descriptor instead.
+func (*SetLinkCostRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SetLinkCostRequest) GetNeighbor() string {
+	if x != nil {
+		return x.Neighbor
+	}
+	return ""
+}
+
+func (x *SetLinkCostRequest) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *SetLinkCostRequest) GetAutoProbe() bool {
+	if x != nil {
+		return x.AutoProbe
+	}
+	return false
+}
+
+// 修改链路成本响应
+type SetLinkCostResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 实际使用的链路成本
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 链路成本是否为手动固定
+	Pinned        bool `protobuf:"varint,4,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLinkCostResponse) Reset() {
+	*x = SetLinkCostResponse{}
+	mi := &file_node_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLinkCostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLinkCostResponse) ProtoMessage() {}
+
+func (x *SetLinkCostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLinkCostResponse.ProtoReflect.Descriptor instead.
+func (*SetLinkCostResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SetLinkCostResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetLinkCostResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SetLinkCostResponse) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *SetLinkCostResponse) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+// 路由条目
+type RouteEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目的节点 ID
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 下一跳节点 ID
+	NextHop string `protobuf:"bytes,2,opt,name=next_hop,json=nextHop,proto3" json:"next_hop,omitempty"`
+	// 到目的地的总成本
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 完整路径（从本节点到目的地）
+	Path          []string `protobuf:"bytes,4,rep,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteEntry) Reset() {
+	*x = RouteEntry{}
+	mi := &file_node_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteEntry) ProtoMessage() {}
+
+func (x *RouteEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteEntry.ProtoReflect.Descriptor instead.
+func (*RouteEntry) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RouteEntry) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *RouteEntry) GetNextHop() string {
+	if x != nil {
+		return x.NextHop
+	}
+	return ""
+}
+
+func (x *RouteEntry) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *RouteEntry) GetPath() []string {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+// 查询路由表请求
+type GetRoutesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoutesRequest) Reset() {
+	*x = GetRoutesRequest{}
+	mi := &file_node_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoutesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoutesRequest) ProtoMessage() {}
+
+func (x *GetRoutesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoutesRequest.ProtoReflect.Descriptor instead.
+func (*GetRoutesRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{24}
+}
+
+// 查询路由表响应
+type GetRoutesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 路由表所属节点 ID
+	NodeId string `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// 路由条目（按目的节点排序）
+	Routes        []*RouteEntry `protobuf:"bytes,4,rep,name=routes,proto3" json:"routes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoutesResponse) Reset() {
+	*x = GetRoutesResponse{}
+	mi := &file_node_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoutesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoutesResponse) ProtoMessage() {}
+
+func (x *GetRoutesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoutesResponse.ProtoReflect.Descriptor instead.
+func (*GetRoutesResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetRoutesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetRoutesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetRoutesResponse) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetRoutesResponse) GetRoutes() []*RouteEntry {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+// 查询单条路由请求
+type GetRouteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目的节点 ID
+	Destination   string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRouteRequest) Reset() {
+	*x = GetRouteRequest{}
+	mi := &file_node_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRouteRequest) ProtoMessage() {}
+
+func (x *GetRouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRouteRequest.ProtoReflect.Descriptor instead.
+func (*GetRouteRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetRouteRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+// 查询单条路由响应
+type GetRouteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 是否存在到目的地的路由
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 路由条目
+	Route         *RouteEntry `protobuf:"bytes,3,opt,name=route,proto3" json:"route,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRouteResponse) Reset() {
+	*x = GetRouteResponse{}
+	mi := &file_node_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRouteResponse) ProtoMessage() {}
+
+func (x *GetRouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRouteResponse.ProtoReflect.Descriptor instead.
+func (*GetRouteResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetRouteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetRouteResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetRouteResponse) GetRoute() *RouteEntry {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+// 拓扑中的节点
+type TopologyNode struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 节点 ID
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// gRPC 地址
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// 节点状态（alive / suspect / failed / left / unknown）
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopologyNode) Reset() {
+	*x = TopologyNode{}
+	mi := &file_node_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopologyNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopologyNode) ProtoMessage() {}
+
+func (x *TopologyNode) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopologyNode.ProtoReflect.Descriptor instead.
+func (*TopologyNode) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TopologyNode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TopologyNode) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *TopologyNode) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// 拓扑中的链路
+type TopologyLink struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 链路一端节点 ID
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// 链路另一端节点 ID
+	To string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// 链路成本
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 链路成本是否为手动固定
+	Pinned bool `protobuf:"varint,4,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	// 链路版本号
+	Sequence      int64 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopologyLink) Reset() {
+	*x = TopologyLink{}
+	mi := &file_node_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopologyLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopologyLink) ProtoMessage() {}
+
+func (x *TopologyLink) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopologyLink.ProtoReflect.Descriptor instead.
+func (*TopologyLink) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TopologyLink) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *TopologyLink) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *TopologyLink) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *TopologyLink) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *TopologyLink) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// 查询拓扑请求
+type GetTopologyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopologyRequest) Reset() {
+	*x = GetTopologyRequest{}
+	mi := &file_node_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopologyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopologyRequest) ProtoMessage() {}
+
+func (x *GetTopologyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopologyRequest.ProtoReflect.Descriptor instead.
+func (*GetTopologyRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{30}
+}
+
+// 查询拓扑响应
+type GetTopologyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 查询的节点 ID
+	NodeId string `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// 所有已知节点（按 ID 排序）
+	Nodes []*TopologyNode `protobuf:"bytes,4,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	// 所有链路（无向图每条边只出现一次）
+	Links         []*TopologyLink `protobuf:"bytes,5,rep,name=links,proto3" json:"links,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopologyResponse) Reset() {
+	*x = GetTopologyResponse{}
+	mi := &file_node_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopologyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopologyResponse) ProtoMessage() {}
+
+func (x *GetTopologyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopologyResponse.ProtoReflect.Descriptor instead.
+func (*GetTopologyResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetTopologyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetTopologyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetTopologyResponse) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetTopologyResponse) GetNodes() []*TopologyNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *GetTopologyResponse) GetLinks() []*TopologyLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+// 延迟直方图
+type LatencyHistogram struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 各桶上界（纳秒），最后还有一个 +Inf 桶
+	BoundsNanos []int64 `protobuf:"varint,1,rep,packed,name=bounds_nanos,json=boundsNanos,proto3" json:"bounds_nanos,omitempty"`
+	// 各桶计数，长度为 bounds_nanos 长度 + 1
+	Counts []uint64 `protobuf:"varint,2,rep,packed,name=counts,proto3" json:"counts,omitempty"`
+	// 样本总数
+	Count uint64 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// 样本总和（纳秒）
+	SumNanos int64 `protobuf:"varint,4,opt,name=sum_nanos,json=sumNanos,proto3" json:"sum_nanos,omitempty"`
+	// 最大值（纳秒）
+	MaxNanos      int64 `protobuf:"varint,5,opt,name=max_nanos,json=maxNanos,proto3" json:"max_nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LatencyHistogram) Reset() {
+	*x = LatencyHistogram{}
+	mi := &file_node_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatencyHistogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyHistogram) ProtoMessage() {}
+
+func (x *LatencyHistogram) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyHistogram.ProtoReflect.Descriptor instead.
+func (*LatencyHistogram) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LatencyHistogram) GetBoundsNanos() []int64 {
+	if x != nil {
+		return x.BoundsNanos
+	}
+	return nil
+}
+
+func (x *LatencyHistogram) GetCounts() []uint64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *LatencyHistogram) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *LatencyHistogram) GetSumNanos() int64 {
+	if x != nil {
+		return x.SumNanos
+	}
+	return 0
+}
+
+func (x *LatencyHistogram) GetMaxNanos() int64 {
+	if x != nil {
+		return x.MaxNanos
+	}
+	return 0
+}
+
+// 某个邻居或目标节点的转发统计
+type TrafficStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 对邻居：交给该邻居的次数；对目标：本节点发起的数据包数
+	PacketsSent uint64 `protobuf:"varint,1,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`
+	// 成功转发到下一跳的数据包数
+	PacketsForwarded uint64 `protobuf:"varint,2,opt,name=packets_forwarded,json=packetsForwarded,proto3" json:"packets_forwarded,omitempty"`
+	// 丢弃的数据包数
+	PacketsDropped uint64 `protobuf:"varint,3,opt,name=packets_dropped,json=packetsDropped,proto3" json:"packets_dropped,omitempty"`
+	// 成功转发的载荷字节数
+	BytesForwarded uint64 `protobuf:"varint,4,opt,name=bytes_forwarded,json=bytesForwarded,proto3" json:"bytes_forwarded,omitempty"`
+	// 按原因统计的丢包数
+	DropReasons map[string]uint64 `protobuf:"bytes,5,rep,name=drop_reasons,json=dropReasons,proto3" json:"drop_reasons,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// 下一跳确认延迟
+	Latency       *LatencyHistogram `protobuf:"bytes,6,opt,name=latency,proto3" json:"latency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficStats) Reset() {
+	*x = TrafficStats{}
+	mi := &file_node_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficStats) ProtoMessage() {}
+
+func (x *TrafficStats) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficStats.ProtoReflect.Descriptor instead.
+func (*TrafficStats) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TrafficStats) GetPacketsSent() uint64 {
+	if x != nil {
+		return x.PacketsSent
+	}
+	return 0
+}
+
+func (x *TrafficStats) GetPacketsForwarded() uint64 {
+	if x != nil {
+		return x.PacketsForwarded
+	}
+	return 0
+}
+
+func (x *TrafficStats) GetPacketsDropped() uint64 {
+	if x != nil {
+		return x.PacketsDropped
+	}
+	return 0
+}
+
+func (x *TrafficStats) GetBytesForwarded() uint64 {
+	if x != nil {
+		return x.BytesForwarded
+	}
+	return 0
+}
+
+func (x *TrafficStats) GetDropReasons() map[string]uint64 {
+	if x != nil {
+		return x.DropReasons
+	}
+	return nil
+}
+
+func (x *TrafficStats) GetLatency() *LatencyHistogram {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+// 查询转发统计请求
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_node_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{34}
+}
+
+// 查询转发统计响应
+type GetStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 操作是否成功
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 节点 ID
+	NodeId string `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// 全局计数
+	PacketsSent      uint64 `protobuf:"varint,4,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`
+	PacketsReceived  uint64 `protobuf:"varint,5,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"`
+	PacketsForwarded uint64 `protobuf:"varint,6,opt,name=packets_forwarded,json=packetsForwarded,proto3" json:"packets_forwarded,omitempty"`
+	PacketsDelivered uint64 `protobuf:"varint,7,opt,name=packets_delivered,json=packetsDelivered,proto3" json:"packets_delivered,omitempty"`
+	PacketsDropped   uint64 `protobuf:"varint,8,opt,name=packets_dropped,json=packetsDropped,proto3" json:"packets_dropped,omitempty"`
+	PacketsDuplicate uint64 `protobuf:"varint,9,opt,name=packets_duplicate,json=packetsDuplicate,proto3" json:"packets_duplicate,omitempty"`
+	ControlSent      uint64 `protobuf:"varint,10,opt,name=control_sent,json=controlSent,proto3" json:"control_sent,omitempty"`
+	ControlReceived  uint64 `protobuf:"varint,11,opt,name=control_received,json=controlReceived,proto3" json:"control_received,omitempty"`
+	BytesSent        uint64 `protobuf:"varint,12,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesForwarded   uint64 `protobuf:"varint,13,opt,name=bytes_forwarded,json=bytesForwarded,proto3" json:"bytes_forwarded,omitempty"`
+	BytesDelivered   uint64 `protobuf:"varint,14,opt,name=bytes_delivered,json=bytesDelivered,proto3" json:"bytes_delivered,omitempty"`
+	// 按原因统计的丢包数
+	DropReasons map[string]uint64 `protobuf:"bytes,15,rep,name=drop_reasons,json=dropReasons,proto3" json:"drop_reasons,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// 按邻居（下一跳）细分的统计
+	Neighbors map[string]*TrafficStats `protobuf:"bytes,16,rep,name=neighbors,proto3" json:"neighbors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 按目标节点细分的统计
+	Destinations map[string]*TrafficStats `protobuf:"bytes,17,rep,name=destinations,proto3" json:"destinations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 本节点发布的主题按订阅者细分的投递统计
+	Subscribers   []*SubscriberStats `protobuf:"bytes,18,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_node_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetStatsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetStatsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetPacketsSent() uint64 {
+	if x != nil {
+		return x.PacketsSent
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetPacketsReceived() uint64 {
+	if x != nil {
+		return x.PacketsReceived
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetPacketsForwarded() uint64 {
+	if x != nil {
+		return x.PacketsForwarded
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetPacketsDelivered() uint64 {
+	if x != nil {
+		return x.PacketsDelivered
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetPacketsDropped() uint64 {
+	if x != nil {
+		return x.PacketsDropped
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetPacketsDuplicate() uint64 {
+	if x != nil {
+		return x.PacketsDuplicate
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetControlSent() uint64 {
+	if x != nil {
+		return x.ControlSent
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetControlReceived() uint64 {
+	if x != nil {
+		return x.ControlReceived
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetBytesForwarded() uint64 {
+	if x != nil {
+		return x.BytesForwarded
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetBytesDelivered() uint64 {
+	if x != nil {
+		return x.BytesDelivered
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetDropReasons() map[string]uint64 {
+	if x != nil {
+		return x.DropReasons
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetNeighbors() map[string]*TrafficStats {
+	if x != nil {
+		return x.Neighbors
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetDestinations() map[string]*TrafficStats {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetSubscribers() []*SubscriberStats {
+	if x != nil {
+		return x.Subscribers
+	}
+	return nil
+}
+
+// 发布者视角下某个主题订阅者的投递统计
+type SubscriberStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 主题
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 订阅者节点 ID
+	Subscriber string `protobuf:"bytes,2,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	// 已确认投递的消息数
+	Delivered uint64 `protobuf:"varint,3,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	// 投递失败的消息数
+	Failed uint64 `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	// 已确认投递的字节数
+	BytesDelivered uint64 `protobuf:"varint,5,opt,name=bytes_delivered,json=bytesDelivered,proto3" json:"bytes_delivered,omitempty"`
+	// 最近一次确认投递的时间（Unix 纳秒）
+	LastDeliveredUnixNano int64 `protobuf:"varint,6,opt,name=last_delivered_unix_nano,json=lastDeliveredUnixNano,proto3" json:"last_delivered_unix_nano,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SubscriberStats) Reset() {
+	*x = SubscriberStats{}
+	mi := &file_node_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriberStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriberStats) ProtoMessage() {}
+
+func (x *SubscriberStats) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriberStats.ProtoReflect.Descriptor instead.
+func (*SubscriberStats) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SubscriberStats) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SubscriberStats) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+func (x *SubscriberStats) GetDelivered() uint64 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *SubscriberStats) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *SubscriberStats) GetBytesDelivered() uint64 {
+	if x != nil {
+		return x.BytesDelivered
+	}
+	return 0
+}
+
+func (x *SubscriberStats) GetLastDeliveredUnixNano() int64 {
+	if x != nil {
+		return x.LastDeliveredUnixNano
+	}
+	return 0
+}
+
+// 订阅事件请求
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 要订阅的事件类型，为空表示全部
+	Types         []EventType `protobuf:"varint,1,rep,packed,name=types,proto3,enum=spfnet.EventType" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_node_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *WatchEventsRequest) GetTypes() []EventType {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// 成员、链路或路由变化事件
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 事件类型
+	Type EventType `protobuf:"varint,1,opt,name=type,proto3,enum=spfnet.EventType" json:"type,omitempty"`
+	// 事件发生时间（Unix 纳秒）
+	UnixNano int64 `protobuf:"varint,2,opt,name=unix_nano,json=unixNano,proto3" json:"unix_nano,omitempty"`
+	// 产生事件的节点 ID
+	Reporter string `protobuf:"bytes,3,opt,name=reporter,proto3" json:"reporter,omitempty"`
+	// 成员事件：节点 ID；路由事件：目标节点 ID
+	Node string `protobuf:"bytes,4,opt,name=node,proto3" json:"node,omitempty"`
+	// 成员事件：节点 gRPC 地址
+	Address string `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
+	// 链路事件：链路两端节点 ID
+	From string `protobuf:"bytes,6,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,7,opt,name=to,proto3" json:"to,omitempty"`
+	// 链路事件：链路成本是否为手动固定
+	Pinned bool `protobuf:"varint,8,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	// 链路或路由事件：变化前后的成本
+	OldCost float64 `protobuf:"fixed64,9,opt,name=old_cost,json=oldCost,proto3" json:"old_cost,omitempty"`
+	Cost    float64 `protobuf:"fixed64,10,opt,name=cost,proto3" json:"cost,omitempty"`
+	// 路由事件：变化前后的下一跳
+	OldNextHop string `protobuf:"bytes,11,opt,name=old_next_hop,json=oldNextHop,proto3" json:"old_next_hop,omitempty"`
+	NextHop    string `protobuf:"bytes,12,opt,name=next_hop,json=nextHop,proto3" json:"next_hop,omitempty"`
+	// 路由事件：变化前后的完整路径
+	OldPath       []string `protobuf:"bytes,13,rep,name=old_path,json=oldPath,proto3" json:"old_path,omitempty"`
+	Path          []string `protobuf:"bytes,14,rep,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_node_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetUnixNano() int64 {
+	if x != nil {
+		return x.UnixNano
+	}
+	return 0
+}
+
+func (x *Event) GetReporter() string {
+	if x != nil {
+		return x.Reporter
+	}
+	return ""
+}
+
+func (x *Event) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *Event) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Event) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Event) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Event) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *Event) GetOldCost() float64 {
+	if x != nil {
+		return x.OldCost
+	}
+	return 0
+}
+
+func (x *Event) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *Event) GetOldNextHop() string {
+	if x != nil {
+		return x.OldNextHop
+	}
+	return ""
+}
+
+func (x *Event) GetNextHop() string {
+	if x != nil {
+		return x.NextHop
+	}
+	return ""
+}
+
+func (x *Event) GetOldPath() []string {
+	if x != nil {
+		return x.OldPath
+	}
+	return nil
+}
+
+func (x *Event) GetPath() []string {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+// 多播请求
+type MulticastRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 多播组名（broadcast 为 true 时忽略）
+	Group string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// 是否广播到集群中所有可达节点
+	Broadcast bool `protobuf:"varint,2,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	// 业务数据
+	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 发布的主题（不为空时发送给订阅该主题的所有节点，忽略 group 和 broadcast）
+	Topic         string `protobuf:"bytes,4,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MulticastRequest) Reset() {
+	*x = MulticastRequest{}
+	mi := &file_node_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MulticastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MulticastRequest) ProtoMessage() {}
+
+func (x *MulticastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MulticastRequest.ProtoReflect.Descriptor instead.
+func (*MulticastRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *MulticastRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *MulticastRequest) GetBroadcast() bool {
+	if x != nil {
+		return x.Broadcast
+	}
+	return false
+}
+
+func (x *MulticastRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *MulticastRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+// 多播响应
+type MulticastResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 是否投递到所有目标
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// 返回信息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 数据包 ID
+	PacketId string `protobuf:"bytes,3,opt,name=packet_id,json=packetId,proto3" json:"packet_id,omitempty"`
+	// 本次多播的所有目标节点 ID
+	Targets []string `protobuf:"bytes,4,rep,name=targets,proto3" json:"targets,omitempty"`
+	// 未能投递的目标节点 ID
+	FailedDestinations []string `protobuf:"bytes,5,rep,name=failed_destinations,json=failedDestinations,proto3" json:"failed_destinations,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MulticastResponse) Reset() {
+	*x = MulticastResponse{}
+	mi := &file_node_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MulticastResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MulticastResponse) ProtoMessage() {}
+
+func (x *MulticastResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MulticastResponse.ProtoReflect.Descriptor instead.
+func (*MulticastResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *MulticastResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *MulticastResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *MulticastResponse) GetPacketId() string {
+	if x != nil {
+		return x.PacketId
+	}
+	return ""
+}
+
+func (x *MulticastResponse) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *MulticastResponse) GetFailedDestinations() []string {
+	if x != nil {
+		return x.FailedDestinations
+	}
+	return nil
+}
+
+// 端口转发隧道及其统计
+type TunnelInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 隧道名称
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 本节点监听的 TCP 地址
+	Listen string `protobuf:"bytes,2,opt,name=listen,proto3" json:"listen,omitempty"`
+	// 出口节点 ID
+	Node string `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"`
+	// 出口节点连接的 TCP 地址
+	Target string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	// 同时活跃的连接数上限（0 表示不限制）
+	MaxConns int32 `protobuf:"varint,5,opt,name=max_conns,json=maxConns,proto3" json:"max_conns,omitempty"`
+	// 当前活跃的连接数
+	ActiveConns int64 `protobuf:"varint,6,opt,name=active_conns,json=activeConns,proto3" json:"active_conns,omitempty"`
+	// 累计接受的连接数
+	TotalConns uint64 `protobuf:"varint,7,opt,name=total_conns,json=totalConns,proto3" json:"total_conns,omitempty"`
+	// 因超过连接数上限被拒绝的连接数
+	RejectedConns uint64 `protobuf:"varint,8,opt,name=rejected_conns,json=rejectedConns,proto3" json:"rejected_conns,omitempty"`
+	// 未能建立到出口节点或目标地址的连接数
+	FailedConns uint64 `protobuf:"varint,9,opt,name=failed_conns,json=failedConns,proto3" json:"failed_conns,omitempty"`
+	// 从本地客户端发往目标的字节数
+	BytesSent uint64 `protobuf:"varint,10,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	// 从目标返回给本地客户端的字节数
+	BytesReceived uint64 `protobuf:"varint,11,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TunnelInfo) Reset() {
+	*x = TunnelInfo{}
+	mi := &file_node_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TunnelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TunnelInfo) ProtoMessage() {}
+
+func (x *TunnelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TunnelInfo.ProtoReflect.Descriptor instead.
+func (*TunnelInfo) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *TunnelInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TunnelInfo) GetListen() string {
+	if x != nil {
+		return x.Listen
+	}
+	return ""
+}
+
+func (x *TunnelInfo) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *TunnelInfo) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *TunnelInfo) GetMaxConns() int32 {
+	if x != nil {
+		return x.MaxConns
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetActiveConns() int64 {
+	if x != nil {
+		return x.ActiveConns
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetTotalConns() uint64 {
+	if x != nil {
+		return x.TotalConns
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetRejectedConns() uint64 {
+	if x != nil {
+		return x.RejectedConns
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetFailedConns() uint64 {
+	if x != nil {
+		return x.FailedConns
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *TunnelInfo) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+// 添加隧道请求
+type AddTunnelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 隧道名称（为空时使用监听地址）
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 本节点监听的 TCP 地址，如 "127.0.0.1:8080"
+	Listen string `protobuf:"bytes,2,opt,name=listen,proto3" json:"listen,omitempty"`
+	// 出口节点 ID
+	Node string `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"`
+	// 出口节点连接的 TCP 地址，如 "127.0.0.1:9000"
+	Target string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	// 同时活跃的连接数上限（0 表示不限制）
+	MaxConns      int32 `protobuf:"varint,5,opt,name=max_conns,json=maxConns,proto3" json:"max_conns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTunnelRequest) Reset() {
+	*x = AddTunnelRequest{}
+	mi := &file_node_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTunnelRequest) ProtoMessage() {}
+
+func (x *AddTunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTunnelRequest.ProtoReflect.Descriptor instead.
+func (*AddTunnelRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AddTunnelRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddTunnelRequest) GetListen() string {
+	if x != nil {
+		return x.Listen
+	}
+	return ""
+}
+
+func (x *AddTunnelRequest) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *AddTunnelRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AddTunnelRequest) GetMaxConns() int32 {
+	if x != nil {
+		return x.MaxConns
+	}
+	return 0
+}
+
+// 添加隧道响应
+type AddTunnelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Tunnel        *TunnelInfo            `protobuf:"bytes,3,opt,name=tunnel,proto3" json:"tunnel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTunnelResponse) Reset() {
+	*x = AddTunnelResponse{}
+	mi := &file_node_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTunnelResponse) ProtoMessage() {}
+
+func (x *AddTunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTunnelResponse.ProtoReflect.Descriptor instead.
+func (*AddTunnelResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AddTunnelResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AddTunnelResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *AddTunnelResponse) GetTunnel() *TunnelInfo {
+	if x != nil {
+		return x.Tunnel
+	}
+	return nil
+}
+
+// 删除隧道请求
+type RemoveTunnelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveTunnelRequest) Reset() {
+	*x = RemoveTunnelRequest{}
+	mi := &file_node_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTunnelRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Packet) ProtoMessage() {}
+func (*RemoveTunnelRequest) ProtoMessage() {}
 
-func (x *Packet) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[0]
+func (x *RemoveTunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -65,78 +3767,133 @@ func (x *Packet) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
-func (*Packet) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use RemoveTunnelRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTunnelRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *Packet) GetSource() string {
+func (x *RemoveTunnelRequest) GetName() string {
 	if x != nil {
-		return x.Source
+		return x.Name
 	}
 	return ""
 }
 
-func (x *Packet) GetDestination() string {
+// 删除隧道响应
+type RemoveTunnelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveTunnelResponse) Reset() {
+	*x = RemoveTunnelResponse{}
+	mi := &file_node_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTunnelResponse) ProtoMessage() {}
+
+func (x *RemoveTunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[45]
 	if x != nil {
-		return x.Destination
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Packet) GetNextHop() string {
+// Deprecated: Use RemoveTunnelResponse.ProtoReflect.Descriptor instead.
+func (*RemoveTunnelResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *RemoveTunnelResponse) GetSuccess() bool {
 	if x != nil {
-		return x.NextHop
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *Packet) GetPacketId() string {
+func (x *RemoveTunnelResponse) GetMessage() string {
 	if x != nil {
-		return x.PacketId
+		return x.Message
 	}
 	return ""
 }
 
-func (x *Packet) GetPayload() []byte {
-	if x != nil {
-		return x.Payload
-	}
-	return nil
+// 查询隧道请求
+type ListTunnelsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Packet) GetVisitedNodes() []string {
+func (x *ListTunnelsRequest) Reset() {
+	*x = ListTunnelsRequest{}
+	mi := &file_node_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTunnelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTunnelsRequest) ProtoMessage() {}
+
+func (x *ListTunnelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[46]
 	if x != nil {
-		return x.VisitedNodes
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type ForwardResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// 本次转发是否成功投递到下一跳
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	// 出错时的信息（例如目标不可达）
-	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+// Deprecated: Use ListTunnelsRequest.ProtoReflect.Descriptor instead.
+func (*ListTunnelsRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{46}
+}
+
+// 查询隧道响应
+type ListTunnelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Tunnels       []*TunnelInfo          `protobuf:"bytes,3,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ForwardResponse) Reset() {
-	*x = ForwardResponse{}
-	mi := &file_node_proto_msgTypes[1]
+func (x *ListTunnelsResponse) Reset() {
+	*x = ListTunnelsResponse{}
+	mi := &file_node_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ForwardResponse) String() string {
+func (x *ListTunnelsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ForwardResponse) ProtoMessage() {}
+func (*ListTunnelsResponse) ProtoMessage() {}
 
-func (x *ForwardResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[1]
+func (x *ListTunnelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,53 +3904,60 @@ func (x *ForwardResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ForwardResponse.ProtoReflect.Descriptor instead.
-func (*ForwardResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ListTunnelsResponse.ProtoReflect.Descriptor instead.
+func (*ListTunnelsResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *ForwardResponse) GetSuccess() bool {
+func (x *ListTunnelsResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ForwardResponse) GetMessage() string {
+func (x *ListTunnelsResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-// 链路质量探测请求
-type ProbeRequest struct {
+func (x *ListTunnelsResponse) GetTunnels() []*TunnelInfo {
+	if x != nil {
+		return x.Tunnels
+	}
+	return nil
+}
+
+// 发送文件请求
+type SendFileRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// 发起探测的节点 ID（可选，用于调试）
-	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
-	// 目标节点 ID（要探测到哪个邻居）
-	Target string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
-	// 自我调试模式（随机生成开销）
-	SelfDebug     bool `protobuf:"varint,3,opt,name=self_debug,json=selfDebug,proto3" json:"self_debug,omitempty"`
+	// 目标节点 ID
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 要发送的文件路径（接收请求的节点上的路径）
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// 目标节点收件目录中的文件名，为空时使用源文件名
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProbeRequest) Reset() {
-	*x = ProbeRequest{}
-	mi := &file_node_proto_msgTypes[2]
+func (x *SendFileRequest) Reset() {
+	*x = SendFileRequest{}
+	mi := &file_node_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProbeRequest) String() string {
+func (x *SendFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProbeRequest) ProtoMessage() {}
+func (*SendFileRequest) ProtoMessage() {}
 
-func (x *ProbeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[2]
+func (x *SendFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,62 +3968,70 @@ func (x *ProbeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProbeRequest.ProtoReflect.Descriptor instead.
-func (*ProbeRequest) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use SendFileRequest.ProtoReflect.Descriptor instead.
+func (*SendFileRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *ProbeRequest) GetSource() string {
+func (x *SendFileRequest) GetDestination() string {
 	if x != nil {
-		return x.Source
+		return x.Destination
 	}
 	return ""
 }
 
-func (x *ProbeRequest) GetTarget() string {
+func (x *SendFileRequest) GetPath() string {
 	if x != nil {
-		return x.Target
+		return x.Path
 	}
 	return ""
 }
 
-func (x *ProbeRequest) GetSelfDebug() bool {
+func (x *SendFileRequest) GetName() string {
 	if x != nil {
-		return x.SelfDebug
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-// 链路质量探测响应
-type ProbeResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// 探测是否成功
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	// 单位毫秒的 RTT（往返时间）
-	RttMs int64 `protobuf:"varint,2,opt,name=rtt_ms,json=rttMs,proto3" json:"rtt_ms,omitempty"`
-	// 根据 rtt 计算出的 cost（你自己定义算法）
-	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
-	// 出错信息
-	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+// 发送文件响应
+type SendFileResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 目标节点收件目录中的文件名
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// 文件大小（字节）
+	Size int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	// 文件的 SHA-256 校验和（十六进制）
+	Sha256 string `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// 从该偏移处续传（此前已传输的字节数）
+	ResumedFrom int64 `protobuf:"varint,6,opt,name=resumed_from,json=resumedFrom,proto3" json:"resumed_from,omitempty"`
+	// 本次实际发送的字节数
+	BytesSent int64 `protobuf:"varint,7,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	// 连接尝试次数
+	Attempts int32 `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// 传输耗时（毫秒）
+	DurationMs    int64 `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProbeResponse) Reset() {
-	*x = ProbeResponse{}
-	mi := &file_node_proto_msgTypes[3]
+func (x *SendFileResponse) Reset() {
+	*x = SendFileResponse{}
+	mi := &file_node_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProbeResponse) String() string {
+func (x *SendFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProbeResponse) ProtoMessage() {}
+func (*SendFileResponse) ProtoMessage() {}
 
-func (x *ProbeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[3]
+func (x *SendFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -270,62 +4042,173 @@ func (x *ProbeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProbeResponse.ProtoReflect.Descriptor instead.
-func (*ProbeResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SendFileResponse.ProtoReflect.Descriptor instead.
+func (*SendFileResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *ProbeResponse) GetSuccess() bool {
+func (x *SendFileResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ProbeResponse) GetRttMs() int64 {
+func (x *SendFileResponse) GetMessage() string {
 	if x != nil {
-		return x.RttMs
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SendFileResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SendFileResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
 	}
 	return 0
 }
 
-func (x *ProbeResponse) GetCost() float64 {
+func (x *SendFileResponse) GetSha256() string {
 	if x != nil {
-		return x.Cost
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *SendFileResponse) GetResumedFrom() int64 {
+	if x != nil {
+		return x.ResumedFrom
 	}
 	return 0
 }
 
-func (x *ProbeResponse) GetMessage() string {
+func (x *SendFileResponse) GetBytesSent() int64 {
 	if x != nil {
-		return x.Message
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *SendFileResponse) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *SendFileResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// 应用发送请求
+type AppSendRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目标节点 ID（按服务名发送时留空）
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 业务数据
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 目标服务名（不为空时发送给最近的服务提供者，忽略 destination）
+	Service string `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	// 元数据（如 content-type），随数据送达目标节点
+	Headers       map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppSendRequest) Reset() {
+	*x = AppSendRequest{}
+	mi := &file_node_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppSendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppSendRequest) ProtoMessage() {}
+
+func (x *AppSendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppSendRequest.ProtoReflect.Descriptor instead.
+func (*AppSendRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *AppSendRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
 	}
 	return ""
 }
 
-// 健康检查
-type PingRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Msg           string                 `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+func (x *AppSendRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *AppSendRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *AppSendRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// 应用发送响应
+type AppSendResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 实际接收数据的节点 ID
+	Destination   string `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PingRequest) Reset() {
-	*x = PingRequest{}
-	mi := &file_node_proto_msgTypes[4]
+func (x *AppSendResponse) Reset() {
+	*x = AppSendResponse{}
+	mi := &file_node_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PingRequest) String() string {
+func (x *AppSendResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingRequest) ProtoMessage() {}
+func (*AppSendResponse) ProtoMessage() {}
 
-func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[4]
+func (x *AppSendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -336,40 +4219,54 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
-func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AppSendResponse.ProtoReflect.Descriptor instead.
+func (*AppSendResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *PingRequest) GetMsg() string {
+func (x *AppSendResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Msg
+		return x.Success
+	}
+	return false
+}
+
+func (x *AppSendResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type PingResponse struct {
+func (x *AppSendResponse) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+// 应用接收请求
+type AppReceiveRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Msg           string                 `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PingResponse) Reset() {
-	*x = PingResponse{}
-	mi := &file_node_proto_msgTypes[5]
+func (x *AppReceiveRequest) Reset() {
+	*x = AppReceiveRequest{}
+	mi := &file_node_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PingResponse) String() string {
+func (x *AppReceiveRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingResponse) ProtoMessage() {}
+func (*AppReceiveRequest) ProtoMessage() {}
 
-func (x *PingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[5]
+func (x *AppReceiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -380,48 +4277,53 @@ func (x *PingResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
-func (*PingResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *PingResponse) GetMsg() string {
-	if x != nil {
-		return x.Msg
-	}
-	return ""
+// Deprecated: Use AppReceiveRequest.ProtoReflect.Descriptor instead.
+func (*AppReceiveRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{52}
 }
 
-// 添加链路请求
-type AddLinkRequest struct {
+// 投递到本节点的数据
+type AppMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// 目标邻居节点 ID
-	Neighbor string `protobuf:"bytes,1,opt,name=neighbor,proto3" json:"neighbor,omitempty"`
-	// 邻居节点的 gRPC 地址（例如 "localhost:50052"）
-	NeighborAddress string `protobuf:"bytes,2,opt,name=neighbor_address,json=neighborAddress,proto3" json:"neighbor_address,omitempty"`
-	// 链路成本（可选，如果不提供则自动探测）
-	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
-	// 是否自动探测成本（默认 true）
-	AutoProbe     bool `protobuf:"varint,4,opt,name=auto_probe,json=autoProbe,proto3" json:"auto_probe,omitempty"`
+	// 数据包 ID
+	PacketId string `protobuf:"bytes,1,opt,name=packet_id,json=packetId,proto3" json:"packet_id,omitempty"`
+	// 源节点 ID
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// 目的节点 ID
+	Destination string `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 数据包经过的节点
+	Path []string `protobuf:"bytes,4,rep,name=path,proto3" json:"path,omitempty"`
+	// 业务数据
+	Payload []byte `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 按服务名发送时的目标服务
+	Service string `protobuf:"bytes,6,opt,name=service,proto3" json:"service,omitempty"`
+	// 多播组名（多播或广播消息）
+	Group string `protobuf:"bytes,7,opt,name=group,proto3" json:"group,omitempty"`
+	// 主题（按主题发布的消息）
+	Topic string `protobuf:"bytes,8,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 请求 ID（不为空时发送方在等待应答，应通过 Reply 回复）
+	RequestId string `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// 发送方设置的元数据
+	Headers       map[string]string `protobuf:"bytes,10,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddLinkRequest) Reset() {
-	*x = AddLinkRequest{}
-	mi := &file_node_proto_msgTypes[6]
+func (x *AppMessage) Reset() {
+	*x = AppMessage{}
+	mi := &file_node_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddLinkRequest) String() string {
+func (x *AppMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddLinkRequest) ProtoMessage() {}
+func (*AppMessage) ProtoMessage() {}
 
-func (x *AddLinkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[6]
+func (x *AppMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -432,129 +4334,109 @@ func (x *AddLinkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddLinkRequest.ProtoReflect.Descriptor instead.
-func (*AddLinkRequest) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use AppMessage.ProtoReflect.Descriptor instead.
+func (*AppMessage) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *AddLinkRequest) GetNeighbor() string {
+func (x *AppMessage) GetPacketId() string {
 	if x != nil {
-		return x.Neighbor
+		return x.PacketId
 	}
 	return ""
 }
 
-func (x *AddLinkRequest) GetNeighborAddress() string {
+func (x *AppMessage) GetSource() string {
 	if x != nil {
-		return x.NeighborAddress
+		return x.Source
 	}
 	return ""
 }
 
-func (x *AddLinkRequest) GetCost() float64 {
+func (x *AppMessage) GetDestination() string {
 	if x != nil {
-		return x.Cost
+		return x.Destination
 	}
-	return 0
+	return ""
 }
 
-func (x *AddLinkRequest) GetAutoProbe() bool {
+func (x *AppMessage) GetPath() []string {
 	if x != nil {
-		return x.AutoProbe
+		return x.Path
 	}
-	return false
-}
-
-// 添加链路响应
-type AddLinkResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// 操作是否成功
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	// 返回信息
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	// 实际使用的链路成本
-	Cost          float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *AddLinkResponse) Reset() {
-	*x = AddLinkResponse{}
-	mi := &file_node_proto_msgTypes[7]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+	return nil
 }
 
-func (x *AddLinkResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *AppMessage) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
 }
 
-func (*AddLinkResponse) ProtoMessage() {}
-
-func (x *AddLinkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[7]
+func (x *AppMessage) GetService() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Service
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use AddLinkResponse.ProtoReflect.Descriptor instead.
-func (*AddLinkResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{7}
+func (x *AppMessage) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
 }
 
-func (x *AddLinkResponse) GetSuccess() bool {
+func (x *AppMessage) GetTopic() string {
 	if x != nil {
-		return x.Success
+		return x.Topic
 	}
-	return false
+	return ""
 }
 
-func (x *AddLinkResponse) GetMessage() string {
+func (x *AppMessage) GetRequestId() string {
 	if x != nil {
-		return x.Message
+		return x.RequestId
 	}
 	return ""
 }
 
-func (x *AddLinkResponse) GetCost() float64 {
+func (x *AppMessage) GetHeaders() map[string]string {
 	if x != nil {
-		return x.Cost
+		return x.Headers
 	}
-	return 0
+	return nil
 }
 
-// 发送数据包请求
-type SendPacketRequest struct {
+// 应用请求
+type AppRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// 源节点的 gRPC 地址（例如 "localhost:5001"）
-	SourceAddress string `protobuf:"bytes,1,opt,name=source_address,json=sourceAddress,proto3" json:"source_address,omitempty"`
-	// 要发送的数据包
-	Packet        *Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet,omitempty"`
+	// 目标节点 ID
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 请求数据
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 等待应答的超时（毫秒），0 表示使用默认值 10 秒
+	TimeoutMs     int64 `protobuf:"varint,3,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendPacketRequest) Reset() {
-	*x = SendPacketRequest{}
-	mi := &file_node_proto_msgTypes[8]
+func (x *AppRequest) Reset() {
+	*x = AppRequest{}
+	mi := &file_node_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendPacketRequest) String() string {
+func (x *AppRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendPacketRequest) ProtoMessage() {}
+func (*AppRequest) ProtoMessage() {}
 
-func (x *SendPacketRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[8]
+func (x *AppRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -565,53 +4447,60 @@ func (x *SendPacketRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendPacketRequest.ProtoReflect.Descriptor instead.
-func (*SendPacketRequest) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use AppRequest.ProtoReflect.Descriptor instead.
+func (*AppRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *SendPacketRequest) GetSourceAddress() string {
+func (x *AppRequest) GetDestination() string {
 	if x != nil {
-		return x.SourceAddress
+		return x.Destination
 	}
 	return ""
 }
 
-func (x *SendPacketRequest) GetPacket() *Packet {
+func (x *AppRequest) GetPayload() []byte {
 	if x != nil {
-		return x.Packet
+		return x.Payload
 	}
 	return nil
 }
 
-// 发送数据包响应
-type SendPacketResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// 操作是否成功
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	// 返回信息
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	// 数据包 ID（用于追踪）
-	PacketId      string `protobuf:"bytes,3,opt,name=packet_id,json=packetId,proto3" json:"packet_id,omitempty"`
+func (x *AppRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+// 应用请求的应答
+type AppResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 应答的节点 ID
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// 应答数据
+	Payload       []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendPacketResponse) Reset() {
-	*x = SendPacketResponse{}
-	mi := &file_node_proto_msgTypes[9]
+func (x *AppResponse) Reset() {
+	*x = AppResponse{}
+	mi := &file_node_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendPacketResponse) String() string {
+func (x *AppResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendPacketResponse) ProtoMessage() {}
+func (*AppResponse) ProtoMessage() {}
 
-func (x *SendPacketResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[9]
+func (x *AppResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -622,56 +4511,67 @@ func (x *SendPacketResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendPacketResponse.ProtoReflect.Descriptor instead.
-func (*SendPacketResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use AppResponse.ProtoReflect.Descriptor instead.
+func (*AppResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *SendPacketResponse) GetSuccess() bool {
+func (x *AppResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *SendPacketResponse) GetMessage() string {
+func (x *AppResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *SendPacketResponse) GetPacketId() string {
+func (x *AppResponse) GetSource() string {
 	if x != nil {
-		return x.PacketId
+		return x.Source
 	}
 	return ""
 }
 
-// 启用或禁用同步请求
-type EnableSyncRequest struct {
+func (x *AppResponse) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// 应答请求
+type AppReplyRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// 是否启用同步
-	Enabled       bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// 请求的源节点 ID（AppMessage.source）
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// 所应答的请求 ID（AppMessage.request_id）
+	RequestId string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// 应答数据
+	Payload       []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EnableSyncRequest) Reset() {
-	*x = EnableSyncRequest{}
-	mi := &file_node_proto_msgTypes[10]
+func (x *AppReplyRequest) Reset() {
+	*x = AppReplyRequest{}
+	mi := &file_node_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EnableSyncRequest) String() string {
+func (x *AppReplyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EnableSyncRequest) ProtoMessage() {}
+func (*AppReplyRequest) ProtoMessage() {}
 
-func (x *EnableSyncRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[10]
+func (x *AppReplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -682,46 +4582,56 @@ func (x *EnableSyncRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EnableSyncRequest.ProtoReflect.Descriptor instead.
-func (*EnableSyncRequest) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use AppReplyRequest.ProtoReflect.Descriptor instead.
+func (*AppReplyRequest) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *EnableSyncRequest) GetEnabled() bool {
+func (x *AppReplyRequest) GetDestination() string {
 	if x != nil {
-		return x.Enabled
+		return x.Destination
 	}
-	return false
+	return ""
 }
 
-// 启用或禁用同步响应
-type EnableSyncResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// 操作是否成功
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	// 返回信息
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	// 当前同步状态
-	Enabled       bool `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+func (x *AppReplyRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *AppReplyRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// 应答响应
+type AppReplyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EnableSyncResponse) Reset() {
-	*x = EnableSyncResponse{}
-	mi := &file_node_proto_msgTypes[11]
+func (x *AppReplyResponse) Reset() {
+	*x = AppReplyResponse{}
+	mi := &file_node_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EnableSyncResponse) String() string {
+func (x *AppReplyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EnableSyncResponse) ProtoMessage() {}
+func (*AppReplyResponse) ProtoMessage() {}
 
-func (x *EnableSyncResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_node_proto_msgTypes[11]
+func (x *AppReplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -732,48 +4642,102 @@ func (x *EnableSyncResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EnableSyncResponse.ProtoReflect.Descriptor instead.
-func (*EnableSyncResponse) Descriptor() ([]byte, []int) {
-	return file_node_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use AppReplyResponse.ProtoReflect.Descriptor instead.
+func (*AppReplyResponse) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{57}
 }
 
-func (x *EnableSyncResponse) GetSuccess() bool {
+func (x *AppReplyResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *EnableSyncResponse) GetMessage() string {
+func (x *AppReplyResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *EnableSyncResponse) GetEnabled() bool {
-	if x != nil {
-		return x.Enabled
-	}
-	return false
-}
-
 var File_node_proto protoreflect.FileDescriptor
 
 const file_node_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"node.proto\x12\x06spfnet\"\xb9\x01\n" +
+	"node.proto\x12\x06spfnet\"\xa6\a\n" +
 	"\x06Packet\x12\x16\n" +
 	"\x06source\x18\x01 \x01(\tR\x06source\x12 \n" +
 	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x19\n" +
 	"\bnext_hop\x18\x03 \x01(\tR\anextHop\x12\x1b\n" +
 	"\tpacket_id\x18\x04 \x01(\tR\bpacketId\x12\x18\n" +
 	"\apayload\x18\x05 \x01(\fR\apayload\x12#\n" +
-	"\rvisited_nodes\x18\x06 \x03(\tR\fvisitedNodes\"E\n" +
+	"\rvisited_nodes\x18\x06 \x03(\tR\fvisitedNodes\x12&\n" +
+	"\x04type\x18\a \x01(\x0e2\x12.spfnet.PacketTypeR\x04type\x12\x10\n" +
+	"\x03ttl\x18\b \x01(\rR\x03ttl\x120\n" +
+	"\acontrol\x18\t \x01(\v2\x16.spfnet.ControlMessageR\acontrol\x12\x14\n" +
+	"\x05trace\x18\n" +
+	" \x01(\bR\x05trace\x12/\n" +
+	"\n" +
+	"trace_hops\x18\v \x03(\v2\x10.spfnet.TraceHopR\ttraceHops\x12\x17\n" +
+	"\aflow_id\x18\f \x01(\tR\x06flowId\x12\x1a\n" +
+	"\bsequence\x18\r \x01(\x04R\bsequence\x12\x18\n" +
+	"\aservice\x18\x0e \x01(\tR\aservice\x12D\n" +
+	"\x11multicast_targets\x18\x0f \x03(\v2\x17.spfnet.MulticastTargetR\x10multicastTargets\x12\x14\n" +
+	"\x05group\x18\x10 \x01(\tR\x05group\x12\x14\n" +
+	"\x05topic\x18\x11 \x01(\tR\x05topic\x12-\n" +
+	"\x06stream\x18\x12 \x01(\v2\x15.spfnet.StreamSegmentR\x06stream\x12'\n" +
+	"\x0fdatagram_target\x18\x13 \x01(\tR\x0edatagramTarget\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x14 \x01(\tR\trequestId\x12\x19\n" +
+	"\breply_to\x18\x15 \x01(\tR\areplyTo\x125\n" +
+	"\aheaders\x18\x16 \x03(\v2\x1b.spfnet.Packet.HeadersEntryR\aheaders\x125\n" +
+	"\vcompression\x18\x17 \x01(\x0e2\x13.spfnet.CompressionR\vcompression\x12\x1c\n" +
+	"\tencrypted\x18\x18 \x01(\bR\tencrypted\x12\x1d\n" +
+	"\n" +
+	"flow_epoch\x18\x19 \x01(\x04R\tflowEpoch\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x01\n" +
+	"\rSealedPayload\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x12<\n" +
+	"\aheaders\x18\x02 \x03(\v2\".spfnet.SealedPayload.HeadersEntryR\aheaders\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe1\x01\n" +
+	"\rStreamSegment\x12\x19\n" +
+	"\bsrc_port\x18\x01 \x01(\rR\asrcPort\x12\x19\n" +
+	"\bdst_port\x18\x02 \x01(\rR\adstPort\x12\x17\n" +
+	"\aconn_id\x18\x03 \x01(\x04R\x06connId\x12-\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x19.spfnet.StreamSegmentTypeR\x04type\x12\x10\n" +
+	"\x03seq\x18\x05 \x01(\x04R\x03seq\x12\x10\n" +
+	"\x03ack\x18\x06 \x01(\x04R\x03ack\x12\x16\n" +
+	"\x06window\x18\a \x01(\rR\x06window\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\"G\n" +
+	"\x0fMulticastTarget\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x12\n" +
+	"\x04path\x18\x02 \x03(\tR\x04path\"\xc2\x01\n" +
+	"\bTraceHop\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12$\n" +
+	"\x0erecv_unix_nano\x18\x02 \x01(\x03R\frecvUnixNano\x12*\n" +
+	"\x11forward_unix_nano\x18\x03 \x01(\x03R\x0fforwardUnixNano\x12\x19\n" +
+	"\bnext_hop\x18\x04 \x01(\tR\anextHop\x120\n" +
+	"\x14downstream_rtt_nanos\x18\x05 \x01(\x03R\x12downstreamRttNanos\"\xf8\x01\n" +
+	"\x0eControlMessage\x12'\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x13.spfnet.ControlCodeR\x04code\x12\x1a\n" +
+	"\breporter\x18\x02 \x01(\tR\breporter\x12,\n" +
+	"\x12original_packet_id\x18\x03 \x01(\tR\x10originalPacketId\x121\n" +
+	"\x14original_destination\x18\x04 \x01(\tR\x13originalDestination\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12(\n" +
+	"\x10max_payload_size\x18\x06 \x01(\rR\x0emaxPayloadSize\"\xd9\x01\n" +
 	"\x0fForwardResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"]\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\acontrol\x18\x03 \x01(\v2\x16.spfnet.ControlMessageR\acontrol\x12/\n" +
+	"\n" +
+	"trace_hops\x18\x04 \x03(\v2\x10.spfnet.TraceHopR\ttraceHops\x12/\n" +
+	"\x13failed_destinations\x18\x05 \x03(\tR\x12failedDestinations\"]\n" +
 	"\fProbeRequest\x12\x16\n" +
 	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
 	"\x06target\x18\x02 \x01(\tR\x06target\x12\x1d\n" +
@@ -810,18 +4774,324 @@ const file_node_proto_rawDesc = "" +
 	"\x12EnableSyncResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
-	"\aenabled\x18\x03 \x01(\bR\aenabled2\xbb\x01\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\"5\n" +
+	"\x11TracerouteRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\"\xcc\x01\n" +
+	"\x12TracerouteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12$\n" +
+	"\x04hops\x18\x03 \x03(\v2\x10.spfnet.TraceHopR\x04hops\x12#\n" +
+	"\rexpected_path\x18\x04 \x03(\tR\fexpectedPath\x12\x1a\n" +
+	"\bdiverged\x18\x05 \x01(\bR\bdiverged\x12\x1b\n" +
+	"\trtt_nanos\x18\x06 \x01(\x03R\brttNanos\"/\n" +
+	"\x11RemoveLinkRequest\x12\x1a\n" +
+	"\bneighbor\x18\x01 \x01(\tR\bneighbor\"H\n" +
+	"\x12RemoveLinkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"c\n" +
+	"\x12SetLinkCostRequest\x12\x1a\n" +
+	"\bneighbor\x18\x01 \x01(\tR\bneighbor\x12\x12\n" +
+	"\x04cost\x18\x02 \x01(\x01R\x04cost\x12\x1d\n" +
+	"\n" +
+	"auto_probe\x18\x03 \x01(\bR\tautoProbe\"u\n" +
+	"\x13SetLinkCostResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06pinned\x18\x04 \x01(\bR\x06pinned\"q\n" +
+	"\n" +
+	"RouteEntry\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x19\n" +
+	"\bnext_hop\x18\x02 \x01(\tR\anextHop\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x01R\x04cost\x12\x12\n" +
+	"\x04path\x18\x04 \x03(\tR\x04path\"\x12\n" +
+	"\x10GetRoutesRequest\"\x8c\x01\n" +
+	"\x11GetRoutesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12*\n" +
+	"\x06routes\x18\x04 \x03(\v2\x12.spfnet.RouteEntryR\x06routes\"3\n" +
+	"\x0fGetRouteRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\"p\n" +
+	"\x10GetRouteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12(\n" +
+	"\x05route\x18\x03 \x01(\v2\x12.spfnet.RouteEntryR\x05route\"P\n" +
+	"\fTopologyNode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"z\n" +
+	"\fTopologyLink\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06pinned\x18\x04 \x01(\bR\x06pinned\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x03R\bsequence\"\x14\n" +
+	"\x12GetTopologyRequest\"\xba\x01\n" +
+	"\x13GetTopologyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12*\n" +
+	"\x05nodes\x18\x04 \x03(\v2\x14.spfnet.TopologyNodeR\x05nodes\x12*\n" +
+	"\x05links\x18\x05 \x03(\v2\x14.spfnet.TopologyLinkR\x05links\"\x9d\x01\n" +
+	"\x10LatencyHistogram\x12!\n" +
+	"\fbounds_nanos\x18\x01 \x03(\x03R\vboundsNanos\x12\x16\n" +
+	"\x06counts\x18\x02 \x03(\x04R\x06counts\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x04R\x05count\x12\x1b\n" +
+	"\tsum_nanos\x18\x04 \x01(\x03R\bsumNanos\x12\x1b\n" +
+	"\tmax_nanos\x18\x05 \x01(\x03R\bmaxNanos\"\xee\x02\n" +
+	"\fTrafficStats\x12!\n" +
+	"\fpackets_sent\x18\x01 \x01(\x04R\vpacketsSent\x12+\n" +
+	"\x11packets_forwarded\x18\x02 \x01(\x04R\x10packetsForwarded\x12'\n" +
+	"\x0fpackets_dropped\x18\x03 \x01(\x04R\x0epacketsDropped\x12'\n" +
+	"\x0fbytes_forwarded\x18\x04 \x01(\x04R\x0ebytesForwarded\x12H\n" +
+	"\fdrop_reasons\x18\x05 \x03(\v2%.spfnet.TrafficStats.DropReasonsEntryR\vdropReasons\x122\n" +
+	"\alatency\x18\x06 \x01(\v2\x18.spfnet.LatencyHistogramR\alatency\x1a>\n" +
+	"\x10DropReasonsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\x11\n" +
+	"\x0fGetStatsRequest\"\xa7\b\n" +
+	"\x10GetStatsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12!\n" +
+	"\fpackets_sent\x18\x04 \x01(\x04R\vpacketsSent\x12)\n" +
+	"\x10packets_received\x18\x05 \x01(\x04R\x0fpacketsReceived\x12+\n" +
+	"\x11packets_forwarded\x18\x06 \x01(\x04R\x10packetsForwarded\x12+\n" +
+	"\x11packets_delivered\x18\a \x01(\x04R\x10packetsDelivered\x12'\n" +
+	"\x0fpackets_dropped\x18\b \x01(\x04R\x0epacketsDropped\x12+\n" +
+	"\x11packets_duplicate\x18\t \x01(\x04R\x10packetsDuplicate\x12!\n" +
+	"\fcontrol_sent\x18\n" +
+	" \x01(\x04R\vcontrolSent\x12)\n" +
+	"\x10control_received\x18\v \x01(\x04R\x0fcontrolReceived\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\f \x01(\x04R\tbytesSent\x12'\n" +
+	"\x0fbytes_forwarded\x18\r \x01(\x04R\x0ebytesForwarded\x12'\n" +
+	"\x0fbytes_delivered\x18\x0e \x01(\x04R\x0ebytesDelivered\x12L\n" +
+	"\fdrop_reasons\x18\x0f \x03(\v2).spfnet.GetStatsResponse.DropReasonsEntryR\vdropReasons\x12E\n" +
+	"\tneighbors\x18\x10 \x03(\v2'.spfnet.GetStatsResponse.NeighborsEntryR\tneighbors\x12N\n" +
+	"\fdestinations\x18\x11 \x03(\v2*.spfnet.GetStatsResponse.DestinationsEntryR\fdestinations\x129\n" +
+	"\vsubscribers\x18\x12 \x03(\v2\x17.spfnet.SubscriberStatsR\vsubscribers\x1a>\n" +
+	"\x10DropReasonsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\x1aR\n" +
+	"\x0eNeighborsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.spfnet.TrafficStatsR\x05value:\x028\x01\x1aU\n" +
+	"\x11DestinationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.spfnet.TrafficStatsR\x05value:\x028\x01\"\xdf\x01\n" +
+	"\x0fSubscriberStats\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x02 \x01(\tR\n" +
+	"subscriber\x12\x1c\n" +
+	"\tdelivered\x18\x03 \x01(\x04R\tdelivered\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x04R\x06failed\x12'\n" +
+	"\x0fbytes_delivered\x18\x05 \x01(\x04R\x0ebytesDelivered\x127\n" +
+	"\x18last_delivered_unix_nano\x18\x06 \x01(\x03R\x15lastDeliveredUnixNano\"=\n" +
+	"\x12WatchEventsRequest\x12'\n" +
+	"\x05types\x18\x01 \x03(\x0e2\x11.spfnet.EventTypeR\x05types\"\xec\x02\n" +
+	"\x05Event\x12%\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x11.spfnet.EventTypeR\x04type\x12\x1b\n" +
+	"\tunix_nano\x18\x02 \x01(\x03R\bunixNano\x12\x1a\n" +
+	"\breporter\x18\x03 \x01(\tR\breporter\x12\x12\n" +
+	"\x04node\x18\x04 \x01(\tR\x04node\x12\x18\n" +
+	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04from\x18\x06 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\a \x01(\tR\x02to\x12\x16\n" +
+	"\x06pinned\x18\b \x01(\bR\x06pinned\x12\x19\n" +
+	"\bold_cost\x18\t \x01(\x01R\aoldCost\x12\x12\n" +
+	"\x04cost\x18\n" +
+	" \x01(\x01R\x04cost\x12 \n" +
+	"\fold_next_hop\x18\v \x01(\tR\n" +
+	"oldNextHop\x12\x19\n" +
+	"\bnext_hop\x18\f \x01(\tR\anextHop\x12\x19\n" +
+	"\bold_path\x18\r \x03(\tR\aoldPath\x12\x12\n" +
+	"\x04path\x18\x0e \x03(\tR\x04path\"v\n" +
+	"\x10MulticastRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x1c\n" +
+	"\tbroadcast\x18\x02 \x01(\bR\tbroadcast\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x12\x14\n" +
+	"\x05topic\x18\x04 \x01(\tR\x05topic\"\xaf\x01\n" +
+	"\x11MulticastResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1b\n" +
+	"\tpacket_id\x18\x03 \x01(\tR\bpacketId\x12\x18\n" +
+	"\atargets\x18\x04 \x03(\tR\atargets\x12/\n" +
+	"\x13failed_destinations\x18\x05 \x03(\tR\x12failedDestinations\"\xd5\x02\n" +
+	"\n" +
+	"TunnelInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06listen\x18\x02 \x01(\tR\x06listen\x12\x12\n" +
+	"\x04node\x18\x03 \x01(\tR\x04node\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x1b\n" +
+	"\tmax_conns\x18\x05 \x01(\x05R\bmaxConns\x12!\n" +
+	"\factive_conns\x18\x06 \x01(\x03R\vactiveConns\x12\x1f\n" +
+	"\vtotal_conns\x18\a \x01(\x04R\n" +
+	"totalConns\x12%\n" +
+	"\x0erejected_conns\x18\b \x01(\x04R\rrejectedConns\x12!\n" +
+	"\ffailed_conns\x18\t \x01(\x04R\vfailedConns\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\n" +
+	" \x01(\x04R\tbytesSent\x12%\n" +
+	"\x0ebytes_received\x18\v \x01(\x04R\rbytesReceived\"\x87\x01\n" +
+	"\x10AddTunnelRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06listen\x18\x02 \x01(\tR\x06listen\x12\x12\n" +
+	"\x04node\x18\x03 \x01(\tR\x04node\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x1b\n" +
+	"\tmax_conns\x18\x05 \x01(\x05R\bmaxConns\"s\n" +
+	"\x11AddTunnelResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12*\n" +
+	"\x06tunnel\x18\x03 \x01(\v2\x12.spfnet.TunnelInfoR\x06tunnel\")\n" +
+	"\x13RemoveTunnelRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"J\n" +
+	"\x14RemoveTunnelResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x14\n" +
+	"\x12ListTunnelsRequest\"w\n" +
+	"\x13ListTunnelsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12,\n" +
+	"\atunnels\x18\x03 \x03(\v2\x12.spfnet.TunnelInfoR\atunnels\"[\n" +
+	"\x0fSendFileRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\x85\x02\n" +
+	"\x10SendFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12!\n" +
+	"\fresumed_from\x18\x06 \x01(\x03R\vresumedFrom\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\a \x01(\x03R\tbytesSent\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1f\n" +
+	"\vduration_ms\x18\t \x01(\x03R\n" +
+	"durationMs\"\xe1\x01\n" +
+	"\x0eAppSendRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x18\n" +
+	"\aservice\x18\x03 \x01(\tR\aservice\x12=\n" +
+	"\aheaders\x18\x04 \x03(\v2#.spfnet.AppSendRequest.HeadersEntryR\aheaders\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"g\n" +
+	"\x0fAppSendResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\"\x13\n" +
+	"\x11AppReceiveRequest\"\xed\x02\n" +
+	"\n" +
+	"AppMessage\x12\x1b\n" +
+	"\tpacket_id\x18\x01 \x01(\tR\bpacketId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\x12\x12\n" +
+	"\x04path\x18\x04 \x03(\tR\x04path\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\x12\x18\n" +
+	"\aservice\x18\x06 \x01(\tR\aservice\x12\x14\n" +
+	"\x05group\x18\a \x01(\tR\x05group\x12\x14\n" +
+	"\x05topic\x18\b \x01(\tR\x05topic\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\t \x01(\tR\trequestId\x129\n" +
+	"\aheaders\x18\n" +
+	" \x03(\v2\x1f.spfnet.AppMessage.HeadersEntryR\aheaders\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"g\n" +
+	"\n" +
+	"AppRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x03 \x01(\x03R\ttimeoutMs\"s\n" +
+	"\vAppResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\fR\apayload\"l\n" +
+	"\x0fAppReplyRequest\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\"F\n" +
+	"\x10AppReplyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage*\xeb\x01\n" +
+	"\x11StreamSegmentType\x12#\n" +
+	"\x1fSTREAM_SEGMENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17STREAM_SEGMENT_TYPE_SYN\x10\x01\x12\x1f\n" +
+	"\x1bSTREAM_SEGMENT_TYPE_SYN_ACK\x10\x02\x12\x1c\n" +
+	"\x18STREAM_SEGMENT_TYPE_DATA\x10\x03\x12\x1b\n" +
+	"\x17STREAM_SEGMENT_TYPE_ACK\x10\x04\x12\x1b\n" +
+	"\x17STREAM_SEGMENT_TYPE_FIN\x10\x05\x12\x1b\n" +
+	"\x17STREAM_SEGMENT_TYPE_RST\x10\x06*\x88\x01\n" +
+	"\n" +
+	"PacketType\x12\x14\n" +
+	"\x10PACKET_TYPE_DATA\x10\x00\x12\x17\n" +
+	"\x13PACKET_TYPE_CONTROL\x10\x01\x12\x19\n" +
+	"\x15PACKET_TYPE_MULTICAST\x10\x02\x12\x16\n" +
+	"\x12PACKET_TYPE_STREAM\x10\x03\x12\x18\n" +
+	"\x14PACKET_TYPE_DATAGRAM\x10\x04*R\n" +
+	"\vCompression\x12\x14\n" +
+	"\x10COMPRESSION_NONE\x10\x00\x12\x14\n" +
+	"\x10COMPRESSION_GZIP\x10\x01\x12\x17\n" +
+	"\x13COMPRESSION_DEFLATE\x10\x02*\xb7\x01\n" +
+	"\vControlCode\x12\x1c\n" +
+	"\x18CONTROL_CODE_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dCONTROL_CODE_DEST_UNREACHABLE\x10\x01\x12\x1d\n" +
+	"\x19CONTROL_CODE_TTL_EXCEEDED\x10\x02\x12%\n" +
+	"!CONTROL_CODE_FRAGMENTATION_NEEDED\x10\x03\x12!\n" +
+	"\x1dCONTROL_CODE_ADMIN_PROHIBITED\x10\x04*\xa3\x02\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16EVENT_TYPE_MEMBER_JOIN\x10\x01\x12\x1b\n" +
+	"\x17EVENT_TYPE_MEMBER_LEAVE\x10\x02\x12\x1c\n" +
+	"\x18EVENT_TYPE_MEMBER_FAILED\x10\x03\x12\x17\n" +
+	"\x13EVENT_TYPE_LINK_ADD\x10\x04\x12\x1a\n" +
+	"\x16EVENT_TYPE_LINK_UPDATE\x10\x05\x12\x1a\n" +
+	"\x16EVENT_TYPE_LINK_REMOVE\x10\x06\x12\x18\n" +
+	"\x14EVENT_TYPE_ROUTE_ADD\x10\a\x12\x1b\n" +
+	"\x17EVENT_TYPE_ROUTE_CHANGE\x10\b\x12\x1b\n" +
+	"\x17EVENT_TYPE_ROUTE_REMOVE\x10\t2\xbb\x01\n" +
 	"\vNodeService\x128\n" +
 	"\rForwardPacket\x12\x0e.spfnet.Packet\x1a\x17.spfnet.ForwardResponse\x12?\n" +
 	"\x10ProbeLinkQuality\x12\x14.spfnet.ProbeRequest\x1a\x15.spfnet.ProbeResponse\x121\n" +
-	"\x04Ping\x12\x13.spfnet.PingRequest\x1a\x14.spfnet.PingResponse2\x89\x02\n" +
+	"\x04Ping\x12\x13.spfnet.PingRequest\x1a\x14.spfnet.PingResponse2\xf5\b\n" +
 	"\x0eControlService\x12:\n" +
 	"\aAddLink\x12\x16.spfnet.AddLinkRequest\x1a\x17.spfnet.AddLinkResponse\x12C\n" +
 	"\n" +
 	"SendPacket\x12\x19.spfnet.SendPacketRequest\x1a\x1a.spfnet.SendPacketResponse\x12C\n" +
 	"\n" +
 	"EnableSync\x12\x19.spfnet.EnableSyncRequest\x1a\x1a.spfnet.EnableSyncResponse\x121\n" +
-	"\x04Ping\x12\x13.spfnet.PingRequest\x1a\x14.spfnet.PingResponseB\tZ\a./protob\x06proto3"
+	"\x04Ping\x12\x13.spfnet.PingRequest\x1a\x14.spfnet.PingResponse\x12C\n" +
+	"\n" +
+	"Traceroute\x12\x19.spfnet.TracerouteRequest\x1a\x1a.spfnet.TracerouteResponse\x12C\n" +
+	"\n" +
+	"RemoveLink\x12\x19.spfnet.RemoveLinkRequest\x1a\x1a.spfnet.RemoveLinkResponse\x12F\n" +
+	"\vSetLinkCost\x12\x1a.spfnet.SetLinkCostRequest\x1a\x1b.spfnet.SetLinkCostResponse\x12@\n" +
+	"\tGetRoutes\x12\x18.spfnet.GetRoutesRequest\x1a\x19.spfnet.GetRoutesResponse\x12=\n" +
+	"\bGetRoute\x12\x17.spfnet.GetRouteRequest\x1a\x18.spfnet.GetRouteResponse\x12F\n" +
+	"\vGetTopology\x12\x1a.spfnet.GetTopologyRequest\x1a\x1b.spfnet.GetTopologyResponse\x12=\n" +
+	"\bGetStats\x12\x17.spfnet.GetStatsRequest\x1a\x18.spfnet.GetStatsResponse\x12:\n" +
+	"\vWatchEvents\x12\x1a.spfnet.WatchEventsRequest\x1a\r.spfnet.Event0\x01\x12@\n" +
+	"\tMulticast\x12\x18.spfnet.MulticastRequest\x1a\x19.spfnet.MulticastResponse\x12@\n" +
+	"\tAddTunnel\x12\x18.spfnet.AddTunnelRequest\x1a\x19.spfnet.AddTunnelResponse\x12I\n" +
+	"\fRemoveTunnel\x12\x1b.spfnet.RemoveTunnelRequest\x1a\x1c.spfnet.RemoveTunnelResponse\x12F\n" +
+	"\vListTunnels\x12\x1a.spfnet.ListTunnelsRequest\x1a\x1b.spfnet.ListTunnelsResponse\x12=\n" +
+	"\bSendFile\x12\x17.spfnet.SendFileRequest\x1a\x18.spfnet.SendFileResponse2\xba\x03\n" +
+	"\n" +
+	"AppService\x127\n" +
+	"\x04Send\x12\x16.spfnet.AppSendRequest\x1a\x17.spfnet.AppSendResponse\x12:\n" +
+	"\aReceive\x12\x19.spfnet.AppReceiveRequest\x1a\x12.spfnet.AppMessage0\x01\x122\n" +
+	"\aRequest\x12\x12.spfnet.AppRequest\x1a\x13.spfnet.AppResponse\x12:\n" +
+	"\x05Reply\x12\x17.spfnet.AppReplyRequest\x1a\x18.spfnet.AppReplyResponse\x12=\n" +
+	"\bGetRoute\x12\x17.spfnet.GetRouteRequest\x1a\x18.spfnet.GetRouteResponse\x12@\n" +
+	"\tGetRoutes\x12\x18.spfnet.GetRoutesRequest\x1a\x19.spfnet.GetRoutesResponse\x12F\n" +
+	"\vGetTopology\x12\x1a.spfnet.GetTopologyRequest\x1a\x1b.spfnet.GetTopologyResponseB\tZ\a./protob\x06proto3"
 
 var (
 	file_node_proto_rawDescOnce sync.Once
@@ -835,42 +5105,173 @@ func file_node_proto_rawDescGZIP() []byte {
 	return file_node_proto_rawDescData
 }
 
-var file_node_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_node_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_node_proto_msgTypes = make([]protoimpl.MessageInfo, 66)
 var file_node_proto_goTypes = []any{
-	(*Packet)(nil),             // 0: spfnet.Packet
-	(*ForwardResponse)(nil),    // 1: spfnet.ForwardResponse
-	(*ProbeRequest)(nil),       // 2: spfnet.ProbeRequest
-	(*ProbeResponse)(nil),      // 3: spfnet.ProbeResponse
-	(*PingRequest)(nil),        // 4: spfnet.PingRequest
-	(*PingResponse)(nil),       // 5: spfnet.PingResponse
-	(*AddLinkRequest)(nil),     // 6: spfnet.AddLinkRequest
-	(*AddLinkResponse)(nil),    // 7: spfnet.AddLinkResponse
-	(*SendPacketRequest)(nil),  // 8: spfnet.SendPacketRequest
-	(*SendPacketResponse)(nil), // 9: spfnet.SendPacketResponse
-	(*EnableSyncRequest)(nil),  // 10: spfnet.EnableSyncRequest
-	(*EnableSyncResponse)(nil), // 11: spfnet.EnableSyncResponse
+	(StreamSegmentType)(0),       // 0: spfnet.StreamSegmentType
+	(PacketType)(0),              // 1: spfnet.PacketType
+	(Compression)(0),             // 2: spfnet.Compression
+	(ControlCode)(0),             // 3: spfnet.ControlCode
+	(EventType)(0),               // 4: spfnet.EventType
+	(*Packet)(nil),               // 5: spfnet.Packet
+	(*SealedPayload)(nil),        // 6: spfnet.SealedPayload
+	(*StreamSegment)(nil),        // 7: spfnet.StreamSegment
+	(*MulticastTarget)(nil),      // 8: spfnet.MulticastTarget
+	(*TraceHop)(nil),             // 9: spfnet.TraceHop
+	(*ControlMessage)(nil),       // 10: spfnet.ControlMessage
+	(*ForwardResponse)(nil),      // 11: spfnet.ForwardResponse
+	(*ProbeRequest)(nil),         // 12: spfnet.ProbeRequest
+	(*ProbeResponse)(nil),        // 13: spfnet.ProbeResponse
+	(*PingRequest)(nil),          // 14: spfnet.PingRequest
+	(*PingResponse)(nil),         // 15: spfnet.PingResponse
+	(*AddLinkRequest)(nil),       // 16: spfnet.AddLinkRequest
+	(*AddLinkResponse)(nil),      // 17: spfnet.AddLinkResponse
+	(*SendPacketRequest)(nil),    // 18: spfnet.SendPacketRequest
+	(*SendPacketResponse)(nil),   // 19: spfnet.SendPacketResponse
+	(*EnableSyncRequest)(nil),    // 20: spfnet.EnableSyncRequest
+	(*EnableSyncResponse)(nil),   // 21: spfnet.EnableSyncResponse
+	(*TracerouteRequest)(nil),    // 22: spfnet.TracerouteRequest
+	(*TracerouteResponse)(nil),   // 23: spfnet.TracerouteResponse
+	(*RemoveLinkRequest)(nil),    // 24: spfnet.RemoveLinkRequest
+	(*RemoveLinkResponse)(nil),   // 25: spfnet.RemoveLinkResponse
+	(*SetLinkCostRequest)(nil),   // 26: spfnet.SetLinkCostRequest
+	(*SetLinkCostResponse)(nil),  // 27: spfnet.SetLinkCostResponse
+	(*RouteEntry)(nil),           // 28: spfnet.RouteEntry
+	(*GetRoutesRequest)(nil),     // 29: spfnet.GetRoutesRequest
+	(*GetRoutesResponse)(nil),    // 30: spfnet.GetRoutesResponse
+	(*GetRouteRequest)(nil),      // 31: spfnet.GetRouteRequest
+	(*GetRouteResponse)(nil),     // 32: spfnet.GetRouteResponse
+	(*TopologyNode)(nil),         // 33: spfnet.TopologyNode
+	(*TopologyLink)(nil),         // 34: spfnet.TopologyLink
+	(*GetTopologyRequest)(nil),   // 35: spfnet.GetTopologyRequest
+	(*GetTopologyResponse)(nil),  // 36: spfnet.GetTopologyResponse
+	(*LatencyHistogram)(nil),     // 37: spfnet.LatencyHistogram
+	(*TrafficStats)(nil),         // 38: spfnet.TrafficStats
+	(*GetStatsRequest)(nil),      // 39: spfnet.GetStatsRequest
+	(*GetStatsResponse)(nil),     // 40: spfnet.GetStatsResponse
+	(*SubscriberStats)(nil),      // 41: spfnet.SubscriberStats
+	(*WatchEventsRequest)(nil),   // 42: spfnet.WatchEventsRequest
+	(*Event)(nil),                // 43: spfnet.Event
+	(*MulticastRequest)(nil),     // 44: spfnet.MulticastRequest
+	(*MulticastResponse)(nil),    // 45: spfnet.MulticastResponse
+	(*TunnelInfo)(nil),           // 46: spfnet.TunnelInfo
+	(*AddTunnelRequest)(nil),     // 47: spfnet.AddTunnelRequest
+	(*AddTunnelResponse)(nil),    // 48: spfnet.AddTunnelResponse
+	(*RemoveTunnelRequest)(nil),  // 49: spfnet.RemoveTunnelRequest
+	(*RemoveTunnelResponse)(nil), // 50: spfnet.RemoveTunnelResponse
+	(*ListTunnelsRequest)(nil),   // 51: spfnet.ListTunnelsRequest
+	(*ListTunnelsResponse)(nil),  // 52: spfnet.ListTunnelsResponse
+	(*SendFileRequest)(nil),      // 53: spfnet.SendFileRequest
+	(*SendFileResponse)(nil),     // 54: spfnet.SendFileResponse
+	(*AppSendRequest)(nil),       // 55: spfnet.AppSendRequest
+	(*AppSendResponse)(nil),      // 56: spfnet.AppSendResponse
+	(*AppReceiveRequest)(nil),    // 57: spfnet.AppReceiveRequest
+	(*AppMessage)(nil),           // 58: spfnet.AppMessage
+	(*AppRequest)(nil),           // 59: spfnet.AppRequest
+	(*AppResponse)(nil),          // 60: spfnet.AppResponse
+	(*AppReplyRequest)(nil),      // 61: spfnet.AppReplyRequest
+	(*AppReplyResponse)(nil),     // 62: spfnet.AppReplyResponse
+	nil,                          // 63: spfnet.Packet.HeadersEntry
+	nil,                          // 64: spfnet.SealedPayload.HeadersEntry
+	nil,                          // 65: spfnet.TrafficStats.DropReasonsEntry
+	nil,                          // 66: spfnet.GetStatsResponse.DropReasonsEntry
+	nil,                          // 67: spfnet.GetStatsResponse.NeighborsEntry
+	nil,                          // 68: spfnet.GetStatsResponse.DestinationsEntry
+	nil,                          // 69: spfnet.AppSendRequest.HeadersEntry
+	nil,                          // 70: spfnet.AppMessage.HeadersEntry
 }
 var file_node_proto_depIdxs = []int32{
-	0,  // 0: spfnet.SendPacketRequest.packet:type_name -> spfnet.Packet
-	0,  // 1: spfnet.NodeService.ForwardPacket:input_type -> spfnet.Packet
-	2,  // 2: spfnet.NodeService.ProbeLinkQuality:input_type -> spfnet.ProbeRequest
-	4,  // 3: spfnet.NodeService.Ping:input_type -> spfnet.PingRequest
-	6,  // 4: spfnet.ControlService.AddLink:input_type -> spfnet.AddLinkRequest
-	8,  // 5: spfnet.ControlService.SendPacket:input_type -> spfnet.SendPacketRequest
-	10, // 6: spfnet.ControlService.EnableSync:input_type -> spfnet.EnableSyncRequest
-	4,  // 7: spfnet.ControlService.Ping:input_type -> spfnet.PingRequest
-	1,  // 8: spfnet.NodeService.ForwardPacket:output_type -> spfnet.ForwardResponse
-	3,  // 9: spfnet.NodeService.ProbeLinkQuality:output_type -> spfnet.ProbeResponse
-	5,  // 10: spfnet.NodeService.Ping:output_type -> spfnet.PingResponse
-	7,  // 11: spfnet.ControlService.AddLink:output_type -> spfnet.AddLinkResponse
-	9,  // 12: spfnet.ControlService.SendPacket:output_type -> spfnet.SendPacketResponse
-	11, // 13: spfnet.ControlService.EnableSync:output_type -> spfnet.EnableSyncResponse
-	5,  // 14: spfnet.ControlService.Ping:output_type -> spfnet.PingResponse
-	8,  // [8:15] is the sub-list for method output_type
-	1,  // [1:8] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	1,  // 0: spfnet.Packet.type:type_name -> spfnet.PacketType
+	10, // 1: spfnet.Packet.control:type_name -> spfnet.ControlMessage
+	9,  // 2: spfnet.Packet.trace_hops:type_name -> spfnet.TraceHop
+	8,  // 3: spfnet.Packet.multicast_targets:type_name -> spfnet.MulticastTarget
+	7,  // 4: spfnet.Packet.stream:type_name -> spfnet.StreamSegment
+	63, // 5: spfnet.Packet.headers:type_name -> spfnet.Packet.HeadersEntry
+	2,  // 6: spfnet.Packet.compression:type_name -> spfnet.Compression
+	64, // 7: spfnet.SealedPayload.headers:type_name -> spfnet.SealedPayload.HeadersEntry
+	0,  // 8: spfnet.StreamSegment.type:type_name -> spfnet.StreamSegmentType
+	3,  // 9: spfnet.ControlMessage.code:type_name -> spfnet.ControlCode
+	10, // 10: spfnet.ForwardResponse.control:type_name -> spfnet.ControlMessage
+	9,  // 11: spfnet.ForwardResponse.trace_hops:type_name -> spfnet.TraceHop
+	5,  // 12: spfnet.SendPacketRequest.packet:type_name -> spfnet.Packet
+	9,  // 13: spfnet.TracerouteResponse.hops:type_name -> spfnet.TraceHop
+	28, // 14: spfnet.GetRoutesResponse.routes:type_name -> spfnet.RouteEntry
+	28, // 15: spfnet.GetRouteResponse.route:type_name -> spfnet.RouteEntry
+	33, // 16: spfnet.GetTopologyResponse.nodes:type_name -> spfnet.TopologyNode
+	34, // 17: spfnet.GetTopologyResponse.links:type_name -> spfnet.TopologyLink
+	65, // 18: spfnet.TrafficStats.drop_reasons:type_name -> spfnet.TrafficStats.DropReasonsEntry
+	37, // 19: spfnet.TrafficStats.latency:type_name -> spfnet.LatencyHistogram
+	66, // 20: spfnet.GetStatsResponse.drop_reasons:type_name -> spfnet.GetStatsResponse.DropReasonsEntry
+	67, // 21: spfnet.GetStatsResponse.neighbors:type_name -> spfnet.GetStatsResponse.NeighborsEntry
+	68, // 22: spfnet.GetStatsResponse.destinations:type_name -> spfnet.GetStatsResponse.DestinationsEntry
+	41, // 23: spfnet.GetStatsResponse.subscribers:type_name -> spfnet.SubscriberStats
+	4,  // 24: spfnet.WatchEventsRequest.types:type_name -> spfnet.EventType
+	4,  // 25: spfnet.Event.type:type_name -> spfnet.EventType
+	46, // 26: spfnet.AddTunnelResponse.tunnel:type_name -> spfnet.TunnelInfo
+	46, // 27: spfnet.ListTunnelsResponse.tunnels:type_name -> spfnet.TunnelInfo
+	69, // 28: spfnet.AppSendRequest.headers:type_name -> spfnet.AppSendRequest.HeadersEntry
+	70, // 29: spfnet.AppMessage.headers:type_name -> spfnet.AppMessage.HeadersEntry
+	38, // 30: spfnet.GetStatsResponse.NeighborsEntry.value:type_name -> spfnet.TrafficStats
+	38, // 31: spfnet.GetStatsResponse.DestinationsEntry.value:type_name -> spfnet.TrafficStats
+	5,  // 32: spfnet.NodeService.ForwardPacket:input_type -> spfnet.Packet
+	12, // 33: spfnet.NodeService.ProbeLinkQuality:input_type -> spfnet.ProbeRequest
+	14, // 34: spfnet.NodeService.Ping:input_type -> spfnet.PingRequest
+	16, // 35: spfnet.ControlService.AddLink:input_type -> spfnet.AddLinkRequest
+	18, // 36: spfnet.ControlService.SendPacket:input_type -> spfnet.SendPacketRequest
+	20, // 37: spfnet.ControlService.EnableSync:input_type -> spfnet.EnableSyncRequest
+	14, // 38: spfnet.ControlService.Ping:input_type -> spfnet.PingRequest
+	22, // 39: spfnet.ControlService.Traceroute:input_type -> spfnet.TracerouteRequest
+	24, // 40: spfnet.ControlService.RemoveLink:input_type -> spfnet.RemoveLinkRequest
+	26, // 41: spfnet.ControlService.SetLinkCost:input_type -> spfnet.SetLinkCostRequest
+	29, // 42: spfnet.ControlService.GetRoutes:input_type -> spfnet.GetRoutesRequest
+	31, // 43: spfnet.ControlService.GetRoute:input_type -> spfnet.GetRouteRequest
+	35, // 44: spfnet.ControlService.GetTopology:input_type -> spfnet.GetTopologyRequest
+	39, // 45: spfnet.ControlService.GetStats:input_type -> spfnet.GetStatsRequest
+	42, // 46: spfnet.ControlService.WatchEvents:input_type -> spfnet.WatchEventsRequest
+	44, // 47: spfnet.ControlService.Multicast:input_type -> spfnet.MulticastRequest
+	47, // 48: spfnet.ControlService.AddTunnel:input_type -> spfnet.AddTunnelRequest
+	49, // 49: spfnet.ControlService.RemoveTunnel:input_type -> spfnet.RemoveTunnelRequest
+	51, // 50: spfnet.ControlService.ListTunnels:input_type -> spfnet.ListTunnelsRequest
+	53, // 51: spfnet.ControlService.SendFile:input_type -> spfnet.SendFileRequest
+	55, // 52: spfnet.AppService.Send:input_type -> spfnet.AppSendRequest
+	57, // 53: spfnet.AppService.Receive:input_type -> spfnet.AppReceiveRequest
+	59, // 54: spfnet.AppService.Request:input_type -> spfnet.AppRequest
+	61, // 55: spfnet.AppService.Reply:input_type -> spfnet.AppReplyRequest
+	31, // 56: spfnet.AppService.GetRoute:input_type -> spfnet.GetRouteRequest
+	29, // 57: spfnet.AppService.GetRoutes:input_type -> spfnet.GetRoutesRequest
+	35, // 58: spfnet.AppService.GetTopology:input_type -> spfnet.GetTopologyRequest
+	11, // 59: spfnet.NodeService.ForwardPacket:output_type -> spfnet.ForwardResponse
+	13, // 60: spfnet.NodeService.ProbeLinkQuality:output_type -> spfnet.ProbeResponse
+	15, // 61: spfnet.NodeService.Ping:output_type -> spfnet.PingResponse
+	17, // 62: spfnet.ControlService.AddLink:output_type -> spfnet.AddLinkResponse
+	19, // 63: spfnet.ControlService.SendPacket:output_type -> spfnet.SendPacketResponse
+	21, // 64: spfnet.ControlService.EnableSync:output_type -> spfnet.EnableSyncResponse
+	15, // 65: spfnet.ControlService.Ping:output_type -> spfnet.PingResponse
+	23, // 66: spfnet.ControlService.Traceroute:output_type -> spfnet.TracerouteResponse
+	25, // 67: spfnet.ControlService.RemoveLink:output_type -> spfnet.RemoveLinkResponse
+	27, // 68: spfnet.ControlService.SetLinkCost:output_type -> spfnet.SetLinkCostResponse
+	30, // 69: spfnet.ControlService.GetRoutes:output_type -> spfnet.GetRoutesResponse
+	32, // 70: spfnet.ControlService.GetRoute:output_type -> spfnet.GetRouteResponse
+	36, // 71: spfnet.ControlService.GetTopology:output_type -> spfnet.GetTopologyResponse
+	40, // 72: spfnet.ControlService.GetStats:output_type -> spfnet.GetStatsResponse
+	43, // 73: spfnet.ControlService.WatchEvents:output_type -> spfnet.Event
+	45, // 74: spfnet.ControlService.Multicast:output_type -> spfnet.MulticastResponse
+	48, // 75: spfnet.ControlService.AddTunnel:output_type -> spfnet.AddTunnelResponse
+	50, // 76: spfnet.ControlService.RemoveTunnel:output_type -> spfnet.RemoveTunnelResponse
+	52, // 77: spfnet.ControlService.ListTunnels:output_type -> spfnet.ListTunnelsResponse
+	54, // 78: spfnet.ControlService.SendFile:output_type -> spfnet.SendFileResponse
+	56, // 79: spfnet.AppService.Send:output_type -> spfnet.AppSendResponse
+	58, // 80: spfnet.AppService.Receive:output_type -> spfnet.AppMessage
+	60, // 81: spfnet.AppService.Request:output_type -> spfnet.AppResponse
+	62, // 82: spfnet.AppService.Reply:output_type -> spfnet.AppReplyResponse
+	32, // 83: spfnet.AppService.GetRoute:output_type -> spfnet.GetRouteResponse
+	30, // 84: spfnet.AppService.GetRoutes:output_type -> spfnet.GetRoutesResponse
+	36, // 85: spfnet.AppService.GetTopology:output_type -> spfnet.GetTopologyResponse
+	59, // [59:86] is the sub-list for method output_type
+	32, // [32:59] is the sub-list for method input_type
+	32, // [32:32] is the sub-list for extension type_name
+	32, // [32:32] is the sub-list for extension extendee
+	0,  // [0:32] is the sub-list for field type_name
 }
 
 func init() { file_node_proto_init() }
@@ -883,13 +5284,14 @@ func file_node_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_node_proto_rawDesc), len(file_node_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   12,
+			NumEnums:      5,
+			NumMessages:   66,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   3,
 		},
 		GoTypes:           file_node_proto_goTypes,
 		DependencyIndexes: file_node_proto_depIdxs,
+		EnumInfos:         file_node_proto_enumTypes,
 		MessageInfos:      file_node_proto_msgTypes,
 	}.Build()
 	File_node_proto = out.File
@@ -207,10 +207,23 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	ControlService_AddLink_FullMethodName    = "/spfnet.ControlService/AddLink"
-	ControlService_SendPacket_FullMethodName = "/spfnet.ControlService/SendPacket"
-	ControlService_EnableSync_FullMethodName = "/spfnet.ControlService/EnableSync"
-	ControlService_Ping_FullMethodName       = "/spfnet.ControlService/Ping"
+	ControlService_AddLink_FullMethodName      = "/spfnet.ControlService/AddLink"
+	ControlService_SendPacket_FullMethodName   = "/spfnet.ControlService/SendPacket"
+	ControlService_EnableSync_FullMethodName   = "/spfnet.ControlService/EnableSync"
+	ControlService_Ping_FullMethodName         = "/spfnet.ControlService/Ping"
+	ControlService_Traceroute_FullMethodName   = "/spfnet.ControlService/Traceroute"
+	ControlService_RemoveLink_FullMethodName   = "/spfnet.ControlService/RemoveLink"
+	ControlService_SetLinkCost_FullMethodName  = "/spfnet.ControlService/SetLinkCost"
+	ControlService_GetRoutes_FullMethodName    = "/spfnet.ControlService/GetRoutes"
+	ControlService_GetRoute_FullMethodName     = "/spfnet.ControlService/GetRoute"
+	ControlService_GetTopology_FullMethodName  = "/spfnet.ControlService/GetTopology"
+	ControlService_GetStats_FullMethodName     = "/spfnet.ControlService/GetStats"
+	ControlService_WatchEvents_FullMethodName  = "/spfnet.ControlService/WatchEvents"
+	ControlService_Multicast_FullMethodName    = "/spfnet.ControlService/Multicast"
+	ControlService_AddTunnel_FullMethodName    = "/spfnet.ControlService/AddTunnel"
+	ControlService_RemoveTunnel_FullMethodName = "/spfnet.ControlService/RemoveTunnel"
+	ControlService_ListTunnels_FullMethodName  = "/spfnet.ControlService/ListTunnels"
+	ControlService_SendFile_FullMethodName     = "/spfnet.ControlService/SendFile"
 )
 
 // ControlServiceClient is the client API for ControlService service.
@@ -228,6 +241,32 @@ type ControlServiceClient interface {
 	EnableSync(ctx context.Context, in *EnableSyncRequest, opts ...grpc.CallOption) (*EnableSyncResponse, error)
 	// 健康检查
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// 路径追踪：从本节点向目标发送追踪包，返回每一跳的信息
+	Traceroute(ctx context.Context, in *TracerouteRequest, opts ...grpc.CallOption) (*TracerouteResponse, error)
+	// 删除到邻居的链路（通过拓扑同步广播到整个集群）
+	RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*RemoveLinkResponse, error)
+	// 修改到邻居的链路成本（手动固定或重新自动探测）
+	SetLinkCost(ctx context.Context, in *SetLinkCostRequest, opts ...grpc.CallOption) (*SetLinkCostResponse, error)
+	// 查询本节点的完整路由表
+	GetRoutes(ctx context.Context, in *GetRoutesRequest, opts ...grpc.CallOption) (*GetRoutesResponse, error)
+	// 查询到指定目标的路由
+	GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*GetRouteResponse, error)
+	// 查询本节点视角下的集群拓扑（节点状态与链路成本）
+	GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error)
+	// 查询转发统计（全局计数及按邻居、目标细分的计数）
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// 订阅成员、链路和路由变化事件（服务端流）
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// 向多播组的所有成员、主题的所有订阅者或集群所有节点发送数据
+	Multicast(ctx context.Context, in *MulticastRequest, opts ...grpc.CallOption) (*MulticastResponse, error)
+	// 添加端口转发隧道：本节点监听 TCP 地址，连接经覆盖网络转发到目标节点可访问的 TCP 地址
+	AddTunnel(ctx context.Context, in *AddTunnelRequest, opts ...grpc.CallOption) (*AddTunnelResponse, error)
+	// 删除端口转发隧道并断开其所有连接
+	RemoveTunnel(ctx context.Context, in *RemoveTunnelRequest, opts ...grpc.CallOption) (*RemoveTunnelResponse, error)
+	// 查询本节点的端口转发隧道及其统计
+	ListTunnels(ctx context.Context, in *ListTunnelsRequest, opts ...grpc.CallOption) (*ListTunnelsResponse, error)
+	// 将本节点上的文件经覆盖网络发送到目标节点的收件目录（支持断点续传）
+	SendFile(ctx context.Context, in *SendFileRequest, opts ...grpc.CallOption) (*SendFileResponse, error)
 }
 
 type controlServiceClient struct {
@@ -278,6 +317,145 @@ func (c *controlServiceClient) Ping(ctx context.Context, in *PingRequest, opts .
 	return out, nil
 }
 
+func (c *controlServiceClient) Traceroute(ctx context.Context, in *TracerouteRequest, opts ...grpc.CallOption) (*TracerouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TracerouteResponse)
+	err := c.cc.Invoke(ctx, ControlService_Traceroute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*RemoveLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveLinkResponse)
+	err := c.cc.Invoke(ctx, ControlService_RemoveLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) SetLinkCost(ctx context.Context, in *SetLinkCostRequest, opts ...grpc.CallOption) (*SetLinkCostResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLinkCostResponse)
+	err := c.cc.Invoke(ctx, ControlService_SetLinkCost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetRoutes(ctx context.Context, in *GetRoutesRequest, opts ...grpc.CallOption) (*GetRoutesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRoutesResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetRoutes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*GetRouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRouteResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTopologyResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetTopology_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ControlService_ServiceDesc.Streams[0], ControlService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlService_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *controlServiceClient) Multicast(ctx context.Context, in *MulticastRequest, opts ...grpc.CallOption) (*MulticastResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MulticastResponse)
+	err := c.cc.Invoke(ctx, ControlService_Multicast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) AddTunnel(ctx context.Context, in *AddTunnelRequest, opts ...grpc.CallOption) (*AddTunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddTunnelResponse)
+	err := c.cc.Invoke(ctx, ControlService_AddTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) RemoveTunnel(ctx context.Context, in *RemoveTunnelRequest, opts ...grpc.CallOption) (*RemoveTunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveTunnelResponse)
+	err := c.cc.Invoke(ctx, ControlService_RemoveTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) ListTunnels(ctx context.Context, in *ListTunnelsRequest, opts ...grpc.CallOption) (*ListTunnelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTunnelsResponse)
+	err := c.cc.Invoke(ctx, ControlService_ListTunnels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) SendFile(ctx context.Context, in *SendFileRequest, opts ...grpc.CallOption) (*SendFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendFileResponse)
+	err := c.cc.Invoke(ctx, ControlService_SendFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServiceServer is the server API for ControlService service.
 // All implementations must embed UnimplementedControlServiceServer
 // for forward compatibility.
@@ -293,6 +471,32 @@ type ControlServiceServer interface {
 	EnableSync(context.Context, *EnableSyncRequest) (*EnableSyncResponse, error)
 	// 健康检查
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// 路径追踪：从本节点向目标发送追踪包，返回每一跳的信息
+	Traceroute(context.Context, *TracerouteRequest) (*TracerouteResponse, error)
+	// 删除到邻居的链路（通过拓扑同步广播到整个集群）
+	RemoveLink(context.Context, *RemoveLinkRequest) (*RemoveLinkResponse, error)
+	// 修改到邻居的链路成本（手动固定或重新自动探测）
+	SetLinkCost(context.Context, *SetLinkCostRequest) (*SetLinkCostResponse, error)
+	// 查询本节点的完整路由表
+	GetRoutes(context.Context, *GetRoutesRequest) (*GetRoutesResponse, error)
+	// 查询到指定目标的路由
+	GetRoute(context.Context, *GetRouteRequest) (*GetRouteResponse, error)
+	// 查询本节点视角下的集群拓扑（节点状态与链路成本）
+	GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyResponse, error)
+	// 查询转发统计（全局计数及按邻居、目标细分的计数）
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// 订阅成员、链路和路由变化事件（服务端流）
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// 向多播组的所有成员、主题的所有订阅者或集群所有节点发送数据
+	Multicast(context.Context, *MulticastRequest) (*MulticastResponse, error)
+	// 添加端口转发隧道：本节点监听 TCP 地址，连接经覆盖网络转发到目标节点可访问的 TCP 地址
+	AddTunnel(context.Context, *AddTunnelRequest) (*AddTunnelResponse, error)
+	// 删除端口转发隧道并断开其所有连接
+	RemoveTunnel(context.Context, *RemoveTunnelRequest) (*RemoveTunnelResponse, error)
+	// 查询本节点的端口转发隧道及其统计
+	ListTunnels(context.Context, *ListTunnelsRequest) (*ListTunnelsResponse, error)
+	// 将本节点上的文件经覆盖网络发送到目标节点的收件目录（支持断点续传）
+	SendFile(context.Context, *SendFileRequest) (*SendFileResponse, error)
 	mustEmbedUnimplementedControlServiceServer()
 }
 
@@ -315,6 +519,45 @@ func (UnimplementedControlServiceServer) EnableSync(context.Context, *EnableSync
 func (UnimplementedControlServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Ping not implemented")
 }
+func (UnimplementedControlServiceServer) Traceroute(context.Context, *TracerouteRequest) (*TracerouteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Traceroute not implemented")
+}
+func (UnimplementedControlServiceServer) RemoveLink(context.Context, *RemoveLinkRequest) (*RemoveLinkResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveLink not implemented")
+}
+func (UnimplementedControlServiceServer) SetLinkCost(context.Context, *SetLinkCostRequest) (*SetLinkCostResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLinkCost not implemented")
+}
+func (UnimplementedControlServiceServer) GetRoutes(context.Context, *GetRoutesRequest) (*GetRoutesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRoutes not implemented")
+}
+func (UnimplementedControlServiceServer) GetRoute(context.Context, *GetRouteRequest) (*GetRouteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRoute not implemented")
+}
+func (UnimplementedControlServiceServer) GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTopology not implemented")
+}
+func (UnimplementedControlServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedControlServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedControlServiceServer) Multicast(context.Context, *MulticastRequest) (*MulticastResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Multicast not implemented")
+}
+func (UnimplementedControlServiceServer) AddTunnel(context.Context, *AddTunnelRequest) (*AddTunnelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddTunnel not implemented")
+}
+func (UnimplementedControlServiceServer) RemoveTunnel(context.Context, *RemoveTunnelRequest) (*RemoveTunnelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveTunnel not implemented")
+}
+func (UnimplementedControlServiceServer) ListTunnels(context.Context, *ListTunnelsRequest) (*ListTunnelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTunnels not implemented")
+}
+func (UnimplementedControlServiceServer) SendFile(context.Context, *SendFileRequest) (*SendFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendFile not implemented")
+}
 func (UnimplementedControlServiceServer) mustEmbedUnimplementedControlServiceServer() {}
 func (UnimplementedControlServiceServer) testEmbeddedByValue()                        {}
 
@@ -408,6 +651,233 @@ func _ControlService_Ping_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ControlService_Traceroute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TracerouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).Traceroute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_Traceroute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).Traceroute(ctx, req.(*TracerouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_RemoveLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).RemoveLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_RemoveLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).RemoveLink(ctx, req.(*RemoveLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_SetLinkCost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLinkCostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).SetLinkCost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_SetLinkCost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).SetLinkCost(ctx, req.(*SetLinkCostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetRoutes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetRoutes(ctx, req.(*GetRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetRoute(ctx, req.(*GetRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetTopology_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopologyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetTopology(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetTopology_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetTopology(ctx, req.(*GetTopologyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControlServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlService_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+func _ControlService_Multicast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MulticastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).Multicast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_Multicast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).Multicast(ctx, req.(*MulticastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_AddTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).AddTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_AddTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).AddTunnel(ctx, req.(*AddTunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_RemoveTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).RemoveTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_RemoveTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).RemoveTunnel(ctx, req.(*RemoveTunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_ListTunnels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTunnelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).ListTunnels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_ListTunnels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).ListTunnels(ctx, req.(*ListTunnelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_SendFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).SendFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_SendFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).SendFile(ctx, req.(*SendFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ControlService_ServiceDesc is the grpc.ServiceDesc for ControlService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -431,7 +901,417 @@ var ControlService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _ControlService_Ping_Handler,
 		},
+		{
+			MethodName: "Traceroute",
+			Handler:    _ControlService_Traceroute_Handler,
+		},
+		{
+			MethodName: "RemoveLink",
+			Handler:    _ControlService_RemoveLink_Handler,
+		},
+		{
+			MethodName: "SetLinkCost",
+			Handler:    _ControlService_SetLinkCost_Handler,
+		},
+		{
+			MethodName: "GetRoutes",
+			Handler:    _ControlService_GetRoutes_Handler,
+		},
+		{
+			MethodName: "GetRoute",
+			Handler:    _ControlService_GetRoute_Handler,
+		},
+		{
+			MethodName: "GetTopology",
+			Handler:    _ControlService_GetTopology_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _ControlService_GetStats_Handler,
+		},
+		{
+			MethodName: "Multicast",
+			Handler:    _ControlService_Multicast_Handler,
+		},
+		{
+			MethodName: "AddTunnel",
+			Handler:    _ControlService_AddTunnel_Handler,
+		},
+		{
+			MethodName: "RemoveTunnel",
+			Handler:    _ControlService_RemoveTunnel_Handler,
+		},
+		{
+			MethodName: "ListTunnels",
+			Handler:    _ControlService_ListTunnels_Handler,
+		},
+		{
+			MethodName: "SendFile",
+			Handler:    _ControlService_SendFile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _ControlService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "node.proto",
+}
+
+const (
+	AppService_Send_FullMethodName        = "/spfnet.AppService/Send"
+	AppService_Receive_FullMethodName     = "/spfnet.AppService/Receive"
+	AppService_Request_FullMethodName     = "/spfnet.AppService/Request"
+	AppService_Reply_FullMethodName       = "/spfnet.AppService/Reply"
+	AppService_GetRoute_FullMethodName    = "/spfnet.AppService/GetRoute"
+	AppService_GetRoutes_FullMethodName   = "/spfnet.AppService/GetRoutes"
+	AppService_GetTopology_FullMethodName = "/spfnet.AppService/GetTopology"
+)
+
+// AppServiceClient is the client API for AppService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ==================== 本地应用服务 ====================
+// spf_route 在 Unix 域套接字或回环地址上为同机的应用提供的收发接口（sidecar 模式），
+// 使非 Go 应用无需引入 spfnet 包即可使用覆盖网络
+type AppServiceClient interface {
+	// 发送数据到目标节点或服务
+	Send(ctx context.Context, in *AppSendRequest, opts ...grpc.CallOption) (*AppSendResponse, error)
+	// 接收投递到本节点的数据（服务端流，可同时存在多个接收方，每个接收方都收到全部数据）
+	Receive(ctx context.Context, in *AppReceiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AppMessage], error)
+	// 发送请求并等待目标节点上的应用应答
+	Request(ctx context.Context, in *AppRequest, opts ...grpc.CallOption) (*AppResponse, error)
+	// 应答收到的请求（AppMessage.request_id 不为空的消息）
+	Reply(ctx context.Context, in *AppReplyRequest, opts ...grpc.CallOption) (*AppReplyResponse, error)
+	// 查询到指定目标的路由
+	GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*GetRouteResponse, error)
+	// 查询本节点的完整路由表
+	GetRoutes(ctx context.Context, in *GetRoutesRequest, opts ...grpc.CallOption) (*GetRoutesResponse, error)
+	// 查询本节点视角下的集群拓扑
+	GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error)
+}
+
+type appServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAppServiceClient(cc grpc.ClientConnInterface) AppServiceClient {
+	return &appServiceClient{cc}
+}
+
+func (c *appServiceClient) Send(ctx context.Context, in *AppSendRequest, opts ...grpc.CallOption) (*AppSendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppSendResponse)
+	err := c.cc.Invoke(ctx, AppService_Send_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) Receive(ctx context.Context, in *AppReceiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AppMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AppService_ServiceDesc.Streams[0], AppService_Receive_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AppReceiveRequest, AppMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AppService_ReceiveClient = grpc.ServerStreamingClient[AppMessage]
+
+func (c *appServiceClient) Request(ctx context.Context, in *AppRequest, opts ...grpc.CallOption) (*AppResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppResponse)
+	err := c.cc.Invoke(ctx, AppService_Request_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) Reply(ctx context.Context, in *AppReplyRequest, opts ...grpc.CallOption) (*AppReplyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppReplyResponse)
+	err := c.cc.Invoke(ctx, AppService_Reply_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*GetRouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRouteResponse)
+	err := c.cc.Invoke(ctx, AppService_GetRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) GetRoutes(ctx context.Context, in *GetRoutesRequest, opts ...grpc.CallOption) (*GetRoutesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRoutesResponse)
+	err := c.cc.Invoke(ctx, AppService_GetRoutes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTopologyResponse)
+	err := c.cc.Invoke(ctx, AppService_GetTopology_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AppServiceServer is the server API for AppService service.
+// All implementations must embed UnimplementedAppServiceServer
+// for forward compatibility.
+//
+// ==================== 本地应用服务 ====================
+// spf_route 在 Unix 域套接字或回环地址上为同机的应用提供的收发接口（sidecar 模式），
+// 使非 Go 应用无需引入 spfnet 包即可使用覆盖网络
+type AppServiceServer interface {
+	// 发送数据到目标节点或服务
+	Send(context.Context, *AppSendRequest) (*AppSendResponse, error)
+	// 接收投递到本节点的数据（服务端流，可同时存在多个接收方，每个接收方都收到全部数据）
+	Receive(*AppReceiveRequest, grpc.ServerStreamingServer[AppMessage]) error
+	// 发送请求并等待目标节点上的应用应答
+	Request(context.Context, *AppRequest) (*AppResponse, error)
+	// 应答收到的请求（AppMessage.request_id 不为空的消息）
+	Reply(context.Context, *AppReplyRequest) (*AppReplyResponse, error)
+	// 查询到指定目标的路由
+	GetRoute(context.Context, *GetRouteRequest) (*GetRouteResponse, error)
+	// 查询本节点的完整路由表
+	GetRoutes(context.Context, *GetRoutesRequest) (*GetRoutesResponse, error)
+	// 查询本节点视角下的集群拓扑
+	GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyResponse, error)
+	mustEmbedUnimplementedAppServiceServer()
+}
+
+// UnimplementedAppServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAppServiceServer struct{}
+
+func (UnimplementedAppServiceServer) Send(context.Context, *AppSendRequest) (*AppSendResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Send not implemented")
+}
+func (UnimplementedAppServiceServer) Receive(*AppReceiveRequest, grpc.ServerStreamingServer[AppMessage]) error {
+	return status.Error(codes.Unimplemented, "method Receive not implemented")
+}
+func (UnimplementedAppServiceServer) Request(context.Context, *AppRequest) (*AppResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Request not implemented")
+}
+func (UnimplementedAppServiceServer) Reply(context.Context, *AppReplyRequest) (*AppReplyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Reply not implemented")
+}
+func (UnimplementedAppServiceServer) GetRoute(context.Context, *GetRouteRequest) (*GetRouteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRoute not implemented")
+}
+func (UnimplementedAppServiceServer) GetRoutes(context.Context, *GetRoutesRequest) (*GetRoutesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRoutes not implemented")
+}
+func (UnimplementedAppServiceServer) GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTopology not implemented")
+}
+func (UnimplementedAppServiceServer) mustEmbedUnimplementedAppServiceServer() {}
+func (UnimplementedAppServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeAppServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AppServiceServer will
+// result in compilation errors.
+type UnsafeAppServiceServer interface {
+	mustEmbedUnimplementedAppServiceServer()
+}
+
+func RegisterAppServiceServer(s grpc.ServiceRegistrar, srv AppServiceServer) {
+	// If the following call panics, it indicates UnimplementedAppServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AppService_ServiceDesc, srv)
+}
+
+func _AppService_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppSendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_Send_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).Send(ctx, req.(*AppSendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_Receive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AppReceiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AppServiceServer).Receive(m, &grpc.GenericServerStream[AppReceiveRequest, AppMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AppService_ReceiveServer = grpc.ServerStreamingServer[AppMessage]
+
+func _AppService_Request_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).Request(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_Request_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).Request(ctx, req.(*AppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_Reply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppReplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).Reply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_Reply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).Reply(ctx, req.(*AppReplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_GetRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).GetRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_GetRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).GetRoute(ctx, req.(*GetRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_GetRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).GetRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_GetRoutes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).GetRoutes(ctx, req.(*GetRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_GetTopology_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopologyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).GetTopology(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppService_GetTopology_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).GetTopology(ctx, req.(*GetTopologyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AppService_ServiceDesc is the grpc.ServiceDesc for AppService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AppService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spfnet.AppService",
+	HandlerType: (*AppServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _AppService_Send_Handler,
+		},
+		{
+			MethodName: "Request",
+			Handler:    _AppService_Request_Handler,
+		},
+		{
+			MethodName: "Reply",
+			Handler:    _AppService_Reply_Handler,
+		},
+		{
+			MethodName: "GetRoute",
+			Handler:    _AppService_GetRoute_Handler,
+		},
+		{
+			MethodName: "GetRoutes",
+			Handler:    _AppService_GetRoutes_Handler,
+		},
+		{
+			MethodName: "GetTopology",
+			Handler:    _AppService_GetTopology_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Receive",
+			Handler:       _AppService_Receive_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "node.proto",
 }
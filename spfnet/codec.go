@@ -0,0 +1,142 @@
+package spfnet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderContentType 标识数据编码方式的元数据键
+const HeaderContentType = "content-type"
+
+// 内置编解码器的 content-type
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// ErrUnknownContentType 没有为 content-type 注册编解码器，或消息没有 content-type
+var ErrUnknownContentType = errors.New("unknown content type")
+
+// Codec 类型化消息的编解码器
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	codecMtx sync.RWMutex
+	codecs   = map[string]Codec{
+		ContentTypeJSON:     jsonCodec{},
+		ContentTypeProtobuf: protoCodec{},
+	}
+)
+
+// RegisterCodec 为 content-type 注册编解码器，已注册时替换
+// 内置 application/json 和 application/x-protobuf
+func RegisterCodec(contentType string, codec Codec) {
+	codecMtx.Lock()
+	defer codecMtx.Unlock()
+	codecs[normalizeContentType(contentType)] = codec
+}
+
+// LookupCodec 查找 content-type 对应的编解码器（忽略大小写和 ";charset=..." 等参数）
+func LookupCodec(contentType string) (Codec, bool) {
+	codecMtx.RLock()
+	defer codecMtx.RUnlock()
+	codec, ok := codecs[normalizeContentType(contentType)]
+	return codec, ok
+}
+
+// normalizeContentType 去掉参数并转为小写
+func normalizeContentType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// Marshal 按 content-type 编码消息
+func Marshal(contentType string, v any) ([]byte, error) {
+	codec, ok := LookupCodec(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownContentType, contentType)
+	}
+	return codec.Marshal(v)
+}
+
+// ContentType 返回消息的 content-type 元数据
+func (m *Message) ContentType() string {
+	return m.Headers[HeaderContentType]
+}
+
+// Decode 按消息的 content-type 将数据解码到 v
+// 消息没有 content-type 或未注册对应的编解码器时返回 ErrUnknownContentType
+//
+// 示例：
+//
+//	var order Order
+//	if err := msg.Decode(&order); err != nil {
+//	    log.Printf("bad message: %v", err)
+//	}
+func (m *Message) Decode(v any) error {
+	contentType := m.ContentType()
+	codec, ok := LookupCodec(contentType)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownContentType, contentType)
+	}
+	return codec.Unmarshal(m.Data, v)
+}
+
+// SendTyped 按 content-type 编码 v 并发送，接收方可通过 Message.Decode 解码
+//
+// 示例：
+//
+//	err := node.SendTyped("nodeC", spfnet.ContentTypeJSON, Order{ID: 42})
+func (n *Node) SendTyped(destination, contentType string, v any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.SendTypedContext(ctx, destination, contentType, v)
+}
+
+// SendTypedContext 使用自定义 context 发送类型化消息
+func (n *Node) SendTypedContext(ctx context.Context, destination, contentType string, v any) error {
+	data, err := Marshal(contentType, v)
+	if err != nil {
+		return err
+	}
+	return n.SendWithHeadersContext(ctx, destination, map[string]string{HeaderContentType: contentType}, data)
+}
+
+// jsonCodec application/json 编解码器
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// protoCodec application/x-protobuf 编解码器，v 必须实现 proto.Message
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
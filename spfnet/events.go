@@ -0,0 +1,170 @@
+package spfnet
+
+import (
+	"context"
+	"time"
+
+	"spfnet/internal/route"
+	pb "spfnet/proto"
+)
+
+// EventKind 事件类别
+type EventKind int
+
+const (
+	EventMemberJoin   EventKind = iota + 1 // 节点加入集群
+	EventMemberLeave                       // 节点主动离开集群
+	EventMemberFailed                      // 节点失联
+	EventLinkAdd                           // 新增链路
+	EventLinkUpdate                        // 链路成本变化
+	EventLinkRemove                        // 删除链路
+	EventRouteAdd                          // 新增到某目标的路由
+	EventRouteChange                       // 到某目标的路由变化（下一跳、成本或路径）
+	EventRouteRemove                       // 删除到某目标的路由
+	EventReachable                         // 目标从不可达变为可达
+	EventUnreachable                       // 目标从可达变为不可达
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventMemberJoin:
+		return "member_join"
+	case EventMemberLeave:
+		return "member_leave"
+	case EventMemberFailed:
+		return "member_failed"
+	case EventLinkAdd:
+		return "link_add"
+	case EventLinkUpdate:
+		return "link_update"
+	case EventLinkRemove:
+		return "link_remove"
+	case EventRouteAdd:
+		return "route_add"
+	case EventRouteChange:
+		return "route_change"
+	case EventRouteRemove:
+		return "route_remove"
+	case EventReachable:
+		return "reachable"
+	case EventUnreachable:
+		return "unreachable"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 成员、链路、路由或可达性变化事件
+type Event struct {
+	Kind EventKind
+	Time time.Time
+
+	Node    string // 成员事件：节点 ID；路由和可达性事件：目标节点 ID
+	Address string // 成员事件：节点 gRPC 地址
+
+	From string // 链路事件：链路两端节点 ID
+	To   string
+
+	OldCost float64 // 链路或路由事件：变化前后的成本
+	Cost    float64
+
+	OldNextHop string // 路由事件：变化前后的下一跳
+	NextHop    string
+	OldPath    []string // 路由事件：变化前后的完整路径
+	Path       []string
+}
+
+// eventBuffer 每个订阅返回的通道缓冲区大小
+const eventBuffer = 64
+
+// kindsByType 底层事件类型对应的 SDK 事件类别
+var kindsByType = map[pb.EventType][]EventKind{
+	pb.EventType_EVENT_TYPE_MEMBER_JOIN:   {EventMemberJoin},
+	pb.EventType_EVENT_TYPE_MEMBER_LEAVE:  {EventMemberLeave},
+	pb.EventType_EVENT_TYPE_MEMBER_FAILED: {EventMemberFailed},
+	pb.EventType_EVENT_TYPE_LINK_ADD:      {EventLinkAdd},
+	pb.EventType_EVENT_TYPE_LINK_UPDATE:   {EventLinkUpdate},
+	pb.EventType_EVENT_TYPE_LINK_REMOVE:   {EventLinkRemove},
+	pb.EventType_EVENT_TYPE_ROUTE_ADD:     {EventRouteAdd, EventReachable},
+	pb.EventType_EVENT_TYPE_ROUTE_CHANGE:  {EventRouteChange},
+	pb.EventType_EVENT_TYPE_ROUTE_REMOVE:  {EventRouteRemove, EventUnreachable},
+}
+
+// Subscribe 订阅指定类别的事件（kinds 为空表示全部）
+// 事件通过返回的通道按发生顺序送达；ctx 取消后取消订阅并关闭通道。
+// 可以同时存在多个订阅，互不影响；订阅者处理过慢时，超出缓冲区的事件会被丢弃
+//
+// 示例：
+//
+//	events := node.Subscribe(ctx, spfnet.EventReachable, spfnet.EventUnreachable)
+//	for ev := range events {
+//	    log.Printf("%s is now %s", ev.Node, ev.Kind)
+//	}
+func (n *Node) Subscribe(ctx context.Context, kinds ...EventKind) <-chan Event {
+	wanted := make(map[EventKind]bool, len(kinds))
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+
+	// 只向底层订阅需要的事件类型
+	var types []pb.EventType
+	if len(wanted) > 0 {
+		for eventType, mapped := range kindsByType {
+			for _, kind := range mapped {
+				if wanted[kind] {
+					types = append(types, eventType)
+					break
+				}
+			}
+		}
+	}
+
+	source, cancel := n.routeNode.SubscribeEvents(types...)
+	out := make(chan Event, eventBuffer)
+
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case raw, ok := <-source:
+				if !ok {
+					return
+				}
+				for _, kind := range kindsByType[raw.Type] {
+					if len(wanted) > 0 && !wanted[kind] {
+						continue
+					}
+					select {
+					case out <- newEvent(kind, raw):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// newEvent 将底层事件转换为 SDK 事件
+func newEvent(kind EventKind, raw *route.Event) Event {
+	return Event{
+		Kind:       kind,
+		Time:       raw.Time,
+		Node:       raw.Node,
+		Address:    raw.Address,
+		From:       raw.From,
+		To:         raw.To,
+		OldCost:    raw.OldCost,
+		Cost:       raw.Cost,
+		OldNextHop: raw.OldNextHop,
+		NextHop:    raw.NextHop,
+		OldPath:    raw.OldPath,
+		Path:       raw.Path,
+	}
+}
@@ -0,0 +1,31 @@
+package spfnet
+
+import (
+	"context"
+
+	"spfnet/internal/route"
+)
+
+// FileTransferResult 文件发送结果
+type FileTransferResult = route.FileTransferResult
+
+// SendFile 将本地文件发送到目的节点的收件目录（文件名与源文件相同）
+// 文件按块经覆盖网络传输，每块带 CRC32，目的节点收齐后校验整个文件的 SHA-256；
+// 连接中断时自动从断点续传，重新调用也会从目的节点已收到的位置继续。
+// 目的节点未开启文件接收或校验失败时返回的错误满足 errors.Is(err, ErrFileRejected)
+//
+// 示例：
+//
+//	result, err := node.SendFile("nodeC", "/data/build.tar.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("sent %d bytes, sha256 %s\n", result.Size, result.SHA256)
+func (n *Node) SendFile(destination, path string) (*FileTransferResult, error) {
+	return n.SendFileContext(context.Background(), destination, path, "")
+}
+
+// SendFileContext 使用自定义 context 发送文件，name 不为空时作为目的节点收件目录中的文件名
+func (n *Node) SendFileContext(ctx context.Context, destination, path, name string) (*FileTransferResult, error) {
+	return n.routeNode.SendFile(ctx, destination, path, name)
+}
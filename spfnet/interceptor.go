@@ -0,0 +1,40 @@
+package spfnet
+
+import (
+	"spfnet/internal/route"
+	pb "spfnet/proto"
+)
+
+// Packet 覆盖网络数据包，拦截器可以读取和修改其中的载荷（Payload）和元数据（Headers）
+type Packet = pb.Packet
+
+// InterceptPoint 拦截器的调用位置
+type InterceptPoint = route.InterceptPoint
+
+// 拦截器的调用位置
+const (
+	InterceptOutbound = route.InterceptOutbound // 本节点发出数据包时
+	InterceptForward  = route.InterceptForward  // 转发其他节点的数据包前
+	InterceptDeliver  = route.InterceptDeliver  // 投递给接收回调前
+)
+
+// Interceptor 数据包拦截器，用于审计、过滤、打标签或转换载荷
+// 在发送、转发和投递业务数据包（单播、多播和主题发布）时按注册顺序调用，可以直接修改数据包；
+// 返回错误时丢弃数据包，源节点收到 errors.Is(err, ErrAdminProhibited) 的差错
+type Interceptor = route.Interceptor
+
+// InterceptorFunc 函数形式的拦截器
+//
+// 示例：
+//
+//	audit := spfnet.InterceptorFunc(func(ctx context.Context, point spfnet.InterceptPoint, p *spfnet.Packet) error {
+//	    log.Printf("%s %s -> %s (%d bytes)", point, p.Source, p.Destination, len(p.Payload))
+//	    return nil
+//	})
+//	node, err := spfnet.NewNode(spfnet.Config{..., Interceptors: []spfnet.Interceptor{audit}})
+type InterceptorFunc = route.InterceptorFunc
+
+// AddInterceptor 在拦截器链末尾追加拦截器
+func (n *Node) AddInterceptor(interceptor Interceptor) {
+	n.routeNode.AddInterceptor(interceptor)
+}
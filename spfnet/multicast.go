@@ -0,0 +1,84 @@
+package spfnet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"spfnet/internal/route"
+)
+
+// BroadcastGroup 广播消息的组名（Message.Group 为该值表示收到的是广播）
+const BroadcastGroup = route.BroadcastGroup
+
+// MulticastResult 多播发送结果（Targets 为发送时可达的目标，Failed 为未能投递的目标）
+type MulticastResult = route.MulticastResult
+
+// JoinGroup 加入多播组，变更会通过集群成员信息同步到其他节点
+func (n *Node) JoinGroup(group string) error {
+	return n.routeNode.JoinGroup(group)
+}
+
+// LeaveGroup 离开多播组，离开后不再收到发往该组的消息
+func (n *Node) LeaveGroup(group string) error {
+	return n.routeNode.LeaveGroup(group)
+}
+
+// Groups 返回本节点加入的多播组
+func (n *Node) Groups() []string {
+	return n.routeNode.Groups()
+}
+
+// GroupMembers 返回多播组中当前可达的成员（按节点 ID 排序，不含本节点）
+func (n *Node) GroupMembers(group string) []string {
+	return n.routeNode.GroupMembers(group)
+}
+
+// Multicast 向多播组的所有可达成员发送数据（不含本节点）
+// 数据沿以本节点为根的最短路径树分发，只在路径分叉的节点复制，共享链路上只传输一份。
+// 部分成员未能投递时返回的错误满足 errors.Is(err, ErrDestinationUnreachable)，
+// 具体名单见 MulticastResult.Failed；组内没有可达成员时返回 ErrNoGroupMembers
+//
+// 示例：
+//
+//	result, err := node.Multicast("alerts", []byte("disk full"))
+//	if err != nil {
+//	    log.Printf("failed: %v", result.Failed)
+//	}
+func (n *Node) Multicast(group string, data []byte) (*MulticastResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.MulticastWithContext(ctx, group, data)
+}
+
+// MulticastWithContext 使用自定义 context 向多播组发送数据
+func (n *Node) MulticastWithContext(ctx context.Context, group string, data []byte) (*MulticastResult, error) {
+	if group == "" || group == BroadcastGroup {
+		return nil, fmt.Errorf("invalid group name %q", group)
+	}
+	return multicastResult(n.routeNode.SendMulticast(ctx, group, data))
+}
+
+// Broadcast 向集群中所有可达节点发送数据（不含本节点），分发方式与 Multicast 相同
+func (n *Node) Broadcast(data []byte) (*MulticastResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.BroadcastWithContext(ctx, data)
+}
+
+// BroadcastWithContext 使用自定义 context 向集群中所有可达节点发送数据
+func (n *Node) BroadcastWithContext(ctx context.Context, data []byte) (*MulticastResult, error) {
+	return multicastResult(n.routeNode.SendBroadcast(ctx, data))
+}
+
+// multicastResult 部分目标未能投递时转换为错误
+func multicastResult(result *MulticastResult, err error) (*MulticastResult, error) {
+	if err != nil || len(result.Failed) == 0 {
+		return result, err
+	}
+	return result, fmt.Errorf("%w: %d of %d targets failed (%s)", ErrDestinationUnreachable,
+		len(result.Failed), len(result.Targets), strings.Join(result.Failed, ", "))
+}
@@ -3,17 +3,69 @@ package spfnet
 import (
 	"context"
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 
 	"spfnet/internal/route"
+	pb "spfnet/proto"
 )
 
 // Node 是对外提供的应用节点
 // 封装了复杂的网络和路由逻辑，业务层只需调用简单的 API
 type Node struct {
 	routeNode *route.RouteNode
+
+	// 业务数据接收
+	handlerMtx sync.RWMutex
+	onReceive  func(*Message)
+	reorder    *reorderBuffer
+
+	// 有序流发送状态 (destination, flow) -> 纪元和已发送的最大序号
+	seqMtx sync.Mutex
+	seqs   map[flowKey]*sendFlow
+
+	// 主题订阅 topic -> 回调
+	topicMtx      sync.RWMutex
+	topics        map[string]topicHandlers
+	nextHandlerID uint64
 }
 
+// Message 本节点收到的业务数据
+type Message struct {
+	Source    string            // 源节点 ID
+	PacketID  string            // 数据包 ID
+	Service   string            // 目标服务名（通过 SendToService 发送时）
+	Group     string            // 多播组名（通过 Multicast 发送时；广播时为 BroadcastGroup）
+	Topic     string            // 发布的主题（通过 Publish 发送时）
+	FlowID    string            // 有序流标识（无序发送时为空）
+	Sequence  uint64            // 有序流内的序号（无序发送时为 0）
+	FlowEpoch uint64            // 有序流的纪元，发送端重启或流空闲过久后重新开始时增大
+	Path      []string          // 数据包经过的节点
+	Headers   map[string]string // 发送方设置的元数据（如 content-type），没有时为 nil
+	RequestID string            // 请求 ID（对端通过 sidecar 的 Request 发送并等待应答时不为空，用 Reply 应答）
+	Data      []byte            // 业务数据
+}
+
+// ControlError 数据包无法投递时由失败节点返回的差错（类似 ICMP 差错报文）
+// 可通过 errors.As 获取差错详情，或通过 errors.Is 与下列错误比较
+type ControlError = route.ControlError
+
+// 数据包无法投递的原因
+var (
+	ErrDestinationUnreachable = route.ErrDestinationUnreachable // 目标不可达
+	ErrTTLExceeded            = route.ErrTTLExceeded            // TTL 耗尽
+	ErrFragmentationNeeded    = route.ErrFragmentationNeeded    // 载荷超过转发节点的长度限制
+	ErrAdminProhibited        = route.ErrAdminProhibited        // 被转发节点的策略禁止
+	ErrServiceUnavailable     = route.ErrServiceUnavailable     // 没有可达的服务提供者
+	ErrNoGroupMembers         = route.ErrNoGroupMembers         // 多播组中没有可达的成员
+	ErrNoSubscribers          = route.ErrNoSubscribers          // 主题没有可达的订阅者
+	ErrConnectionRefused      = route.ErrConnectionRefused      // 目标端口没有监听者
+	ErrConnectionReset        = route.ErrConnectionReset        // 字节流连接被对端重置
+	ErrStreamTimeout          = route.ErrStreamTimeout          // 字节流重传次数耗尽仍未收到确认
+	ErrFileRejected           = route.ErrFileRejected           // 目标节点拒绝接收文件或校验失败
+)
+
 // Config 应用节点配置
 type Config struct {
 	// 必填项
@@ -23,8 +75,15 @@ type Config struct {
 	SerfPort int    // Serf 端口，如 7001
 
 	// 可选项
-	AppConfigPath string // 应用配置文件路径，默认 "configs/app.toml"
-	JoinAddr      string // 加入的集群地址，如 "127.0.0.1:7001"
+	AppConfigPath     string        // 应用配置文件路径，默认 "configs/app.toml"
+	JoinAddr          string        // 加入的集群地址，如 "127.0.0.1:7001"
+	OrderedGapTimeout time.Duration // 有序流等待缺失序号的超时，默认 2s
+	Services          []string      // 本节点提供的服务名，其他节点可通过 SendToService 访问
+	Groups            []string      // 本节点加入的多播组，其他节点可通过 Multicast 发送
+	InboxDir          string        // 设置后接收其他节点 SendFile 发来的文件，写入 {InboxDir}/{NodeID}
+	Interceptors      []Interceptor // 数据包拦截器，按顺序在发送、转发和投递业务数据包时调用
+	KeyDir            string        // 设置后启用端到端加密，私钥保存在 {KeyDir}/{NodeID}.key
+	RequireEncryption bool          // 拒绝发往没有公钥的节点，丢弃收到的未加密数据包（需同时设置 KeyDir）
 }
 
 // NewNode 创建一个新的应用节点实例
@@ -56,16 +115,34 @@ func NewNode(cfg Config) (*Node, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load runtime config: %w", err)
 	}
+	rtConfig.Services = cfg.Services
+	rtConfig.Groups = cfg.Groups
+	if cfg.InboxDir != "" {
+		rtConfig.AppConfig.FileTransfer.Enabled = true
+		rtConfig.AppConfig.FileTransfer.InboxDir = cfg.InboxDir
+	}
+	if cfg.KeyDir != "" {
+		rtConfig.AppConfig.Encryption.Enabled = true
+		rtConfig.AppConfig.Encryption.KeyDir = cfg.KeyDir
+		rtConfig.AppConfig.Encryption.Require = cfg.RequireEncryption
+	}
 
 	// 创建路由节点
 	routeNode := route.NewRouteNode(rtConfig)
+	routeNode.SetInterceptors(cfg.Interceptors...)
 	if err := routeNode.Init(); err != nil {
 		return nil, fmt.Errorf("failed to initialize node: %w", err)
 	}
 
-	return &Node{
+	n := &Node{
 		routeNode: routeNode,
-	}, nil
+		seqs:      make(map[flowKey]*sendFlow),
+		topics:    make(map[string]topicHandlers),
+	}
+	n.reorder = newReorderBuffer(cfg.OrderedGapTimeout, n.dispatch)
+	routeNode.SetDeliveryHandler(n.handleDelivery)
+
+	return n, nil
 }
 
 // Start 启动应用节点
@@ -88,9 +165,14 @@ func (n *Node) Stop() {
 //   - destination: 目标节点 ID，如 "nodeC"
 //   - data: 要发送的数据
 //
+// 数据包无法投递时返回 *ControlError
+//
 // 示例：
 //
 //	err := node.Send("nodeC", []byte("hello world"))
+//	if errors.Is(err, spfnet.ErrDestinationUnreachable) {
+//	    // 目标不可达
+//	}
 func (n *Node) Send(destination string, data []byte) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -103,6 +185,171 @@ func (n *Node) SendWithContext(ctx context.Context, destination string, data []b
 	return n.routeNode.SendPacket(ctx, destination, data)
 }
 
+// SendWithHeaders 发送带元数据的数据，元数据随数据包原样送达，接收方通过 Message.Headers 读取
+// 键区分大小写，约定使用小写（如 "content-type"、"trace-id"）
+//
+// 示例：
+//
+//	err := node.SendWithHeaders("nodeC", map[string]string{"trace-id": "abc123"}, []byte("hello"))
+func (n *Node) SendWithHeaders(destination string, headers map[string]string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.SendWithHeadersContext(ctx, destination, headers, data)
+}
+
+// SendWithHeadersContext 使用自定义 context 发送带元数据的数据
+func (n *Node) SendWithHeadersContext(ctx context.Context, destination string, headers map[string]string, data []byte) error {
+	return n.routeNode.SendPacketWithOptions(ctx, destination, data, route.SendOptions{
+		Headers: maps.Clone(headers),
+	})
+}
+
+// Reply 应答对端的请求（msg.RequestID 不为空的消息），应答发回请求的源节点
+// 对端通过 sidecar 的 Request 等待应答，只接受来自请求目的节点（即本节点）的应答
+//
+// 示例：
+//
+//	node.SetReceiveHandler(func(msg *spfnet.Message) {
+//	    if msg.RequestID != "" {
+//	        node.Reply(context.Background(), msg, []byte("pong"))
+//	    }
+//	})
+func (n *Node) Reply(ctx context.Context, msg *Message, data []byte) error {
+	if msg.RequestID == "" {
+		return fmt.Errorf("message %s is not a request", msg.PacketID)
+	}
+	return n.routeNode.SendPacketWithOptions(ctx, msg.Source, data, route.SendOptions{
+		ReplyTo: msg.RequestID,
+	})
+}
+
+// SendOrdered 在有序流中发送数据
+// 同一 (目标节点, flowID) 上的消息会被分配递增序号，目的节点按序号顺序交给接收回调，
+// 即使数据包因重试、异步转发等原因乱序到达
+//
+// 示例：
+//
+//	err := node.SendOrdered("nodeC", "orders", []byte("order-1"))
+func (n *Node) SendOrdered(destination, flowID string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.SendOrderedWithContext(ctx, destination, flowID, data)
+}
+
+// SendOrderedWithContext 使用自定义 context 在有序流中发送数据
+func (n *Node) SendOrderedWithContext(ctx context.Context, destination, flowID string, data []byte) error {
+	if flowID == "" {
+		return fmt.Errorf("flow ID cannot be empty")
+	}
+
+	// 同一流上的发送串行进行，发送失败时不占用序号，避免目的端等待永远不会到达的序号
+	flow := n.getSendFlow(flowKey{peer: destination, flow: flowID})
+	defer flow.mtx.Unlock()
+
+	err := n.routeNode.SendPacketWithOptions(ctx, destination, data, route.SendOptions{
+		FlowID:    flowID,
+		Sequence:  flow.seq + 1,
+		FlowEpoch: flow.epoch,
+	})
+	if err == nil {
+		flow.seq++
+	}
+	return err
+}
+
+// SendToService 发送数据到提供指定服务的最近节点
+// 按路由成本从低到高选择可达的提供者（不含本节点），提供者不可达时自动改投下一个较近的提供者。
+// 返回最终接收数据的节点 ID；没有可达的提供者时返回的错误满足 errors.Is(err, ErrServiceUnavailable)
+//
+// 示例：
+//
+//	provider, err := node.SendToService("billing", []byte("invoice-42"))
+func (n *Node) SendToService(service string, data []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.routeNode.SendToService(ctx, service, data)
+}
+
+// SendToServiceWithContext 使用自定义 context 发送数据到提供指定服务的最近节点
+func (n *Node) SendToServiceWithContext(ctx context.Context, service string, data []byte) (string, error) {
+	return n.routeNode.SendToService(ctx, service, data)
+}
+
+// ServiceProviders 返回提供指定服务且当前可达的节点，按路由成本从低到高排序（不含本节点）
+func (n *Node) ServiceProviders(service string) []string {
+	return n.routeNode.ServiceProviders(service)
+}
+
+// AdvertiseService 声明本节点提供指定服务，变更会通过集群成员信息同步到其他节点
+func (n *Node) AdvertiseService(service string) error {
+	return n.routeNode.AdvertiseService(service)
+}
+
+// WithdrawService 撤销本节点提供的指定服务
+// 撤销后发往本节点的该服务数据包会被拒绝，发送方自动改投其他提供者
+func (n *Node) WithdrawService(service string) error {
+	return n.routeNode.WithdrawService(service)
+}
+
+// SetReceiveHandler 设置接收业务数据的回调
+// 回调在接收数据包的 gRPC 请求中同步执行，耗时操作应自行异步处理
+// 有序流消息按序号顺序回调；缺失的序号超过 Config.OrderedGapTimeout 仍未到达时会被跳过
+func (n *Node) SetReceiveHandler(handler func(msg *Message)) {
+	n.handlerMtx.Lock()
+	defer n.handlerMtx.Unlock()
+	n.onReceive = handler
+}
+
+// handleDelivery 处理投递到本节点的数据包
+func (n *Node) handleDelivery(packet *pb.Packet) {
+	msg := &Message{
+		Source:    packet.Source,
+		PacketID:  packet.PacketId,
+		Service:   packet.Service,
+		Group:     packet.Group,
+		Topic:     packet.Topic,
+		FlowID:    packet.FlowId,
+		Sequence:  packet.Sequence,
+		FlowEpoch: packet.FlowEpoch,
+		Path:      packet.VisitedNodes,
+		Headers:   packet.Headers,
+		RequestID: packet.RequestId,
+		Data:      packet.Payload,
+	}
+
+	if msg.Topic != "" {
+		n.dispatchTopic(msg)
+		return
+	}
+	if msg.FlowID != "" && msg.Sequence > 0 {
+		n.reorder.push(msg)
+		return
+	}
+	n.dispatch(msg)
+}
+
+// dispatch 将消息交给业务回调
+func (n *Node) dispatch(msg *Message) {
+	n.handlerMtx.RLock()
+	handler := n.onReceive
+	n.handlerMtx.RUnlock()
+
+	if handler != nil {
+		handler(msg)
+	}
+}
+
+// SetUndeliverableHandler 设置数据包无法投递时的回调
+// 只在没有同步调用方等待结果时触发：存储转发的中间节点已经确认了数据包、之后放弃转发时，
+// 会向本节点发回控制报文，框架将其转换为 *ControlError 交给回调处理。
+// 同步转发失败时差错由 Send 等方法直接返回，不会再触发回调
+func (n *Node) SetUndeliverableHandler(handler func(err *ControlError)) {
+	n.routeNode.SetControlHandler(handler)
+}
+
 // AddLink 添加到邻居节点的链路
 // 参数：
 //   - neighborID: 邻居节点 ID
@@ -116,7 +363,30 @@ func (n *Node) AddLink(neighborID, neighborAddr string, cost float64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	return n.routeNode.AddLink(ctx, neighborID, neighborAddr, cost, true)
+	return n.routeNode.AddLink(ctx, neighborID, neighborAddr, cost, cost <= 0)
+}
+
+// RemoveLink 删除到邻居节点的链路，变更会同步到整个集群
+// 示例：
+//
+//	err := node.RemoveLink("nodeB")
+func (n *Node) RemoveLink(neighborID string) error {
+	return n.routeNode.RemoveLink(neighborID)
+}
+
+// SetLinkCost 修改到邻居节点的链路成本，变更会同步到整个集群
+// 参数：
+//   - neighborID: 邻居节点 ID
+//   - cost: 链路成本，大于 0 时固定为该值，0 表示重新自动探测
+//
+// 示例：
+//
+//	err := node.SetLinkCost("nodeB", 20)
+func (n *Node) SetLinkCost(neighborID string, cost float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.routeNode.SetLinkCost(ctx, neighborID, cost, cost <= 0)
 }
 
 // EnableSync 启用或禁用拓扑同步
@@ -128,3 +398,8 @@ func (n *Node) EnableSync(enabled bool) error {
 func (n *Node) IsSyncEnabled() bool {
 	return n.routeNode.IsSyncEnabled()
 }
+
+// PublicKey 返回本节点的端到端加密公钥（X25519），未启用加密时返回 nil
+func (n *Node) PublicKey() []byte {
+	return n.routeNode.PublicKey()
+}
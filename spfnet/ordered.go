@@ -0,0 +1,252 @@
+package spfnet
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultOrderedGapTimeout 有序流等待缺失序号的默认超时
+const DefaultOrderedGapTimeout = 2 * time.Second
+
+// maxIdleFlows 超过该数量时清理空闲的有序流状态
+const maxIdleFlows = 1024
+
+// orderedFlowIdle 发送端的有序流空闲超过该时间后以新的纪元从序号 1 重新开始，
+// 目的端空闲超过其两倍的有序流才会被清理，保证清理后收到的消息总是带有新的纪元
+const orderedFlowIdle = 30 * time.Second
+
+// sendFlow 发送端的有序流状态
+type sendFlow struct {
+	mtx      sync.Mutex
+	epoch    uint64 // 流的纪元（创建时的 Unix 纳秒时间）
+	seq      uint64 // 已成功发送的最大序号
+	lastUsed time.Time
+	removed  bool // 已被清理，持有者需重新获取
+}
+
+// getSendFlow 获取或创建发往对端的有序流状态，返回时已持有其锁
+func (n *Node) getSendFlow(key flowKey) *sendFlow {
+	for {
+		n.seqMtx.Lock()
+		flow, ok := n.seqs[key]
+		if !ok {
+			if len(n.seqs) >= maxIdleFlows {
+				n.pruneSendFlows()
+			}
+			flow = &sendFlow{}
+			n.seqs[key] = flow
+		}
+		n.seqMtx.Unlock()
+
+		flow.mtx.Lock()
+		if flow.removed {
+			flow.mtx.Unlock()
+			continue
+		}
+		if flow.epoch == 0 || time.Since(flow.lastUsed) > orderedFlowIdle {
+			flow.epoch = uint64(time.Now().UnixNano())
+			flow.seq = 0
+		}
+		flow.lastUsed = time.Now()
+		return flow
+	}
+}
+
+// pruneSendFlows 清理空闲的发送端有序流状态（调用方持有 n.seqMtx）
+func (n *Node) pruneSendFlows() {
+	for key, flow := range n.seqs {
+		if !flow.mtx.TryLock() {
+			continue // 正在发送
+		}
+		if time.Since(flow.lastUsed) > orderedFlowIdle {
+			flow.removed = true
+			delete(n.seqs, key)
+		}
+		flow.mtx.Unlock()
+	}
+}
+
+// flowKey 有序流标识（对端节点 + 流 ID）
+type flowKey struct {
+	peer string
+	flow string
+}
+
+// reorderBuffer 目的端的有序流重排缓冲区
+// 按 (source, flow) 缓存乱序到达的消息，按序号依次交给业务回调；
+// 缺失的序号在 gapTimeout 内仍未到达时跳过，避免整个流被阻塞
+type reorderBuffer struct {
+	mtx        sync.Mutex
+	gapTimeout time.Duration
+	flows      map[flowKey]*flowState
+	deliver    func(*Message)
+}
+
+// flowState 单个有序流的状态
+type flowState struct {
+	mtx        sync.Mutex
+	epoch      uint64              // 当前纪元，收到更大的纪元时重置
+	next       uint64              // 期望的下一个序号
+	pending    map[uint64]*Message // 已到达但尚不能投递的消息
+	timer      *time.Timer         // 缺口等待定时器
+	lastActive time.Time
+	removed    bool // 已被清理，持有者需重新获取
+}
+
+func newReorderBuffer(gapTimeout time.Duration, deliver func(*Message)) *reorderBuffer {
+	if gapTimeout <= 0 {
+		gapTimeout = DefaultOrderedGapTimeout
+	}
+	return &reorderBuffer{
+		gapTimeout: gapTimeout,
+		flows:      make(map[flowKey]*flowState),
+		deliver:    deliver,
+	}
+}
+
+// push 接收一条有序流消息
+func (rb *reorderBuffer) push(msg *Message) {
+	key := flowKey{peer: msg.Source, flow: msg.FlowID}
+	st := rb.getFlow(key)
+	defer st.mtx.Unlock()
+
+	// 发送端重启或重新开始该流：先按序投递旧纪元缓存的消息，再从序号 1 开始
+	if msg.FlowEpoch > st.epoch {
+		if st.epoch != 0 {
+			log.Printf("Flow %s/%s restarted with epoch %d (was %d)", msg.Source, msg.FlowID, msg.FlowEpoch, st.epoch)
+		}
+		rb.flush(st)
+		st.epoch = msg.FlowEpoch
+		st.next = 1
+	} else if msg.FlowEpoch < st.epoch {
+		log.Printf("Dropping stale message %s (flow %s/%s epoch %d, current %d)",
+			msg.PacketID, msg.Source, msg.FlowID, msg.FlowEpoch, st.epoch)
+		return
+	}
+
+	if msg.Sequence < st.next {
+		log.Printf("Dropping stale message %s (flow %s/%s seq %d, expecting %d)",
+			msg.PacketID, msg.Source, msg.FlowID, msg.Sequence, st.next)
+		return
+	}
+	if _, exists := st.pending[msg.Sequence]; exists {
+		return
+	}
+
+	st.pending[msg.Sequence] = msg
+	rb.release(key, st)
+}
+
+// getFlow 获取或创建有序流状态，返回时已持有其锁
+// 获取锁之前状态可能已被 pruneIdle 清理，此时重新获取，避免消息缓存在已脱离 rb.flows 的状态中
+func (rb *reorderBuffer) getFlow(key flowKey) *flowState {
+	for {
+		rb.mtx.Lock()
+		st, ok := rb.flows[key]
+		if !ok {
+			if len(rb.flows) >= maxIdleFlows {
+				rb.pruneIdle()
+			}
+			st = &flowState{
+				next:    1,
+				pending: make(map[uint64]*Message),
+			}
+			rb.flows[key] = st
+		}
+		rb.mtx.Unlock()
+
+		st.mtx.Lock()
+		if st.removed {
+			st.mtx.Unlock()
+			continue
+		}
+		st.lastActive = time.Now()
+		return st
+	}
+}
+
+// pruneIdle 清理长时间没有消息且没有缓存的有序流（调用方持有 rb.mtx）
+func (rb *reorderBuffer) pruneIdle() {
+	idle := max(10*rb.gapTimeout, 2*orderedFlowIdle)
+	for key, st := range rb.flows {
+		if !st.mtx.TryLock() {
+			continue // 正在接收
+		}
+		if len(st.pending) == 0 && time.Since(st.lastActive) > idle {
+			st.removed = true
+			delete(rb.flows, key)
+		}
+		st.mtx.Unlock()
+	}
+}
+
+// release 按序投递所有连续的消息，并根据剩余缓存启停缺口定时器（调用方持有 st.mtx）
+func (rb *reorderBuffer) release(key flowKey, st *flowState) {
+	for {
+		msg, ok := st.pending[st.next]
+		if !ok {
+			break
+		}
+		delete(st.pending, st.next)
+		st.next++
+		rb.deliver(msg)
+	}
+
+	if len(st.pending) == 0 {
+		if st.timer != nil {
+			st.timer.Stop()
+			st.timer = nil
+		}
+		return
+	}
+
+	if st.timer == nil {
+		epoch := st.epoch
+		st.timer = time.AfterFunc(rb.gapTimeout, func() { rb.skipGap(key, st, epoch) })
+	}
+}
+
+// flush 按序号顺序投递所有缓存的消息并停止缺口定时器（调用方持有 st.mtx）
+func (rb *reorderBuffer) flush(st *flowState) {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	seqs := make([]uint64, 0, len(st.pending))
+	for seq := range st.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		rb.deliver(st.pending[seq])
+	}
+	st.pending = make(map[uint64]*Message)
+}
+
+// skipGap 缺口等待超时，跳过缺失的序号继续投递
+func (rb *reorderBuffer) skipGap(key flowKey, st *flowState, epoch uint64) {
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	// 定时器触发后流已切换到新的纪元，由新纪元的定时器处理
+	if st.epoch != epoch {
+		return
+	}
+	st.timer = nil
+	if len(st.pending) == 0 {
+		return
+	}
+
+	seqs := make([]uint64, 0, len(st.pending))
+	for seq := range st.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	log.Printf("Flow %s/%s: gap timeout, skipping sequence %d-%d",
+		key.peer, key.flow, st.next, seqs[0]-1)
+	st.next = seqs[0]
+	rb.release(key, st)
+}
@@ -0,0 +1,127 @@
+package spfnet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"spfnet/internal/route"
+)
+
+// SubscriberStats 发布者视角下某个主题订阅者的投递统计
+type SubscriberStats = route.SubscriberStats
+
+// topicHandlers 某个主题在本节点上注册的回调
+type topicHandlers map[uint64]func(*Message)
+
+// Publish 向订阅指定主题的所有可达节点发送数据（本节点订阅了该主题时也会收到）
+// 数据沿以本节点为根的最短路径树分发，只在路径分叉的节点复制。
+// 部分订阅者未能投递时返回的错误满足 errors.Is(err, ErrDestinationUnreachable)，
+// 具体名单见 MulticastResult.Failed；没有可达的订阅者时返回 ErrNoSubscribers
+//
+// 示例：
+//
+//	result, err := node.Publish("orders", []byte("order-42"))
+func (n *Node) Publish(topic string, data []byte) (*MulticastResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return n.PublishWithContext(ctx, topic, data)
+}
+
+// PublishWithContext 使用自定义 context 向主题发布数据
+func (n *Node) PublishWithContext(ctx context.Context, topic string, data []byte) (*MulticastResult, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("topic cannot be empty")
+	}
+	return multicastResult(n.routeNode.Publish(ctx, topic, data))
+}
+
+// SubscribeTopic 订阅主题，发布到该主题的消息交给 handler 处理
+// 同一主题可以注册多个回调，每条消息依次交给所有回调；主题消息不会交给 SetReceiveHandler 设置的回调。
+// 订阅通过集群成员信息同步到其他节点，返回的函数用于取消本次订阅（可重复调用），
+// 主题的最后一个回调取消后本节点不再接收该主题的消息
+//
+// 示例：
+//
+//	unsubscribe, err := node.SubscribeTopic("orders", func(msg *spfnet.Message) {
+//	    log.Printf("order from %s: %s", msg.Source, msg.Data)
+//	})
+//	defer unsubscribe()
+func (n *Node) SubscribeTopic(topic string, handler func(*Message)) (func(), error) {
+	if topic == "" {
+		return nil, fmt.Errorf("topic cannot be empty")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+
+	n.topicMtx.Lock()
+	defer n.topicMtx.Unlock()
+
+	handlers := n.topics[topic]
+	if len(handlers) == 0 {
+		if err := n.routeNode.SubscribeTopic(topic); err != nil {
+			return nil, err
+		}
+		handlers = make(topicHandlers)
+		n.topics[topic] = handlers
+	}
+
+	n.nextHandlerID++
+	id := n.nextHandlerID
+	handlers[id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { n.unsubscribeTopic(topic, id) })
+	}, nil
+}
+
+// unsubscribeTopic 删除主题的一个回调，最后一个回调删除后取消订阅
+func (n *Node) unsubscribeTopic(topic string, id uint64) {
+	n.topicMtx.Lock()
+	defer n.topicMtx.Unlock()
+
+	handlers := n.topics[topic]
+	delete(handlers, id)
+	if len(handlers) > 0 {
+		return
+	}
+
+	delete(n.topics, topic)
+	if err := n.routeNode.UnsubscribeTopic(topic); err != nil {
+		log.Printf("Failed to unsubscribe topic %s: %v", topic, err)
+	}
+}
+
+// Topics 返回本节点订阅的主题
+func (n *Node) Topics() []string {
+	return n.routeNode.Topics()
+}
+
+// TopicSubscribers 返回订阅指定主题且当前可达的节点（按节点 ID 排序，本节点订阅时也包含在内）
+func (n *Node) TopicSubscribers(topic string) []string {
+	return n.routeNode.TopicSubscribers(topic)
+}
+
+// TopicStats 返回本节点发布的消息按订阅者细分的投递统计（topic 为空表示全部主题）
+func (n *Node) TopicStats(topic string) []SubscriberStats {
+	return n.routeNode.SubscriberStats(topic)
+}
+
+// dispatchTopic 将主题消息交给该主题的所有回调
+func (n *Node) dispatchTopic(msg *Message) {
+	n.topicMtx.RLock()
+	handlers := make([]func(*Message), 0, len(n.topics[msg.Topic]))
+	for _, handler := range n.topics[msg.Topic] {
+		handlers = append(handlers, handler)
+	}
+	n.topicMtx.RUnlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
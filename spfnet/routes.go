@@ -0,0 +1,113 @@
+package spfnet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Route 到某个目标节点的路由（快照，修改不会影响节点）
+type Route struct {
+	Destination string   // 目标节点 ID
+	NextHop     string   // 下一跳节点 ID
+	Cost        float64  // 到目标的总成本
+	Path        []string // 完整路径（从本节点到目标）
+}
+
+// Neighbor 与本节点直连的邻居
+type Neighbor struct {
+	ID     string  // 邻居节点 ID
+	Cost   float64 // 链路成本
+	Pinned bool    // 链路成本是否为手动固定（否则为自动探测）
+}
+
+// Member 集群成员
+type Member struct {
+	ID      string // 节点 ID
+	Address string // gRPC 地址
+	Status  string // 节点状态（alive / suspect / failed / left / unknown）
+}
+
+// Routes 返回当前路由表的快照（按目标节点 ID 排序）
+func (n *Node) Routes() []Route {
+	table := n.routeNode.GetRouteTable().GetAllRoutes()
+
+	routes := make([]Route, 0, len(table))
+	for _, r := range table {
+		routes = append(routes, Route{
+			Destination: r.Destination,
+			NextHop:     r.NextHop,
+			Cost:        r.Cost,
+			Path:        append([]string(nil), r.Path...),
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Destination < routes[j].Destination })
+	return routes
+}
+
+// PathTo 返回到目标节点的完整路径（从本节点开始）
+// 没有路由时返回的错误满足 errors.Is(err, ErrDestinationUnreachable)
+func (n *Node) PathTo(destination string) ([]string, error) {
+	if destination == n.routeNode.NodeID() {
+		return []string{destination}, nil
+	}
+
+	r, err := n.routeNode.GetRouteTable().GetRoute(destination)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no route to %s", ErrDestinationUnreachable, destination)
+	}
+	return append([]string(nil), r.Path...), nil
+}
+
+// CostTo 返回到目标节点的总成本
+// 没有路由时返回的错误满足 errors.Is(err, ErrDestinationUnreachable)
+func (n *Node) CostTo(destination string) (float64, error) {
+	if destination == n.routeNode.NodeID() {
+		return 0, nil
+	}
+
+	r, err := n.routeNode.GetRouteTable().GetRoute(destination)
+	if err != nil {
+		return 0, fmt.Errorf("%w: no route to %s", ErrDestinationUnreachable, destination)
+	}
+	return r.Cost, nil
+}
+
+// Reachable 检查目标节点当前是否可达
+func (n *Node) Reachable(destination string) bool {
+	if destination == n.routeNode.NodeID() {
+		return true
+	}
+
+	_, err := n.routeNode.GetRouteTable().GetRoute(destination)
+	return err == nil
+}
+
+// Neighbors 返回与本节点直连的邻居（按节点 ID 排序）
+func (n *Node) Neighbors() []Neighbor {
+	self := n.routeNode.NodeID()
+	topology := n.routeNode.GetTopology()
+
+	var neighbors []Neighbor
+	for id, cost := range topology.GetNeighbors(self) {
+		meta, _ := topology.GetLinkMeta(self, id)
+		neighbors = append(neighbors, Neighbor{ID: id, Cost: cost, Pinned: meta.Pinned})
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].ID < neighbors[j].ID })
+	return neighbors
+}
+
+// Members 返回本节点已知的集群成员（按节点 ID 排序）
+func (n *Node) Members() []Member {
+	nodes := n.routeNode.GetTopology().GetAllNodes()
+
+	members := make([]Member, 0, len(nodes))
+	for _, info := range nodes {
+		members = append(members, Member{
+			ID:      info.ID,
+			Address: info.GRPCAddress(),
+			Status:  info.Status.String(),
+		})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return members
+}
@@ -0,0 +1,67 @@
+package spfnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"spfnet/internal/route"
+)
+
+// Addr 覆盖网络上的字节流地址（节点 ID + 端口），Network() 返回 "spfnet"
+type Addr = route.StreamAddr
+
+// Listen 在覆盖网络的指定端口上监听字节流连接，返回标准的 net.Listener
+// 连接可靠、有序并带流量控制，可直接用于 http.Serve、grpc.Server.Serve 等
+//
+// 示例：
+//
+//	ln, err := node.Listen(80)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Serve(ln, handler)
+func (n *Node) Listen(port int) (net.Listener, error) {
+	if err := validPort(port); err != nil {
+		return nil, err
+	}
+	return n.routeNode.Listen(uint32(port))
+}
+
+// Dial 与目的节点指定端口建立字节流连接，默认 10 秒内未完成握手返回超时
+// 目的端口没有监听者时返回的错误满足 errors.Is(err, ErrConnectionRefused)
+//
+// 示例：
+//
+//	conn, err := node.Dial("nodeC", 80)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer conn.Close()
+func (n *Node) Dial(destination string, port int) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return n.DialContext(ctx, destination, port)
+}
+
+// DialContext 使用自定义 context 建立字节流连接（context 只约束握手过程）
+func (n *Node) DialContext(ctx context.Context, destination string, port int) (net.Conn, error) {
+	if err := validPort(port); err != nil {
+		return nil, err
+	}
+	conn, err := n.routeNode.Dial(ctx, destination, uint32(port))
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// validPort 检查端口范围
+func validPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package spfnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Domain 覆盖网络主机名后缀，"nodeC.spfnet" 表示节点 nodeC
+const Domain = "spfnet"
+
+// OverlayNode 从主机名中解析覆盖网络节点 ID（不区分大小写，允许末尾的 "."）
+// 不是覆盖网络主机名时返回 false
+func OverlayNode(host string) (string, bool) {
+	host = strings.TrimSuffix(host, ".")
+	suffix := "." + Domain
+	if len(host) <= len(suffix) || !strings.EqualFold(host[len(host)-len(suffix):], suffix) {
+		return "", false
+	}
+	return host[:len(host)-len(suffix)], true
+}
+
+// DialAddr 按 "主机:端口" 地址建立连接，签名与 net.Dialer.DialContext 相同
+// 主机名为 "<节点ID>.spfnet" 时通过覆盖网络建立字节流，其他地址使用普通 TCP 连接
+func (n *Node) DialAddr(ctx context.Context, network, address string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeID, ok := OverlayNode(host)
+	if !ok {
+		var d net.Dialer
+		return d.DialContext(ctx, network, address)
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("network %q not supported over the overlay", network)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+	return n.DialContext(ctx, nodeID, port)
+}
+
+// GRPCDialer 返回可用于 grpc.WithContextDialer 的拨号函数
+// 目标需使用 passthrough 解析器，避免 gRPC 先对覆盖网络主机名做 DNS 解析
+//
+// 示例：
+//
+//	conn, err := grpc.NewClient("passthrough:///nodeC.spfnet:50051",
+//	    grpc.WithContextDialer(node.GRPCDialer()),
+//	    grpc.WithTransportCredentials(insecure.NewCredentials()))
+func (n *Node) GRPCDialer() func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		return n.DialAddr(ctx, "tcp", address)
+	}
+}
+
+// RoundTripper 返回经覆盖网络访问 "<节点ID>.spfnet" 主机的 http.RoundTripper
+// 其他主机照常直连（遵循环境变量中的代理设置），覆盖网络主机不经过代理
+//
+// 示例：
+//
+//	client := &http.Client{Transport: node.RoundTripper()}
+//	resp, err := client.Get("http://nodeC.spfnet:8080/health")
+func (n *Node) RoundTripper() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = n.DialAddr
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if _, ok := OverlayNode(req.URL.Hostname()); ok {
+			return nil, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+	return transport
+}